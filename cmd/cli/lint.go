@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/spf13/cobra"
+)
+
+func lintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the manifests of a discovery model",
+		Long:  "Check the manifests used by a discovery model without a server, fails when errors are found.",
+		RunE:  lint,
+	}
+	cmd.Flags().StringP("filename", "f", "", "Discovery filename")
+	cmd.Flags().Bool("json", false, "Output the issues found as JSON")
+	return cmd
+}
+
+// lint checks the manifests of the discovery model and prints the issues found
+func lint(cmd *cobra.Command, _ []string) error {
+	filenameFlag, err := cmd.Flags().GetString("filename")
+	if err != nil || filenameFlag == "" {
+		return fmt.Errorf("you need to provide a discovery filename")
+	}
+	jsonFlag, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+
+	discoveryJSON, err := ioutil.ReadFile(filenameFlag)
+	if err != nil {
+		return err
+	}
+	disco, err := discovery.UnmarshalDiscoveryJSON(string(discoveryJSON))
+	if err != nil {
+		return err
+	}
+
+	issues := manifest.LintDiscovery(*disco)
+	if jsonFlag {
+		output, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+	}
+
+	if errs := issues.Errors(); errs > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d errors and %d warnings found", errs, len(issues)-errs)
+	}
+	return nil
+}
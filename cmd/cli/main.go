@@ -44,6 +44,8 @@ func newRootCommand(service client.Service) *cobra.Command {
 		Long:  `To use with pipelines and reproducible test runs`,
 	}
 	rootCmd.AddCommand(runCmd(service))
+	rootCmd.AddCommand(planCmd(service))
 	rootCmd.AddCommand(versionCmd(service))
+	rootCmd.AddCommand(lintCmd())
 	return rootCmd
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+func planCmd(service client.Service) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the execution plan of the test cases of a discovery model",
+		Long:  "Output how the test cases of a discovery model depend on each other, as JSON or Graphviz DOT. No consent is acquired.",
+		RunE:  plan(service),
+	}
+	cmd.Flags().StringP("filename", "f", "", "Discovery filename")
+	cmd.Flags().StringP("config", "c", "", "Config filename")
+	cmd.Flags().String("format", "json", "Output format: json or dot")
+	cmd.Flags().String("failed", "", "Mark the tests skipped when the test with this id fails")
+	cmd.Flags().StringP("output", "o", "", "Write the plan to this file instead of standard output")
+	addSelectionFlags(cmd, "", "Only plan tests")
+	addSelectionFlags(cmd, "exclude-", "Do not plan tests")
+	return cmd
+}
+
+// plan prints the execution plan of the test cases of the discovery model, without acquiring consents
+func plan(service client.Service) func(cmd *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		filenameFlag, err := cmd.Flags().GetString("filename")
+		if err != nil || filenameFlag == "" {
+			return fmt.Errorf("you need to provide a discovery filename")
+		}
+		configFlag, err := cmd.Flags().GetString("config")
+		if err != nil || configFlag == "" {
+			return fmt.Errorf("you need to provide a config filename")
+		}
+		formatFlag, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		failedFlag, err := cmd.Flags().GetString("failed")
+		if err != nil {
+			return err
+		}
+		outputFlag, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		selection := client.Selection{}
+		if selection.Include, err = selectionRules(cmd, ""); err != nil {
+			return fmt.Errorf("invalid test selection: %s", err.Error())
+		}
+		if selection.Exclude, err = selectionRules(cmd, "exclude-"); err != nil {
+			return fmt.Errorf("invalid test selection: %s", err.Error())
+		}
+
+		cmd.SilenceUsage = true
+		executionPlan, err := service.Plan(filenameFlag, configFlag, selection, formatFlag, failedFlag)
+		if err != nil {
+			return err
+		}
+		if outputFlag != "" {
+			return ioutil.WriteFile(outputFlag, executionPlan, 0644)
+		}
+		fmt.Println(string(executionPlan))
+		return nil
+	}
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
-	"github.com/OpenBankingUK/conformance-suite/pkg/client"
 	"fmt"
-	"github.com/spf13/cobra"
 	"os"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/client"
+	"github.com/spf13/cobra"
 )
 
 func runCmd(service client.Service) *cobra.Command {
@@ -17,9 +18,41 @@ func runCmd(service client.Service) *cobra.Command {
 	generatorCmd.Flags().StringP("filename", "f", "", "Discovery filename")
 	generatorCmd.Flags().StringP("config", "c", "", "Config filename")
 	generatorCmd.Flags().StringP("export", "e", "", "Export config filename")
+	addSelectionFlags(generatorCmd, "", "Only run tests")
+	addSelectionFlags(generatorCmd, "exclude-", "Do not run tests")
 	return generatorCmd
 }
 
+// addSelectionFlags adds the flags narrowing the tests run, prefixed with prefix
+func addSelectionFlags(cmd *cobra.Command, prefix, usage string) {
+	cmd.Flags().StringSlice(prefix+"id", nil, usage+" with ids matching these globs, e.g. OB-301-ACC-*")
+	cmd.Flags().StringSlice(prefix+"regex", nil, usage+" with ids or descriptions matching these regular expressions")
+	cmd.Flags().StringSlice(prefix+"resource", nil, usage+" for these resources, e.g. Account")
+	cmd.Flags().StringSlice(prefix+"api", nil, usage+" for these API specification names")
+	cmd.Flags().StringSlice(prefix+"uri-implementation", nil, usage+" with these uri implementations: mandatory, optional or conditional")
+	cmd.Flags().StringSlice(prefix+"tag", nil, usage+" with these tags")
+}
+
+// selectionRules reads the selection flags prefixed with prefix
+func selectionRules(cmd *cobra.Command, prefix string) (client.SelectionRules, error) {
+	rules := client.SelectionRules{}
+	for name, values := range map[string]*[]string{
+		"id":                 &rules.IDs,
+		"regex":              &rules.Regexes,
+		"resource":           &rules.Resources,
+		"api":                &rules.APINames,
+		"uri-implementation": &rules.URIImplementations,
+		"tag":                &rules.Tags,
+	} {
+		flag, err := cmd.Flags().GetStringSlice(prefix + name)
+		if err != nil {
+			return rules, err
+		}
+		*values = flag
+	}
+	return rules, nil
+}
+
 // run runs the functional conformance workflow to generate test case run report
 func run(service client.Service) func(cmd *cobra.Command, _ []string) {
 	return func(cmd *cobra.Command, _ []string) {
@@ -41,7 +74,17 @@ func run(service client.Service) func(cmd *cobra.Command, _ []string) {
 			return
 		}
 
-		results, err := service.Run(filenameFlag, configFlag, exportFlag)
+		selection := client.Selection{}
+		if selection.Include, err = selectionRules(cmd, ""); err != nil {
+			fmt.Printf("Invalid test selection: %s\n", err.Error())
+			return
+		}
+		if selection.Exclude, err = selectionRules(cmd, "exclude-"); err != nil {
+			fmt.Printf("Invalid test selection: %s\n", err.Error())
+			return
+		}
+
+		results, err := service.Run(filenameFlag, configFlag, exportFlag, selection)
 		if err != nil {
 			fmt.Printf("Error running tests: %s\n", err.Error())
 			return
@@ -8,10 +8,12 @@ import (
 	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server"
 	"github.com/OpenBankingUK/conformance-suite/pkg/tracer"
 	"github.com/OpenBankingUK/conformance-suite/pkg/version"
@@ -46,7 +48,11 @@ Complete documentation is available at https://github.com/OpenBankingUK/conforma
 
 			validatorEngine := discovery.NewFuncValidator(model.NewConditionalityChecker())
 			testGenerator := generation.NewGenerator()
-			tlsValidator := discovery.NewStdTLSValidator(tls.VersionTLS11)
+			roots, err := executors.RootCAs("")
+			if err != nil {
+				return errors.Wrap(err, "tls validator root CAs")
+			}
+			tlsValidator := discovery.NewStdTLSValidator(tls.VersionTLS11).WithRootCAs(roots)
 			journey := server.NewJourney(logger, testGenerator, validatorEngine, tlsValidator, viper.GetBool("dynres"))
 
 			echoServer := server.NewServer(journey, logger, ver)
@@ -90,6 +96,8 @@ func init() {
 	rootCmd.PersistentFlags().Bool("tlscheck", true, "enable tls version checking - default enabled")
 	rootCmd.PersistentFlags().Bool("export_testcases", false, "Dump all testcases to console in CSV format")
 	rootCmd.PersistentFlags().Bool("proxy_version_check", true, "Use proxy for version checks")
+	rootCmd.PersistentFlags().Bool("unsafe_debug", false, "Do not redact tokens and credentials in logs and traces - unsafe, for debugging only")
+	rootCmd.PersistentFlags().Bool("redact_jws_signature", false, "Redact x-jws-signature headers and detached JWS")
 
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
 		fmt.Fprint(os.Stderr, err)
@@ -106,14 +114,14 @@ func init() {
 
 func initConfig() {
 	logger.SetNoLock()
-	logger.SetFormatter(&prefixed.TextFormatter{
+	logger.SetFormatter(redact.NewFormatter(&prefixed.TextFormatter{
 		DisableColors:    false,
 		ForceColors:      true,
 		TimestampFormat:  time.RFC3339,
 		FullTimestamp:    true,
 		DisableTimestamp: false,
 		ForceFormatting:  true,
-	})
+	}))
 	level, err := logrus.ParseLevel(viper.GetString("log_level"))
 	if err != nil {
 		printConfigurationFlags()
@@ -128,6 +136,12 @@ func initConfig() {
 		os.Exit(0)
 	}
 
+	redact.EnableUnsafeDebug(viper.GetBool("unsafe_debug"))
+	redact.RedactSignatures(viper.GetBool("redact_jws_signature"))
+	if redact.UnsafeDebug() {
+		logger.Warn("unsafe_debug enabled - tokens and credentials are written in clear to logs and traces")
+	}
+
 	tracer.Silent = !viper.GetBool("log_tracer")
 	if viper.GetBool("log_to_file") {
 		f, err := os.OpenFile("suite.log", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
@@ -136,23 +150,24 @@ func initConfig() {
 		} else {
 			mw := f // io.MultiWriter(os.Stdout, f)
 			logrus.SetOutput(mw)
-			logger.SetFormatter(&prefixed.TextFormatter{
+			logger.SetFormatter(redact.NewFormatter(&prefixed.TextFormatter{
 				DisableColors:    true,
 				ForceColors:      false,
 				TimestampFormat:  time.RFC3339,
 				FullTimestamp:    true,
 				DisableTimestamp: false,
 				ForceFormatting:  true,
-			})
+			}))
 			os.Remove("http-trace.log")
 		}
 	}
+	resty.SetLogger(redact.NewWriter(os.Stderr))
 	if viper.GetBool("log_http_file") {
 		httpLogFile, err := os.OpenFile("http-trace.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
 			logrus.Warn("cannot set http trace file")
 		} else {
-			resty.SetLogger(httpLogFile)
+			resty.SetLogger(redact.NewWriter(httpLogFile))
 		}
 	}
 
@@ -168,24 +183,25 @@ func initConfig() {
 		server.EnableTLSCheck(false)
 	}
 
-	resty.SetDebug(viper.GetBool("log_http_trace"))
-	resty.SetRedirectPolicy(resty.FlexibleRedirectPolicy(15))
+	resty.SetDebug(viper.GetBool("log_http_trace")) // the HTTP clients of the runs log as the default client
 	printConfigurationFlags()
 }
 
 func printConfigurationFlags() {
 	logger.WithFields(logrus.Fields{
-		"log_level":        viper.GetString("log_level"),
-		"log_tracer":       viper.GetBool("log_tracer"),
-		"log_http_trace":   viper.GetBool("log_http_trace"),
-		"log_http_file":    viper.GetBool("log_http_file"),
-		"log_to_file":      viper.GetBool("log_to_file"),
-		"port":             viper.GetInt("port"),
-		"tracer.Silent":    tracer.Silent,
-		"disable_jws":      viper.GetBool("disable_jws"),
-		"dynres":           viper.GetBool("dynres"),
-		"dumpcontexts":     viper.GetBool("dumpcontexts"),
-		"tlscheck":         viper.GetBool("tlscheck"),
-		"export_testcases": viper.GetString("export_testcases"),
+		"log_level":            viper.GetString("log_level"),
+		"log_tracer":           viper.GetBool("log_tracer"),
+		"log_http_trace":       viper.GetBool("log_http_trace"),
+		"log_http_file":        viper.GetBool("log_http_file"),
+		"log_to_file":          viper.GetBool("log_to_file"),
+		"port":                 viper.GetInt("port"),
+		"tracer.Silent":        tracer.Silent,
+		"disable_jws":          viper.GetBool("disable_jws"),
+		"dynres":               viper.GetBool("dynres"),
+		"dumpcontexts":         viper.GetBool("dumpcontexts"),
+		"tlscheck":             viper.GetBool("tlscheck"),
+		"export_testcases":     viper.GetString("export_testcases"),
+		"unsafe_debug":         viper.GetBool("unsafe_debug"),
+		"redact_jws_signature": viper.GetBool("redact_jws_signature"),
 	}).Info("configuration flags")
 }
@@ -0,0 +1,30 @@
+// Command jsonschema writes the JSON Schemas of manifests, assertions and discovery models, see pkg/jsonschema
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema/generator"
+)
+
+func main() {
+	root := flag.String("root", ".", "Repository root")
+	out := flag.String("out", "pkg/jsonschema/v1", "Directory the schemas are written to")
+	flag.Parse()
+
+	schemas, err := generator.Schemas(*root)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for name, schema := range schemas {
+		if err := ioutil.WriteFile(filepath.Join(*out, name+".json"), schema, 0644); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+}
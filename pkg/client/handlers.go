@@ -59,9 +59,12 @@ type TestCaseResult struct {
 
 // TestCase result for a run
 type TestCase struct {
-	Id   string `json:"id"`
-	Pass bool   `json:"pass"`
-	Fail string `json:"fail,omitempty"`
+	Id        string   `json:"id"`
+	Pass      bool     `json:"pass"`
+	Fail      string   `json:"fail,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Status    string   `json:"status"` // pass, fail, skipped, error, warning or not-applicable
+	BlockedBy string   `json:"blockedBy,omitempty"`
 }
 
 type event struct {
@@ -5,13 +5,35 @@ import (
 	"io"
 )
 
+// statusMsg is the label printed for each result status
+var statusMsg = map[string]string{
+	"pass":           "PASS",
+	"fail":           "FAIL",
+	"skipped":        "SKIP",
+	"error":          "ERROR",
+	"warning":        "WARN",
+	"not-applicable": "N/A",
+}
+
 // ResultWriter writes testcase results to a writer
 func ResultWriter(w io.Writer, results []TestCase) {
 	var passMsg = map[bool]string{true: "PASS", false: "FAIL"}
 	for _, result := range results {
-		fmt.Fprintf(w, "=== %s: %s\n", passMsg[result.Pass], result.Id)
+		msg, ok := statusMsg[result.Status]
+		if !ok {
+			// results from servers without statuses
+			msg = passMsg[result.Pass]
+		}
+		if result.Status == "skipped" {
+			fmt.Fprintf(w, "=== %s: %s (blocked by %s)\n", msg, result.Id, result.BlockedBy)
+			continue
+		}
+		fmt.Fprintf(w, "=== %s: %s\n", msg, result.Id)
 		if !result.Pass {
 			fmt.Fprintf(w, "\t %s\n", result.Fail)
 		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(w, "\t %s\n", warning)
+		}
 	}
 }
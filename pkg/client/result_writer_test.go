@@ -0,0 +1,28 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultWriter(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	ResultWriter(output, []TestCase{
+		{Id: "OB-301-DOP-100100", Pass: false, Status: "fail", Fail: "HTTP Status code does not match"},
+		{Id: "OB-301-DOP-100600", Status: "skipped", BlockedBy: "OB-301-DOP-100100"},
+		{Id: "OB-301-ACC-100000", Pass: true, Status: "pass"},
+		{Id: "OB-301-ACC-100100", Pass: false, Status: "error", Fail: "missing token"},
+		{Id: "OB-301-ACC-100200", Pass: true},
+		{Id: "OB-301-ACC-100300", Pass: true, Status: "warning", Warnings: []string{"x-fapi-interaction-id header not present"}},
+	})
+
+	assert.Equal(t, "=== FAIL: OB-301-DOP-100100\n\t HTTP Status code does not match\n"+
+		"=== SKIP: OB-301-DOP-100600 (blocked by OB-301-DOP-100100)\n"+
+		"=== PASS: OB-301-ACC-100000\n"+
+		"=== ERROR: OB-301-ACC-100100\n\t missing token\n"+
+		"=== PASS: OB-301-ACC-100200\n"+
+		"=== WARN: OB-301-ACC-100300\n\t x-fapi-interaction-id header not present\n", output.String())
+}
@@ -1,11 +1,13 @@
 package client
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 
@@ -16,7 +18,8 @@ import (
 // Service is a gateway to backend services provided by FCS
 type Service interface {
 	Version() (VersionResponse, error)
-	Run(discoveryFile, configFile, exportConfig string) ([]TestCase, error)
+	Run(discoveryFile, configFile, exportConfig string, selection Selection) ([]TestCase, error)
+	Plan(discoveryFile, configFile string, selection Selection, format, failed string) ([]byte, error)
 }
 
 const (
@@ -24,6 +27,7 @@ const (
 	setConfigPath         = "/api/config/global"
 	exportReport          = "/api/export"
 	generateTestCases     = "/api/test-cases"
+	executionPlan         = "/api/test-cases/plan"
 	runTestCases          = "/api/run"
 	runTestCasesResultsWS = "/api/run/ws"
 	versionPath           = "/api/version"
@@ -44,13 +48,29 @@ func NewService(host, wsHost string, conn *Connection) service {
 	}
 }
 
+// Selection narrows the tests generated, it is validated by the server, see manifest.Selection
+type Selection struct {
+	Include SelectionRules `json:"include,omitempty"`
+	Exclude SelectionRules `json:"exclude,omitempty"`
+}
+
+// SelectionRules match a test when any of their values matches it, see manifest.SelectionRules
+type SelectionRules struct {
+	IDs                []string `json:"ids,omitempty"`
+	Regexes            []string `json:"regexes,omitempty"`
+	Resources          []string `json:"resources,omitempty"`
+	APINames           []string `json:"apiNames,omitempty"`
+	URIImplementations []string `json:"uriImplementations,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+}
+
 type VersionResponse struct {
 	Version string `json:"version"`
 	Message string `json:"message"`
 	Update  bool   `json:"update"`
 }
 
-func (s service) Run(discovery, config, report string) ([]TestCase, error) {
+func (s service) Run(discovery, config, report string, selection Selection) ([]TestCase, error) {
 	err := s.setDiscoveryModel(discovery)
 	if err != nil {
 		return nil, err
@@ -61,7 +81,7 @@ func (s service) Run(discovery, config, report string) ([]TestCase, error) {
 		return nil, err
 	}
 
-	err = s.TestCases()
+	err = s.TestCases(selection)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +107,47 @@ func (s service) Run(discovery, config, report string) ([]TestCase, error) {
 	return results, nil
 }
 
+// Plan returns the execution plan of the test cases of a discovery model in format, json or dot, marking the test
+// cases skipped when the test case failed fails, if any. The test cases are not generated for a run, no consent
+// is acquired.
+func (s service) Plan(discovery, config string, selection Selection, format, failed string) ([]byte, error) {
+	if err := s.setDiscoveryModel(discovery); err != nil {
+		return nil, err
+	}
+	if err := s.setConfig(config); err != nil {
+		return nil, err
+	}
+	return s.executionPlan(selection, format, failed)
+}
+
+func (s service) executionPlan(selection Selection, format, failed string) ([]byte, error) {
+	selectionBody, err := json.Marshal(selection)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting execution plan")
+	}
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	if failed != "" {
+		query.Set("failed", failed)
+	}
+	response, err := s.conn.Post(s.host+executionPlan+"?"+query.Encode(), "application/json", bytes.NewReader(selectionBody))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting execution plan")
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading execution plan")
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code getting execution plan: %d, %s", response.StatusCode, string(body))
+	}
+	return body, nil
+}
+
 func aggregateResults(resultChan chan TestCase, endedChan chan struct{}) ([]TestCase, error) {
 	var results []TestCase
 	const timeoutRunningTests = 5 * time.Minute
@@ -168,8 +229,13 @@ func (s service) setConfig(filename string) error {
 	return nil
 }
 
-func (s service) TestCases() error {
-	response, err := s.conn.Get(s.host + generateTestCases)
+func (s service) TestCases(selection Selection) error {
+	body, err := json.Marshal(selection)
+	if err != nil {
+		return errors.Wrap(err, "generating test cases")
+	}
+
+	response, err := s.conn.Post(s.host+generateTestCases, "application/json", bytes.NewReader(body))
 	if err != nil {
 		return errors.Wrap(err, "generating test cases")
 	}
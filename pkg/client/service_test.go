@@ -1,9 +1,12 @@
 package client
 
 import (
+	"encoding/json"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -48,7 +51,69 @@ func TestTestCases(t *testing.T) {
 	conn := &Connection{Client: &http.Client{}}
 	service := NewService(url, url, conn)
 
-	err := service.TestCases()
+	err := service.TestCases(Selection{})
 
 	assert.NoError(t, err)
 }
+
+func TestTestCasesPostsSelection(t *testing.T) {
+	selection := Selection{Include: SelectionRules{IDs: []string{"OB-301-ACC-*"}}}
+	var received Selection
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, generateTestCases, r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	conn := &Connection{Client: &http.Client{}}
+	service := NewService(server.URL, server.URL, conn)
+
+	err := service.TestCases(selection)
+
+	assert.NoError(t, err)
+	assert.Equal(t, selection, received)
+}
+
+func TestTestCasesReportsRejectedSelection(t *testing.T) {
+	server, url := test.HTTPServer(http.StatusBadRequest, `{"error":"selection include: invalid regex"}`, nil)
+	defer server.Close()
+	conn := &Connection{Client: &http.Client{}}
+	service := NewService(url, url, conn)
+
+	err := service.TestCases(Selection{Include: SelectionRules{Regexes: []string{"("}}})
+
+	assert.EqualError(t, err, `unexpected status code generating test cases: 400, {"error":"selection include: invalid regex"}`)
+}
+
+func TestExecutionPlan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, executionPlan, r.URL.Path)
+		assert.Equal(t, "dot", r.URL.Query().Get("format"))
+		assert.Equal(t, "OB-301-DOP-100100", r.URL.Query().Get("failed"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"include":{"apiNames":["Payment Initiation"]},"exclude":{}}`, string(body))
+		_, _ = w.Write([]byte(`digraph "execution plan" {}`))
+	}))
+	defer server.Close()
+	conn := &Connection{Client: &http.Client{}}
+	service := NewService(server.URL, server.URL, conn)
+
+	plan, err := service.executionPlan(Selection{Include: SelectionRules{APINames: []string{"Payment Initiation"}}}, "dot", "OB-301-DOP-100100")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `digraph "execution plan" {}`, string(plan))
+}
+
+func TestExecutionPlanReportsErrors(t *testing.T) {
+	server, url := test.HTTPServer(http.StatusBadRequest, `{"error":"test case OB-301-XXX-01 is not in the execution plan"}`, nil)
+	defer server.Close()
+	conn := &Connection{Client: &http.Client{}}
+	service := NewService(url, url, conn)
+
+	_, err := service.executionPlan(Selection{}, "", "OB-301-XXX-01")
+
+	assert.EqualError(t, err, `unexpected status code getting execution plan: 400, {"error":"test case OB-301-XXX-01 is not in the execution plan"}`)
+}
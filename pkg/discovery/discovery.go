@@ -3,6 +3,7 @@ package discovery
 import (
 	"encoding/json"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/sirupsen/logrus"
@@ -87,9 +88,12 @@ func (c CBPIIDebtorAccount) Validate() error {
 // UnmarshalDiscoveryJSON - Used for testing in multiple packages to get discovery
 // model from JSON. We tried moving this function to a _test file, but we get
 // `go vet` error as it is used from multiple packages.
-// In production, we use echo.Context Bind to load configuration from JSON in HTTP POST.
+// The JSON is validated against the discovery JSON Schema first, see jsonschema.Validate.
 func UnmarshalDiscoveryJSON(discoveryJSON string) (*Model, error) {
 	discovery := &Model{}
+	if err := jsonschema.Validate(jsonschema.Discovery, []byte(discoveryJSON)); err != nil {
+		return discovery, err
+	}
 	err := json.Unmarshal([]byte(discoveryJSON), &discovery)
 	return discovery, err
 }
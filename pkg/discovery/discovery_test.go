@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"fmt"
 	"log"
 	"testing"
 
@@ -323,3 +324,22 @@ var testDiscoveryPayloadOAS3 = []byte(`
   }
   
 `)
+
+func TestUnmarshalDiscoveryJSONValidatesSchema(t *testing.T) {
+	_, err := UnmarshalDiscoveryJSON(`{
+  "discoveryModel": {
+    "name": "ob-v3.1-ozone",
+    "tokenAcquisition": ["psu"]
+  }
+}`)
+
+	assert.EqualError(t, err, `json schema validation failed: line 4: discoveryModel.tokenAcquisition: Field must be set to string or not be present`)
+
+	_, err = UnmarshalDiscoveryJSON(`{
+  "discoveryModel": {
+    "name": "ob-v3.1-ozone",
+    "consentCallbackUrl": "https://fcs-callback-proxy.openbanking.rocks"
+  }
+}`)
+	assert.NotContains(t, fmt.Sprint(err), "json schema validation failed", "keys of earlier versions are ignored")
+}
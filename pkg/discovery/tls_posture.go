@@ -0,0 +1,445 @@
+package discovery
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/magisterquis/connectproxy"
+	"github.com/pkg/errors"
+)
+
+// Kinds of endpoint checked by ValidateTLSPosture
+const (
+	TLSEndpointResource      = "resource"
+	TLSEndpointToken         = "token"
+	TLSEndpointAuthorization = "authorization"
+)
+
+const (
+	tlsDialTimeout      = 10 * time.Second // Time a connection and its handshake may take
+	tlsPostureTimeout   = 30 * time.Second // Time all the probes of a host may take
+	tlsPostureCacheTTL  = 5 * time.Minute  // Time the probes of a host are reused for
+	tlsProbeConcurrency = 8                // Handshakes in progress at a time with a host
+)
+
+// probedTLSVersions are the protocol versions offered one at a time to find those accepted by an endpoint
+var probedTLSVersions = []uint16{
+	tls.VersionTLS10,
+	tls.VersionTLS11,
+	tls.VersionTLS12,
+	tls.VersionTLS13,
+}
+
+// fapiCipherSuites are the TLS 1.2 cipher suites permitted by FAPI, see
+// https://openid.net/specs/openid-financial-api-part-2-1_0.html#tls-considerations
+// The permitted DHE suites are not implemented by crypto/tls so cannot be probed.
+// TLS 1.3 cipher suites are all permitted.
+var fapiCipherSuites = map[uint16]bool{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256: true,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384: true,
+}
+
+// TLSPostureResult reports the TLS posture of an endpoint. Valid is only set when the endpoint accepts no protocol
+// version below the minimum supported, offers FAPI permitted cipher suites only, presents a valid and unexpired
+// certificate chain matching its hostname and, when mtlsRequired, rejects connections without a client certificate.
+type TLSPostureResult struct {
+	Endpoint         string   `json:"endpoint"`           // Kind of endpoint: resource, token or authorization
+	URI              string   `json:"uri"`                // URI of the endpoint
+	Valid            bool     `json:"valid"`              // All the checks below passed
+	Versions         []string `json:"versions"`           // Protocol versions accepted
+	CipherSuites     []string `json:"cipher_suites"`      // Cipher suites accepted
+	FAPICipherSuites bool     `json:"fapi_cipher_suites"` // Only FAPI permitted cipher suites are accepted
+	ChainValid       bool     `json:"chain_valid"`        // Certificate chain verifies against the trusted roots
+	NotAfter         string   `json:"not_after"`          // Expiry of the leaf certificate
+	Expired          bool     `json:"expired"`            // Leaf certificate has expired
+	HostnameMatch    bool     `json:"hostname_match"`     // Leaf certificate is valid for the hostname of the endpoint
+	OCSPStapled      bool     `json:"ocsp_stapled"`       // An OCSP response is stapled to the handshake
+	MTLSRequired     bool     `json:"mtls_required"`      // Endpoint must enforce mutual TLS
+	MTLSEnforced     bool     `json:"mtls_enforced"`      // Connections without a client certificate fail
+	Errors           []string `json:"errors,omitempty"`   // Reasons why the posture is not valid
+}
+
+// ValidateTLSPosture probes the TLS posture of the endpoint at uri, see TLSPostureResult. The probes of a host are
+// run concurrently, within tlsPostureTimeout, and reused by the endpoints on the same host for tlsPostureCacheTTL.
+// An error is only returned when no TLS connection at all can be established with the endpoint.
+func (v StdTLSValidator) ValidateTLSPosture(endpoint, uri string, mtlsRequired bool) (TLSPostureResult, error) {
+	result := TLSPostureResult{
+		Endpoint:     endpoint,
+		URI:          uri,
+		Versions:     []string{},
+		CipherSuites: []string{},
+		MTLSRequired: mtlsRequired,
+	}
+
+	addr, host, err := tlsAddress(uri)
+	if err != nil {
+		return result, err
+	}
+
+	probe := v.postureCache.probe(addr, host)
+	if probe.state == nil {
+		return result, fmt.Errorf("unable to establish a tls connection with %s", addr)
+	}
+	if probe.timedOut {
+		result.Errors = append(result.Errors, fmt.Sprintf("probes did not complete within %s, the versions and cipher suites accepted may be incomplete", tlsPostureTimeout))
+	}
+
+	for _, version := range probe.versions {
+		strVersion, _ := tlsVersionToString(version)
+		result.Versions = append(result.Versions, strVersion)
+		if version < v.minSupportedTLSVersion {
+			result.Errors = append(result.Errors, fmt.Sprintf("accepts %s which is below the minimum supported version", strVersion))
+		}
+	}
+
+	result.FAPICipherSuites = true
+	for _, suite := range probe.cipherSuites {
+		result.CipherSuites = append(result.CipherSuites, suite.Name)
+		if !fapiCipherSuites[suite.ID] {
+			result.FAPICipherSuites = false
+			result.Errors = append(result.Errors, fmt.Sprintf("accepts cipher suite %s which is not permitted by FAPI", suite.Name))
+		}
+	}
+	// TLS 1.3 cipher suites cannot be chosen with crypto/tls, only the one negotiated is reported
+	if probe.state.Version == tls.VersionTLS13 {
+		result.CipherSuites = append(result.CipherSuites, tls.CipherSuiteName(probe.state.CipherSuite))
+	}
+
+	result.checkCertificates(*probe.state, host, v.rootCAs, time.Now())
+	result.OCSPStapled = len(probe.state.OCSPResponse) > 0
+
+	result.MTLSEnforced = probe.mtlsEnforced
+	if mtlsRequired && probe.mtlsErr != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("unable to tell whether connections without a client certificate are rejected: %s", probe.mtlsErr.Error()))
+	} else if mtlsRequired && !result.MTLSEnforced {
+		result.Errors = append(result.Errors, "accepts connections without a client certificate")
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// hostProbe is what the handshakes with a host found, whatever the endpoint
+type hostProbe struct {
+	versions     []uint16             // Protocol versions accepted, in the order of probedTLSVersions
+	state        *tls.ConnectionState // State of the handshake with the highest version accepted, nil if none
+	cipherSuites []*tls.CipherSuite   // TLS 1.2 cipher suites accepted
+	mtlsEnforced bool                 // A client without certificate is rejected
+	mtlsErr      error                // Why mtlsEnforced could not be told, if so
+	timedOut     bool                 // Some probes did not complete within tlsPostureTimeout
+}
+
+// tlsPostureCache keeps the probes of each host, so the endpoints of a discovery on the same host are probed once
+type tlsPostureCache struct {
+	lock    sync.Mutex
+	entries map[string]*tlsPostureCacheEntry
+	now     func() time.Time
+}
+
+type tlsPostureCacheEntry struct {
+	once     sync.Once
+	probedAt time.Time
+	probe    hostProbe
+}
+
+func newTLSPostureCache() *tlsPostureCache {
+	return &tlsPostureCache{entries: map[string]*tlsPostureCacheEntry{}, now: time.Now}
+}
+
+// probe returns the probes of addr, probing it unless done less than tlsPostureCacheTTL ago. Concurrent calls for
+// the same addr wait for the same probes.
+func (c *tlsPostureCache) probe(addr, host string) hostProbe {
+	c.lock.Lock()
+	entry, ok := c.entries[addr]
+	if !ok || c.now().Sub(entry.probedAt) > tlsPostureCacheTTL {
+		entry = &tlsPostureCacheEntry{probedAt: c.now()}
+		c.entries[addr] = entry
+	}
+	c.lock.Unlock()
+
+	entry.once.Do(func() { entry.probe = probeHost(addr, host) })
+	return entry.probe
+}
+
+// probeHost offers each protocol version and each TLS 1.2 cipher suite in turn, tlsProbeConcurrency handshakes at a
+// time, then checks whether a client without certificate is rejected
+func probeHost(addr, host string) hostProbe {
+	ctx, cancel := context.WithTimeout(context.Background(), tlsPostureTimeout)
+	defer cancel()
+
+	suites := []*tls.CipherSuite{}
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		if supportsTLS12(suite) {
+			suites = append(suites, suite)
+		}
+	}
+
+	states := make([]*tls.ConnectionState, len(probedTLSVersions))
+	accepted := make([]bool, len(suites))
+	slots := make(chan struct{}, tlsProbeConcurrency)
+	wg := sync.WaitGroup{}
+	run := func(config *tls.Config, found func(tls.ConnectionState)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if s, err := handshake(ctx, addr, config); err == nil {
+				found(s)
+			}
+		}()
+	}
+	for i, version := range probedTLSVersions {
+		i := i
+		run(&tls.Config{InsecureSkipVerify: true, ServerName: host, MinVersion: version, MaxVersion: version},
+			func(s tls.ConnectionState) { states[i] = &s })
+	}
+	for i, suite := range suites {
+		i := i
+		run(&tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         host,
+			MinVersion:         tls.VersionTLS12,
+			MaxVersion:         tls.VersionTLS12,
+			CipherSuites:       []uint16{suite.ID},
+		}, func(tls.ConnectionState) { accepted[i] = true })
+	}
+	wg.Wait()
+
+	probe := hostProbe{}
+	for i, state := range states {
+		if state != nil {
+			probe.versions = append(probe.versions, probedTLSVersions[i])
+			probe.state = state
+		}
+	}
+	for i, suite := range suites {
+		if accepted[i] {
+			probe.cipherSuites = append(probe.cipherSuites, suite)
+		}
+	}
+	if probe.state != nil {
+		probe.mtlsEnforced, probe.mtlsErr = mtlsEnforced(ctx, addr, host)
+	}
+	probe.timedOut = ctx.Err() != nil
+	return probe
+}
+
+func (r *TLSPostureResult) checkCertificates(state tls.ConnectionState, host string, roots *x509.CertPool, now time.Time) {
+	if len(state.PeerCertificates) == 0 {
+		r.Errors = append(r.Errors, "no certificate presented")
+		return
+	}
+
+	leaf := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	r.NotAfter = leaf.NotAfter.Format(time.RFC3339)
+	r.Expired = now.After(leaf.NotAfter)
+	if r.Expired {
+		r.Errors = append(r.Errors, fmt.Sprintf("certificate expired on %s", r.NotAfter))
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now})
+	r.ChainValid = err == nil
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid certificate chain: %s", err.Error()))
+	}
+
+	err = leaf.VerifyHostname(host)
+	r.HostnameMatch = err == nil
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("certificate does not match hostname: %s", err.Error()))
+	}
+}
+
+// mtlsEnforced connects without a client certificate and tells whether the endpoint rejects it with an alert, during
+// the handshake or, with TLS 1.3, on the first read after it. Data received means the connection was accepted.
+// Failures other than a certificate alert tell nothing and are returned.
+func mtlsEnforced(ctx context.Context, addr, host string) (bool, error) {
+	conn, err := dialTLS(ctx, addr, &tls.Config{InsecureSkipVerify: true, ServerName: host})
+	if err != nil {
+		if remoteAlert(err) == handshakeFailureAlert {
+			return certificateExpected(ctx, addr, host, err)
+		}
+		return certificateRejected(err)
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "HEAD / HTTP/1.1\r\nHost: %s\r\nConnection: close\r\n\r\n", host); err != nil {
+		return certificateRejected(err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return certificateRejected(err)
+	}
+	return false, nil
+}
+
+// contextDialer is a proxy.Dialer connecting within the deadline of its dialer and until ctx is done
+type contextDialer struct {
+	ctx    context.Context
+	dialer *net.Dialer
+}
+
+// Dial connects to addr on the named network
+func (d contextDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.dialer.DialContext(d.ctx, network, addr)
+}
+
+// certificateAlerts are the alerts of a server rejecting a client without certificate
+var certificateAlerts = []string{"tls: bad certificate", "tls: certificate required"}
+
+// handshakeFailureAlert is the generic alert some servers, TLS 1.2 ones in particular, send for a missing client
+// certificate, as well as for unrelated reasons such as no cipher suite in common
+const handshakeFailureAlert = "tls: handshake failure"
+
+// certificateRejected tells whether err is an alert sent by the server rejecting the client certificate, and returns
+// err when it is not
+func certificateRejected(err error) (bool, error) {
+	alert := remoteAlert(err)
+	for _, certificateAlert := range certificateAlerts {
+		if alert == certificateAlert {
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+// certificateExpected retries a handshake which failed with handshakeErr, a generic alert, presenting a self-signed
+// client certificate. The failure was caused by the missing certificate when the outcome changes: the handshake
+// succeeds, or fails with an alert judging the certificate. handshakeErr is returned otherwise.
+func certificateExpected(ctx context.Context, addr, host string, handshakeErr error) (bool, error) {
+	cert, err := probeCertificate()
+	if err != nil {
+		return false, handshakeErr
+	}
+	conn, err := dialTLS(ctx, addr, &tls.Config{InsecureSkipVerify: true, ServerName: host, Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		if alert := remoteAlert(err); alert != "" && alert != handshakeFailureAlert {
+			return true, nil
+		}
+		return false, handshakeErr
+	}
+	conn.Close()
+	return true, nil
+}
+
+// remoteAlert returns the alert sent by the server that err is, empty when it is not one
+func remoteAlert(err error) string {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "remote error" {
+		return opErr.Err.Error()
+	}
+	return ""
+}
+
+// probeCertificate returns a short lived self-signed client certificate, trusted by no server
+func probeCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano()),
+		Subject:      pkix.Name{CommonName: "conformance-suite tls posture probe"},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func supportsTLS12(suite *tls.CipherSuite) bool {
+	for _, version := range suite.SupportedVersions {
+		if version == tls.VersionTLS12 {
+			return true
+		}
+	}
+	return false
+}
+
+// handshake returns the state of a tls connection with addr once the server certificate has been received.
+// Endpoints enforcing mutual TLS fail the handshake later on, since no client certificate is presented,
+// but the protocol version and cipher suite have already been agreed by then.
+func handshake(ctx context.Context, addr string, config *tls.Config) (tls.ConnectionState, error) {
+	var state *tls.ConnectionState
+	config.VerifyConnection = func(s tls.ConnectionState) error {
+		state = &s
+		return nil
+	}
+	conn, err := dialTLS(ctx, addr, config)
+	if conn != nil {
+		conn.Close()
+	}
+	if state != nil {
+		return *state, nil
+	}
+	return tls.ConnectionState{}, err
+}
+
+// dialTLS opens a tls connection with addr, through the proxy configured in the environment if any, within
+// tlsDialTimeout and the deadline of ctx
+func dialTLS(ctx context.Context, addr string, config *tls.Config) (*tls.Conn, error) {
+	deadline := time.Now().Add(tlsDialTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	dialer := contextDialer{ctx: ctx, dialer: &net.Dialer{Deadline: deadline}}
+
+	var conn net.Conn
+	var err error
+	if proxyStr := environmentProxy(); proxyStr != "" {
+		uris, err := url.Parse(proxyStr)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse proxy URL")
+		}
+		// the proxy is reached with the dialer bound to ctx, its CONNECT response is awaited until the deadline
+		proxyDialer, err := connectproxy.NewWithConfig(uris, dialer, &connectproxy.Config{
+			InsecureSkipVerify: true,
+			DialTimeout:        time.Until(deadline),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to dial %s using proxy", addr)
+		}
+		conn, err = proxyDialer.Dial("tcp", addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to dial %s using proxy", addr)
+		}
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to dial %s", addr)
+		}
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "tls handshake with %s failed", addr)
+	}
+	return tlsConn, nil
+}
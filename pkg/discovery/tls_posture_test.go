@@ -0,0 +1,202 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func trustedTLSValidator(t *testing.T, cert *x509.Certificate) StdTLSValidator {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return NewStdTLSValidator(tls.VersionTLS12).WithRootCAs(roots)
+}
+
+func TestValidateTLSPostureSucceeds(t *testing.T) {
+	srv, uri := test.HTTPSServer(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+		ClientAuth:   tls.RequireAnyClientCert,
+	}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	r, err := trustedTLSValidator(t, srv.Certificate()).ValidateTLSPosture(TLSEndpointResource, uri, true)
+	require.NoError(t, err)
+
+	assert.Empty(t, r.Errors)
+	assert.True(t, r.Valid)
+	assert.Equal(t, TLSEndpointResource, r.Endpoint)
+	assert.Equal(t, []string{"TLS12"}, r.Versions)
+	assert.ElementsMatch(t, []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}, r.CipherSuites)
+	assert.True(t, r.FAPICipherSuites)
+	assert.True(t, r.ChainValid)
+	assert.False(t, r.Expired)
+	assert.Equal(t, srv.Certificate().NotAfter.Format(time.RFC3339), r.NotAfter)
+	assert.True(t, r.HostnameMatch)
+	assert.False(t, r.OCSPStapled)
+	assert.True(t, r.MTLSEnforced)
+}
+
+func TestValidateTLSPostureReportsWeakPosture(t *testing.T) {
+	srv, uri := test.HTTPSServer(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA},
+	}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	r, err := trustedTLSValidator(t, srv.Certificate()).ValidateTLSPosture(TLSEndpointToken, uri, true)
+	require.NoError(t, err)
+
+	assert.False(t, r.Valid)
+	assert.False(t, r.FAPICipherSuites)
+	assert.Contains(t, r.CipherSuites, "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA")
+	assert.False(t, r.MTLSEnforced)
+	assert.Contains(t, r.Errors, "accepts cipher suite TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA which is not permitted by FAPI")
+	assert.Contains(t, r.Errors, "accepts connections without a client certificate")
+}
+
+func TestValidateTLSPostureDoesNotRequireMTLSOnAuthorizationEndpoint(t *testing.T) {
+	srv, uri := test.HTTPSServer(&tls.Config{MinVersion: tls.VersionTLS13}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	r, err := trustedTLSValidator(t, srv.Certificate()).ValidateTLSPosture(TLSEndpointAuthorization, uri, false)
+	require.NoError(t, err)
+
+	assert.True(t, r.Valid)
+	assert.Equal(t, []string{"TLS13"}, r.Versions)
+	assert.Len(t, r.CipherSuites, 1)
+	assert.False(t, r.MTLSEnforced)
+}
+
+func TestValidateTLSPostureEnforcesMTLSWithTLS13(t *testing.T) {
+	srv, uri := test.HTTPSServer(&tls.Config{MinVersion: tls.VersionTLS13, ClientAuth: tls.RequireAnyClientCert}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	r, err := trustedTLSValidator(t, srv.Certificate()).ValidateTLSPosture(TLSEndpointResource, uri, true)
+	require.NoError(t, err)
+
+	assert.True(t, r.MTLSEnforced)
+	assert.True(t, r.Valid)
+}
+
+func TestValidateTLSPostureReportsUntrustedChain(t *testing.T) {
+	srv, uri := test.HTTPSServer(&tls.Config{MinVersion: tls.VersionTLS12}, http.StatusOK, "", nil)
+	defer srv.Close()
+
+	r, err := NewStdTLSValidator(tls.VersionTLS12).ValidateTLSPosture(TLSEndpointResource, uri, false)
+	require.NoError(t, err)
+
+	assert.False(t, r.Valid)
+	assert.False(t, r.ChainValid)
+	assert.True(t, r.HostnameMatch)
+}
+
+func TestValidateTLSPostureFailsOnNonTLSHost(t *testing.T) {
+	srv, uri := test.HTTPServer(http.StatusServiceUnavailable, "", nil)
+	defer srv.Close()
+
+	_, err := NewStdTLSValidator(tls.VersionTLS12).ValidateTLSPosture(TLSEndpointResource, uri, true)
+	assert.EqualError(t, err, "unable to establish a tls connection with "+srv.Listener.Addr().String())
+}
+
+func TestValidateTLSPostureReportsUnknownMTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close() // neither a response nor an alert
+	}))
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS13}
+	srv.StartTLS()
+	defer srv.Close()
+
+	r, err := trustedTLSValidator(t, srv.Certificate()).ValidateTLSPosture(TLSEndpointResource, srv.URL, true)
+	require.NoError(t, err)
+
+	assert.False(t, r.Valid)
+	assert.False(t, r.MTLSEnforced)
+	require.Len(t, r.Errors, 1)
+	assert.Contains(t, r.Errors[0], "unable to tell whether connections without a client certificate are rejected: EOF")
+}
+
+func TestCertificateRejected(t *testing.T) {
+	rejected, err := certificateRejected(&net.OpError{Op: "remote error", Err: errors.New("tls: certificate required")})
+	assert.True(t, rejected)
+	assert.NoError(t, err)
+
+	handshakeFailure := &net.OpError{Op: "remote error", Err: errors.New("tls: handshake failure")}
+	rejected, err = certificateRejected(handshakeFailure)
+	assert.False(t, rejected)
+	assert.Equal(t, handshakeFailure, err)
+}
+
+func TestDialTLSThroughProxyStopsWithContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close() // never answers the CONNECT request
+		}
+	}()
+	t.Setenv("HTTPS_PROXY", "http://"+listener.Addr().String())
+	t.Setenv("HTTP_PROXY", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = dialTLS(ctx, "aspsp.example:443", &tls.Config{})
+
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestValidateTLSPostureCachesProbesPerHost(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	connections := int32(0)
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS12, ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+	uri := srv.URL
+	validator := trustedTLSValidator(t, srv.Certificate())
+	now := time.Now()
+	validator.postureCache.now = func() time.Time { return now }
+
+	resource, err := validator.ValidateTLSPosture(TLSEndpointResource, uri, true)
+	require.NoError(t, err)
+	probed := atomic.LoadInt32(&connections)
+	token, err := validator.ValidateTLSPosture(TLSEndpointToken, uri+"/token", true)
+	require.NoError(t, err)
+
+	assert.Equal(t, probed, atomic.LoadInt32(&connections), "the host is probed once")
+	assert.Equal(t, resource.Versions, token.Versions)
+	assert.Equal(t, TLSEndpointToken, token.Endpoint)
+
+	now = now.Add(tlsPostureCacheTTL + time.Second)
+	_, err = validator.ValidateTLSPosture(TLSEndpointResource, uri, true)
+	require.NoError(t, err)
+	assert.Greater(t, atomic.LoadInt32(&connections), probed, "the host is probed again once the probes expire")
+}
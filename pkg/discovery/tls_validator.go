@@ -2,6 +2,7 @@ package discovery
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/url"
 	"strings"
@@ -15,16 +16,20 @@ import (
 
 type TLSValidator interface {
 	ValidateTLSVersion(uri string) (TLSValidationResult, error)
+	ValidateTLSPosture(endpoint, uri string, mtlsRequired bool) (TLSPostureResult, error)
 }
 
 type TLSValidationResult struct {
 	Valid      bool
 	TLSVersion string
+	Posture    []TLSPostureResult // TLS posture of the resource, token and authorization endpoints
 }
 
 type StdTLSValidator struct {
 	tlsConfig              *tls.Config
 	minSupportedTLSVersion uint16
+	rootCAs                *x509.CertPool   // Roots used to check certificate chains, nil for the system roots
+	postureCache           *tlsPostureCache // Probes of the hosts, shared by the copies of the validator
 }
 
 type NullTLSValidator struct{}
@@ -37,28 +42,30 @@ func (v NullTLSValidator) ValidateTLSVersion(uri string) (TLSValidationResult, e
 	return TLSValidationResult{}, nil
 }
 
+func (v NullTLSValidator) ValidateTLSPosture(endpoint, uri string, mtlsRequired bool) (TLSPostureResult, error) {
+	return TLSPostureResult{}, nil
+}
+
 func NewStdTLSValidator(minSupportedTLSVersion uint16) StdTLSValidator {
 	return StdTLSValidator{&tls.Config{
 		InsecureSkipVerify: true,
-	}, minSupportedTLSVersion}
+	}, minSupportedTLSVersion, nil, newTLSPostureCache()}
+}
+
+// WithRootCAs returns a copy of the validator checking the certificate chains of the TLS posture against roots
+// instead of the system roots
+func (v StdTLSValidator) WithRootCAs(roots *x509.CertPool) StdTLSValidator {
+	v.rootCAs = roots
+	return v
 }
 
 func (v StdTLSValidator) ValidateTLSVersion(uri string) (TLSValidationResult, error) {
 	var version uint16
 	var strVersion string
 
-	parsedURI, err := url.Parse(uri)
+	addr, _, err := tlsAddress(uri)
 	if err != nil {
-		return TLSValidationResult{}, errors.Wrapf(err, "unable to parse the provided uri %s", uri)
-	}
-	// url.Parse only returns error for uri containing ASCII CTL bytes
-	// in this case checking for blank URI will suffice
-	if strings.TrimSpace(parsedURI.Host) == "" {
-		return TLSValidationResult{}, fmt.Errorf("unable to parse the provided uri %s", uri)
-	}
-	addr := parsedURI.Host
-	if !strings.Contains(parsedURI.Host, ":") {
-		addr = fmt.Sprintf("%s:%d", parsedURI.Host, 443)
+		return TLSValidationResult{}, err
 	}
 
 	proxyState, err := getProxyConnectionState(addr, v.tlsConfig.InsecureSkipVerify)
@@ -86,11 +93,29 @@ func (v StdTLSValidator) ValidateTLSVersion(uri string) (TLSValidationResult, er
 	}
 
 	return TLSValidationResult{
-		version >= v.minSupportedTLSVersion,
-		strVersion,
+		Valid:      version >= v.minSupportedTLSVersion,
+		TLSVersion: strVersion,
 	}, nil
 }
 
+// tlsAddress returns the host:port address to dial for uri, defaulting to port 443, along with its hostname
+func tlsAddress(uri string) (string, string, error) {
+	parsedURI, err := url.Parse(uri)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "unable to parse the provided uri %s", uri)
+	}
+	// url.Parse only returns error for uri containing ASCII CTL bytes
+	// in this case checking for blank URI will suffice
+	if strings.TrimSpace(parsedURI.Host) == "" {
+		return "", "", fmt.Errorf("unable to parse the provided uri %s", uri)
+	}
+	addr := parsedURI.Host
+	if !strings.Contains(parsedURI.Host, ":") {
+		addr = fmt.Sprintf("%s:%d", parsedURI.Host, 443)
+	}
+	return addr, parsedURI.Hostname(), nil
+}
+
 func getConnectionState(addr string, tlsConfig *tls.Config) (tls.ConnectionState, error) {
 	if !strings.Contains(addr, ":") {
 		addr = fmt.Sprintf("%s:%d", addr, 443)
@@ -108,17 +133,7 @@ func getConnectionState(addr string, tlsConfig *tls.Config) (tls.ConnectionState
 }
 
 func getProxyConnectionState(addr string, InsecureSkipVerify bool) (utls.ConnectionState, error) {
-	var proxyStr string
-
-	if httpsProxy := httpproxy.FromEnvironment().HTTPSProxy; httpsProxy != "" {
-		proxyStr = httpsProxy
-	}
-
-	if httpProxy := httpproxy.FromEnvironment().HTTPProxy; httpProxy != "" {
-		proxyStr = httpProxy
-	}
-
-	if proxyStr != "" {
+	if proxyStr := environmentProxy(); proxyStr != "" {
 		uris, err := url.Parse(proxyStr)
 		if err != nil {
 			return utls.ConnectionState{}, errors.Wrap(err, "unable to parse proxy URL")
@@ -156,6 +171,21 @@ func getProxyConnectionState(addr string, InsecureSkipVerify bool) (utls.Connect
 	return utls.ConnectionState{}, nil
 }
 
+// environmentProxy returns the proxy configured in the environment, if any
+func environmentProxy() string {
+	var proxyStr string
+
+	if httpsProxy := httpproxy.FromEnvironment().HTTPSProxy; httpsProxy != "" {
+		proxyStr = httpsProxy
+	}
+
+	if httpProxy := httpproxy.FromEnvironment().HTTPProxy; httpProxy != "" {
+		proxyStr = httpProxy
+	}
+
+	return proxyStr
+}
+
 func tlsVersionToString(v uint16) (string, error) {
 	switch int(v) {
 	case tls.VersionSSL30:
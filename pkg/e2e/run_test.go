@@ -112,7 +112,8 @@ func disableTestRun(t *testing.T) {
 	results, err := service.Run(
 		"../discovery/templates/ob-v3.1-ozone-headless.json",
 		"../../config/config-ozone-run_test.json",
-		"../../config/report.json")
+		"../../config/report.json",
+		client.Selection{})
 	require.NoError(t, err)
 
 	w := bytes.NewBufferString("")
@@ -0,0 +1,305 @@
+package executors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
+)
+
+// CassetteVersion is the version of the format of cassette files
+const CassetteVersion = "1.0"
+
+const (
+	// CassetteRecord records the requests sent and the responses received by a run in the cassette
+	CassetteRecord = "record"
+	// CassetteReplay serves the responses recorded in the cassette, no request is sent
+	CassetteReplay = "replay"
+)
+
+// CassetteDir is the directory, relative to the working directory, cassette files are read from and written to
+const CassetteDir = "cassettes"
+
+// CassetteConfig configures the recording of a run in a cassette file, or its replay offline, by default runs
+// are neither recorded nor replayed
+type CassetteConfig struct {
+	Mode         string               `json:"mode,omitempty"`          // record, replay or empty
+	Path         string               `json:"path,omitempty"`          // Cassette file, within CassetteDir
+	MatchHeaders []string             `json:"match_headers,omitempty"` // Headers matched besides those of DefaultMatchHeaders
+	Normalisers  []CassetteNormaliser `json:"normalisers,omitempty"`   // Applied after DefaultNormalisers
+}
+
+// CassetteNormaliser replaces a dynamic value of the requests, e.g. a date, so a request replayed matches the
+// request recorded
+type CassetteNormaliser struct {
+	Name    string `json:"name"`
+	Header  string `json:"header,omitempty"`  // Header normalised, the URL and body when empty
+	Pattern string `json:"pattern"`           // Regular expression matching the dynamic value
+	Replace string `json:"replace,omitempty"` // Replacement, the name in braces when empty
+}
+
+// DefaultMatchHeaders are the headers matched along with the method, URL and body of the requests
+var DefaultMatchHeaders = []string{"Accept", "Content-Type", "x-idempotency-key", "x-jws-signature"}
+
+// DefaultNormalisers replace identifiers, dates, JWTs, idempotency keys and signatures, which differ each run
+var DefaultNormalisers = []CassetteNormaliser{
+	{Name: "uuid", Pattern: `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`},
+	{Name: "datetime", Pattern: `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?`},
+	{Name: "jwt", Pattern: `eyJ[\w-]*\.[\w-]*\.[\w-]*`},
+	{Name: "idempotency-key", Header: "x-idempotency-key", Pattern: `.+`},
+	{Name: "signature", Header: "x-jws-signature", Pattern: `.+`},
+}
+
+// Validate checks the mode, that the path is relative, within CassetteDir and has no `..` element and that the
+// normalisers are named and their patterns compile
+func (c CassetteConfig) Validate() error {
+	switch c.Mode {
+	case "":
+		return nil
+	case CassetteRecord, CassetteReplay:
+	default:
+		return fmt.Errorf("cassette: mode %q is not %s or %s", c.Mode, CassetteRecord, CassetteReplay)
+	}
+	if c.Path == "" {
+		return errors.New("cassette: path is empty")
+	}
+	if !withinCassetteDir(c.Path) {
+		return fmt.Errorf("cassette: path %q is not within the %s directory", c.Path, CassetteDir)
+	}
+	for _, normaliser := range c.Normalisers {
+		if strings.TrimSpace(normaliser.Name) == "" {
+			return errors.New("cassette: normaliser name is empty")
+		}
+		if _, err := regexp.Compile(normaliser.Pattern); err != nil {
+			return errors.Wrapf(err, "cassette: normaliser %s pattern", normaliser.Name)
+		}
+	}
+	return nil
+}
+
+// withinCassetteDir returns true when path is a relative path of a file in CassetteDir, or one of its
+// subdirectories, with no `..` element
+func withinCassetteDir(path string) bool {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return false
+	}
+	for _, element := range strings.FieldsFunc(filepath.ToSlash(path), func(r rune) bool { return r == '/' }) {
+		if element == ".." {
+			return false
+		}
+	}
+	clean := filepath.Clean(path)
+	return strings.HasPrefix(clean, CassetteDir+string(filepath.Separator)) && clean != CassetteDir
+}
+
+// Cassette holds the requests sent and the responses received by a run, keyed by normalised request. It is
+// shared by the runners of a journey.
+type Cassette struct {
+	config       CassetteConfig
+	matchHeaders []string
+	normalisers  []normaliser
+	lock         sync.Mutex
+	file         cassetteFile
+	served       map[string]int // number of times each key has been replayed
+}
+
+type cassetteFile struct {
+	Version      string        `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request sent and the response received, or the error sending the request
+type Interaction struct {
+	Key        string              `json:"key"`
+	TestCaseID string              `json:"testCaseId"`
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	Response   InteractionResponse `json:"response"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// InteractionResponse is a response recorded, its headers and body redacted
+type InteractionResponse struct {
+	Status     string      `json:"status"`
+	StatusCode int         `json:"statusCode"`
+	Proto      string      `json:"proto"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+type normaliser struct {
+	header  string
+	pattern *regexp.Regexp
+	replace string
+}
+
+// NewCassette returns the cassette configured: empty to record, read from its file to replay, or nil when runs
+// are neither recorded nor replayed
+func NewCassette(config CassetteConfig) (*Cassette, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Mode == "" {
+		return nil, nil
+	}
+
+	c := &Cassette{
+		config:       config,
+		matchHeaders: append(append([]string{}, DefaultMatchHeaders...), config.MatchHeaders...),
+		file:         cassetteFile{Version: CassetteVersion, Interactions: []Interaction{}},
+		served:       map[string]int{},
+	}
+	for _, n := range append(append([]CassetteNormaliser{}, DefaultNormalisers...), config.Normalisers...) {
+		replace := n.Replace
+		if replace == "" {
+			replace = "{" + n.Name + "}"
+		}
+		c.normalisers = append(c.normalisers, normaliser{header: n.Header, pattern: regexp.MustCompile(n.Pattern), replace: replace})
+	}
+
+	if config.Mode == CassetteReplay {
+		raw, err := ioutil.ReadFile(config.Path)
+		if err != nil {
+			return nil, errors.Wrap(err, "cassette: reading")
+		}
+		if err := json.Unmarshal(raw, &c.file); err != nil {
+			return nil, errors.Wrapf(err, "cassette: parsing %s", config.Path)
+		}
+		if c.file.Version != CassetteVersion {
+			return nil, fmt.Errorf("cassette: version %q of %s is not %s", c.file.Version, config.Path, CassetteVersion)
+		}
+	}
+	return c, nil
+}
+
+// Recording returns true when the cassette records a run
+func (c *Cassette) Recording() bool {
+	return c != nil && c.config.Mode == CassetteRecord
+}
+
+// Executor returns executor recording to the cassette, or an executor replaying the cassette, which does not
+// use executor
+func (c *Cassette) Executor(executor TestCaseExecutor) TestCaseExecutor {
+	if c.config.Mode == CassetteReplay {
+		return &replayingExecutor{cassette: c}
+	}
+	return &recordingExecutor{executor: executor, cassette: c}
+}
+
+// Save writes the interactions recorded to the cassette file
+func (c *Cassette) Save() error {
+	c.lock.Lock()
+	raw, err := json.MarshalIndent(c.file, "", "  ")
+	c.lock.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "cassette: encoding")
+	}
+	if dir := filepath.Dir(c.config.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errors.Wrap(err, "cassette: creating directory")
+		}
+	}
+	return errors.Wrap(ioutil.WriteFile(c.config.Path, raw, 0600), "cassette: writing")
+}
+
+// Key returns the normalised request: its method, URL unescaped, matched headers and body, dynamic values
+// normalised and secrets redacted
+func (c *Cassette) Key(r *resty.Request) string {
+	u := requestURL(r)
+	if unescaped, err := url.QueryUnescape(u); err == nil {
+		u = unescaped
+	}
+	lines := []string{strings.ToUpper(r.Method) + " " + c.normalise("", u)}
+	for _, name := range c.matchHeaders {
+		if value := r.Header.Get(name); value != "" {
+			lines = append(lines, strings.ToLower(name)+": "+c.normalise(name, value))
+		}
+	}
+	sort.Strings(lines[1:])
+	lines = append(lines, "", c.normalise("", requestBody(r)))
+	return redact.String(strings.Join(lines, "\n"))
+}
+
+func (c *Cassette) normalise(header, value string) string {
+	for _, n := range c.normalisers {
+		if strings.EqualFold(n.header, header) {
+			value = n.pattern.ReplaceAllString(value, n.replace)
+		}
+	}
+	return value
+}
+
+func (c *Cassette) record(interaction Interaction) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.file.Interactions = append(c.file.Interactions, interaction)
+}
+
+// interaction returns the next interaction recorded with key, in the order they were recorded, the last one
+// again once all have been replayed
+func (c *Cassette) interaction(key string) (Interaction, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	matches := []Interaction{}
+	for _, interaction := range c.file.Interactions {
+		if interaction.Key == key {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 {
+		return Interaction{}, false
+	}
+	served := c.served[key]
+	c.served[key]++
+	if served >= len(matches) {
+		served = len(matches) - 1
+	}
+	return matches[served], true
+}
+
+// requestURL returns the URL of the request with its query parameters, sorted, those of r.QueryParam not
+// already in the URL added, as resty adds them to the URL of the requests executed
+func requestURL(r *resty.Request) string {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return r.URL
+	}
+	query := u.Query()
+	for name, values := range r.QueryParam {
+		if _, ok := query[name]; !ok {
+			query[name] = append([]string{}, values...)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// requestBody returns the body of the request, its form data encoded when it has no body
+func requestBody(r *resty.Request) string {
+	switch body := r.Body.(type) {
+	case nil:
+		return r.FormData.Encode()
+	case string:
+		return body
+	case []byte:
+		return string(body)
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Sprint(body)
+		}
+		return string(raw)
+	}
+}
@@ -0,0 +1,128 @@
+package executors
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
+)
+
+// recordingExecutor records the requests sent by an executor in a cassette, along with the responses received
+type recordingExecutor struct {
+	executor TestCaseExecutor
+	cassette *Cassette
+}
+
+// ExecuteTestCase executes the test case and records the exchange, unless the endpoint is not called
+func (e *recordingExecutor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, results.Metrics, error) {
+	if t.DoNotCallEndpoint {
+		return e.executor.ExecuteTestCase(r, t, ctx)
+	}
+	key := e.cassette.Key(r)
+	resp, metrics, err := e.executor.ExecuteTestCase(r, t, ctx)
+
+	interaction := Interaction{Key: key, TestCaseID: t.ID, Method: r.Method, URL: redact.String(requestURL(r))}
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+	if resp != nil && resp.RawResponse != nil {
+		interaction.Response = InteractionResponse{
+			Status:     resp.Status(),
+			StatusCode: resp.StatusCode(),
+			Proto:      resp.RawResponse.Proto,
+			Header:     redact.Header(resp.Header()),
+			Body:       redact.String(string(resp.Body())),
+		}
+	}
+	e.cassette.record(interaction)
+	return resp, metrics, err
+}
+
+// SetCertificates sets the certificates of the executor recorded
+func (e *recordingExecutor) SetCertificates(certificateSigning, certificationTransport authentication.Certificate) error {
+	return e.executor.SetCertificates(certificateSigning, certificationTransport)
+}
+
+// replayingExecutor serves the responses recorded in a cassette, it does not send any request
+type replayingExecutor struct {
+	cassette *Cassette
+}
+
+// ExecuteTestCase returns the response recorded for the request, or an error when none was recorded
+func (e *replayingExecutor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, results.Metrics, error) {
+	if t.DoNotCallEndpoint {
+		return emptyResponse(), results.NoMetrics(), nil
+	}
+	key := e.cassette.Key(r)
+	interaction, ok := e.cassette.interaction(key)
+	if !ok {
+		return &resty.Response{Request: r}, results.NoMetrics(), fmt.Errorf("cassette: no response recorded for test case %s request %s %s", t.ID, r.Method, redact.String(requestURL(r)))
+	}
+	if interaction.Response.StatusCode == 0 {
+		return &resty.Response{Request: r}, results.NoMetrics(), errors.New(interaction.Error)
+	}
+
+	client := resty.New().
+		SetTransport(interactionTransport{interaction.Response}).
+		SetRedirectPolicy(resty.NoRedirectPolicy())
+	resp, err := copyRequest(client.R(), r).Execute(r.Method, r.URL)
+	if err != nil && resp.StatusCode() != http.StatusFound {
+		return resp, metrics(t, resp), errors.Wrap(err, "cassette: replaying")
+	}
+	t.StatusCode = resp.Status()
+	if interaction.Error != "" && resp.StatusCode() != http.StatusFound {
+		return resp, metrics(t, resp), errors.New(interaction.Error)
+	}
+	return resp, metrics(t, resp), nil
+}
+
+// SetCertificates does nothing, no request is sent
+func (e *replayingExecutor) SetCertificates(certificateSigning, certificationTransport authentication.Certificate) error {
+	return nil
+}
+
+// interactionTransport returns the response recorded whatever the request
+type interactionTransport struct {
+	response InteractionResponse
+}
+
+func (t interactionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		Status:        t.response.Status,
+		StatusCode:    t.response.StatusCode,
+		Proto:         t.response.Proto,
+		Header:        t.response.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(t.response.Body))),
+		ContentLength: int64(len(t.response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// copyRequest copies the method, URL, headers, query parameters, body and form data of r to req
+func copyRequest(req, r *resty.Request) *resty.Request {
+	req.Method = r.Method
+	req.URL = r.URL
+	req.Header = r.Header.Clone()
+	for name, values := range r.QueryParam {
+		req.QueryParam[name] = append([]string{}, values...)
+	}
+	if r.Body != nil {
+		req.SetBody(r.Body)
+	}
+	if len(r.FormData) > 0 {
+		req.FormData = url.Values{}
+		for name, values := range r.FormData {
+			req.FormData[name] = append([]string{}, values...)
+		}
+	}
+	return req
+}
@@ -0,0 +1,158 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func paymentConsentTestCase(url, idempotencyKey string) model.TestCase {
+	return model.TestCase{
+		ID: "OB-301-DOP-100100",
+		Input: model.Input{
+			Method:      http.MethodPost,
+			Endpoint:    url,
+			Headers:     map[string]string{"Authorization": "Bearer 2f6c5b1e", "x-idempotency-key": idempotencyKey},
+			RequestBody: `{"Data":{"Initiation":{"InstructionIdentification":"` + idempotencyKey + `"}}}`,
+		},
+		Expect: model.Expect{
+			StatusCode: http.StatusCreated,
+			ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "consent_id", JSON: "Data.ConsentId"}}},
+		},
+		Validator: schema.NewNullValidator(),
+	}
+}
+
+func consentServer(t *testing.T) (*httptest.Server, *int) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"Data":{"ConsentId":"sdp-%d","Status":"AwaitingAuthorisation"},"access_token":"2f6c5b1e"}`, calls)
+	}))
+	return server, &calls
+}
+
+func cassetteTestRunner(t *testing.T, mode string) *TestCaseRunner {
+	cassette, err := NewCassette(CassetteConfig{
+		Mode:        mode,
+		Path:        "cassettes/ozone.json",
+		Normalisers: []CassetteNormaliser{{Name: "instruction", Pattern: `"InstructionIdentification":"\w+"`, Replace: `"InstructionIdentification":"{instruction}"`}},
+	})
+	require.NoError(t, err)
+	runner := requestTestRunner()
+	runner.definition.Cassette = cassette
+	runner.executor = cassette.Executor(requestExecutor{})
+	return runner
+}
+
+func TestCassetteRecordsAndReplays(t *testing.T) {
+	inTempDir(t)
+	server, calls := consentServer(t)
+	recorder := cassetteTestRunner(t, CassetteRecord)
+
+	for _, key := range []string{"a1b2c3", "d4e5f6"} {
+		ctx := &model.Context{}
+		result := recorder.executeTest(paymentConsentTestCase(server.URL, key), ctx, test.NullLogger())
+		require.True(t, result.Pass, result.Fail)
+	}
+	recorder.saveCassette()
+	server.Close()
+	require.Equal(t, 2, *calls)
+
+	replayer := cassetteTestRunner(t, CassetteReplay)
+	for i, key := range []string{"g7h8i9", "j0k1l2", "m3n4o5"} {
+		ctx := &model.Context{}
+		result := replayer.executeTest(paymentConsentTestCase(server.URL, key), ctx, test.NullLogger())
+
+		require.True(t, result.Pass, result.Fail)
+		consentID, err := ctx.GetString("consent_id")
+		require.NoError(t, err)
+		expected := fmt.Sprintf("sdp-%d", i+1)
+		if i == 2 {
+			expected = "sdp-2" // the last response recorded is replayed once all have been
+		}
+		assert.Equal(t, expected, consentID)
+	}
+	assert.Equal(t, 2, *calls, "no request is sent when replaying")
+}
+
+func TestCassetteRecordsRedactedResponses(t *testing.T) {
+	inTempDir(t)
+	server, _ := consentServer(t)
+	defer server.Close()
+	recorder := cassetteTestRunner(t, CassetteRecord)
+
+	recorder.executeTest(paymentConsentTestCase(server.URL, "a1b2c3"), &model.Context{}, test.NullLogger())
+
+	interactions := recorder.definition.Cassette.file.Interactions
+	require.Len(t, interactions, 1)
+	assert.Equal(t, "OB-301-DOP-100100", interactions[0].TestCaseID)
+	assert.Equal(t, http.StatusCreated, interactions[0].Response.StatusCode)
+	assert.Contains(t, interactions[0].Response.Body, `"access_token":"[redacted]"`)
+	assert.Contains(t, interactions[0].Key, `x-idempotency-key: {idempotency-key}`)
+}
+
+func TestCassetteReplayWithoutRecordedResponse(t *testing.T) {
+	inTempDir(t)
+	cassetteTestRunner(t, CassetteRecord).saveCassette()
+	replayer := cassetteTestRunner(t, CassetteReplay)
+
+	result := replayer.executeTest(paymentConsentTestCase("https://ob19-rs1.o3bank.co.uk:4501/domestic-payment-consents", "a1b2c3"), &model.Context{}, test.NullLogger())
+
+	assert.False(t, result.Pass)
+	require.Len(t, result.Fail, 1)
+	assert.Contains(t, result.Fail[0], "cassette: no response recorded for test case OB-301-DOP-100100 request POST https://ob19-rs1.o3bank.co.uk:4501/domestic-payment-consents")
+}
+
+func TestCassetteReplaysCodeExchange(t *testing.T) {
+	inTempDir(t)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"2f6c5b1e","token_type":"Bearer","expires_in":3600}`)
+	}))
+	exchange := func(mode, code string) (*grantToken, error) {
+		cassette, err := NewCassette(CassetteConfig{Mode: mode, Path: "cassettes/ozone.json"})
+		require.NoError(t, err)
+		client, err := NewClient(ClientConfig{}, nil)
+		require.NoError(t, err)
+		definition := RunDefinition{Cassette: cassette, Client: client}
+		ctx := &model.Context{
+			"basic_authentication":    "b3pvbmU6c2VjcmV0",
+			"token_endpoint":          server.URL + "/token",
+			"redirect_url":            "https://127.0.0.1:8443/conformancesuite/callback",
+			"client_id":               "ozone",
+			"requestObjectSigningAlg": "PS256",
+			"signingPrivate":          signingPrivate,
+			"signingPublic":           signingPublic,
+		}
+		token, err := exchangeCodeForToken(definition, code, ctx, test.NullLogger())
+		if cassette.Recording() {
+			require.NoError(t, cassette.Save())
+		}
+		return token, err
+	}
+
+	recorded, err := exchange(CassetteRecord, "a1b2c3")
+	require.NoError(t, err)
+	assert.Equal(t, "2f6c5b1e", recorded.AccessToken)
+	server.Close()
+
+	replayed, err := exchange(CassetteReplay, "d4e5f6")
+
+	require.NoError(t, err, "the code is redacted from the key")
+	assert.Equal(t, "[redacted]", replayed.AccessToken)
+	assert.Equal(t, "Bearer", replayed.TokenType)
+	assert.Equal(t, 1, calls, "no request is sent when replaying")
+}
@@ -0,0 +1,98 @@
+package executors
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+)
+
+// inTempDir runs the test in a temporary working directory, cassette paths being relative
+func inTempDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestCassetteConfigValidate(t *testing.T) {
+	assert.NoError(t, CassetteConfig{}.Validate())
+	assert.NoError(t, CassetteConfig{Mode: CassetteRecord, Path: "cassettes/ozone.json"}.Validate())
+	assert.EqualError(t, CassetteConfig{Mode: "rewind", Path: "ozone.json"}.Validate(), `cassette: mode "rewind" is not record or replay`)
+	assert.EqualError(t, CassetteConfig{Mode: CassetteReplay}.Validate(), "cassette: path is empty")
+	assert.NoError(t, CassetteConfig{Mode: CassetteRecord, Path: "./cassettes/sandbox/ozone.json"}.Validate())
+	assert.EqualError(t, CassetteConfig{Mode: CassetteReplay, Path: "/etc/passwd"}.Validate(), `cassette: path "/etc/passwd" is not within the cassettes directory`)
+	assert.EqualError(t, CassetteConfig{Mode: CassetteReplay, Path: "cassettes/../../ozone.json"}.Validate(), `cassette: path "cassettes/../../ozone.json" is not within the cassettes directory`)
+	assert.EqualError(t, CassetteConfig{Mode: CassetteRecord, Path: "cassettes/sandbox/../ozone.json"}.Validate(), `cassette: path "cassettes/sandbox/../ozone.json" is not within the cassettes directory`)
+	assert.EqualError(t, CassetteConfig{Mode: CassetteRecord, Path: "ozone.json"}.Validate(), `cassette: path "ozone.json" is not within the cassettes directory`)
+	assert.EqualError(t, CassetteConfig{Mode: CassetteRecord, Path: "cassettes"}.Validate(), `cassette: path "cassettes" is not within the cassettes directory`)
+	assert.EqualError(t, CassetteConfig{Mode: CassetteRecord, Path: "cassettes/ozone.json", Normalisers: []CassetteNormaliser{{Pattern: ".+"}}}.Validate(), "cassette: normaliser name is empty")
+	assert.Error(t, CassetteConfig{Mode: CassetteRecord, Path: "cassettes/ozone.json", Normalisers: []CassetteNormaliser{{Name: "date", Pattern: "("}}}.Validate())
+}
+
+func TestNewCassetteNotConfigured(t *testing.T) {
+	cassette, err := NewCassette(CassetteConfig{})
+
+	require.NoError(t, err)
+	assert.Nil(t, cassette)
+	assert.False(t, cassette.Recording())
+}
+
+func TestNewCassetteReplayReadsFile(t *testing.T) {
+	inTempDir(t)
+
+	_, err := NewCassette(CassetteConfig{Mode: CassetteReplay, Path: "cassettes/ozone.json"})
+	assert.Error(t, err)
+
+	require.NoError(t, os.Mkdir(CassetteDir, 0755))
+	require.NoError(t, ioutil.WriteFile("cassettes/ozone.json", []byte(`{"version":"0.1","interactions":[]}`), 0600))
+	_, err = NewCassette(CassetteConfig{Mode: CassetteReplay, Path: "cassettes/ozone.json"})
+	assert.EqualError(t, err, `cassette: version "0.1" of cassettes/ozone.json is not 1.0`)
+}
+
+func TestCassetteKey(t *testing.T) {
+	cassette, err := NewCassette(CassetteConfig{
+		Mode:         CassetteRecord,
+		Path:         "cassettes/ozone.json",
+		MatchHeaders: []string{"x-fapi-financial-id"},
+		Normalisers:  []CassetteNormaliser{{Name: "amount", Pattern: `"Amount":"[0-9.]+"`, Replace: `"Amount":"{amount}"`}},
+	})
+	require.NoError(t, err)
+
+	req := resty.R().
+		SetHeader("Accept", "application/json").
+		SetHeader("Authorization", "Bearer 2f6c5b1e").
+		SetHeader("x-fapi-financial-id", "0015800001041RHAAY").
+		SetHeader("x-idempotency-key", "a1b2c3").
+		SetQueryParam("fromBookingDateTime", "2026-10-01T00:00:00Z").
+		SetBody(`{"Data":{"ConsentId":"aac-0e8a1c2b-6f2e-4b7a-9a3c-2d1f0e9b8c7a","Amount":"10.50"}}`)
+	req.Method = http.MethodPost
+	req.URL = "https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp/accounts"
+
+	assert.Equal(t, `POST https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp/accounts?fromBookingDateTime={datetime}
+accept: application/json
+x-fapi-financial-id: 0015800001041RHAAY
+x-idempotency-key: {idempotency-key}
+
+{"Data":{"ConsentId":"aac-{uuid}","Amount":"{amount}"}}`, cassette.Key(req))
+
+	executed := reissueRequest(req)
+	executed.URL += "?fromBookingDateTime=2026-10-19T08%3A00%3A00%2B01%3A00"
+	executed.SetHeader("x-idempotency-key", "d4e5f6")
+	assert.Equal(t, cassette.Key(req), cassette.Key(executed), "the query parameters are in the URL of the requests executed")
+}
+
+func TestCassetteKeyRedactsSecrets(t *testing.T) {
+	cassette, err := NewCassette(CassetteConfig{Mode: CassetteRecord, Path: "cassettes/ozone.json"})
+	require.NoError(t, err)
+
+	req := resty.R().SetFormData(map[string]string{"grant_type": "refresh_token", "refresh_token": "2f6c5b1e"})
+	req.Method = http.MethodPost
+	req.URL = "https://ob19-auth1.o3bank.co.uk:4201/token"
+
+	assert.Equal(t, "POST https://ob19-auth1.o3bank.co.uk:4201/token\n\ngrant_type=refresh_token&refresh_token=[redacted]", cassette.Key(req))
+}
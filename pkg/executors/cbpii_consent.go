@@ -15,7 +15,7 @@ func getCbpiiConsents(
 	requiredTokens []manifest.RequiredTokens,
 	ctx *model.Context,
 ) (TokenConsentIDs, error) {
-	executor := &Executor{}
+	executor := newRunExecutor(definition)
 	err := executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		logrus.Error(fmt.Sprintf("error running cbpii consent acquisition: %s", err))
@@ -42,7 +42,7 @@ func getCbpiiConsents(
 	return consentItems, err
 }
 
-func runCbpiiConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor *Executor) ([]manifest.RequiredTokens, error) {
+func runCbpiiConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor TestCaseExecutor) ([]manifest.RequiredTokens, error) {
 	localCtx := model.Context{}
 	localCtx.PutContext(ctx)
 	localCtx.PutString("scope", "fundsconfirmations")
@@ -0,0 +1,167 @@
+package executors
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication/certificates"
+)
+
+// ClientConfig configures the HTTP client of a run. The proxy is taken from the `HTTPS_PROXY`, `HTTP_PROXY` and
+// `NO_PROXY` environment variables when not set.
+type ClientConfig struct {
+	Proxy               string `json:"proxy,omitempty"`                 // URL of the proxy, with its user and password when it requires authentication
+	NoProxy             string `json:"no_proxy,omitempty"`              // Hosts not proxied, in the format of NO_PROXY
+	Timeout             string `json:"timeout,omitempty"`               // Timeout of each request, not limited if not set
+	DialTimeout         string `json:"dial_timeout,omitempty"`          // Timeout of each connection, 30s if not set
+	TLSHandshakeTimeout string `json:"tls_handshake_timeout,omitempty"` // Timeout of each TLS handshake, 10s if not set
+	HTTP2               bool   `json:"http2,omitempty"`                 // Negotiates HTTP/2, HTTP/1.1 only if not set
+	RootCAs             string `json:"root_cas,omitempty"`              // PEM of the CAs trusted, in addition to the system and Open Banking CAs
+}
+
+// Validate checks the proxy, timeouts and root CAs of the configuration
+func (c ClientConfig) Validate() error {
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return errors.New("http client proxy: not a URL")
+		}
+	}
+	for _, timeout := range []string{c.Timeout, c.DialTimeout, c.TLSHandshakeTimeout} {
+		if timeout == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("http client timeout %q: %s", timeout, err.Error())
+		}
+		if d < 0 {
+			return fmt.Errorf("http client timeout %q: must not be negative", timeout)
+		}
+	}
+	if c.RootCAs != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(c.RootCAs)) {
+		return errors.New("http client root_cas: no certificate found")
+	}
+	return nil
+}
+
+// NewClient returns the HTTP client of a run: authenticated by the transport certificate over mutual TLS, trusting
+// the system, Open Banking and configured CAs, proxied as configured. The client is not shared with other runs.
+func NewClient(config ClientConfig, transportCert authentication.Certificate) (*resty.Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := clientTLSConfig(config, transportCert)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: clientProxy(config),
+		DialContext: (&net.Dialer{
+			Timeout:   durationOr(config.DialTimeout, 30*time.Second),
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   durationOr(config.TLSHandshakeTimeout, 10*time.Second),
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     config.HTTP2,
+	}
+
+	client := resty.New().
+		SetTransport(transport).
+		SetTimeout(durationOr(config.Timeout, 0)).
+		SetRedirectPolicy(resty.FlexibleRedirectPolicy(15)).
+		SetDebug(resty.DefaultClient.Debug)
+	client.Log = resty.DefaultClient.Log // the HTTP trace is logged as configured by the flags
+	return client, nil
+}
+
+// clientTLSConfig returns the TLS configuration of the client, with the transport certificate if any
+func clientTLSConfig(config ClientConfig, transportCert authentication.Certificate) (*tls.Config, error) {
+	caCertPool, err := RootCAs(config.RootCAs)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:            caCertPool,
+		InsecureSkipVerify: false,
+		MinVersion:         tls.VersionTLS12,
+		Renegotiation:      tls.RenegotiateFreelyAsClient,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+			tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+		},
+	}
+	if transportCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{transportCert.TLSCert()}
+	}
+	return tlsConfig, nil
+}
+
+// RootCAs returns the CAs trusted by the HTTP clients: the system CAs, the Open Banking CAs and those of pem, if any
+func RootCAs(pem string) (*x509.CertPool, error) {
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, errors.Wrap(err, "http client SystemCertPool")
+	}
+	if ok := caCertPool.AppendCertsFromPEM(certificates.OpenBankingSandBoxIssuingCA()); !ok {
+		return nil, errors.New("http client failed to append OpenBankingSandBoxIssuingCA")
+	}
+	if ok := caCertPool.AppendCertsFromPEM(certificates.OpenBankingSandBoxRootCA()); !ok {
+		return nil, errors.New("http client failed to append OpenBankingSandBoxRootCA")
+	}
+	if ok := caCertPool.AppendCertsFromPEM(certificates.OpenBankingIssuingCA()); !ok {
+		return nil, errors.New("http client failed to append OpenBankingIssuingCA")
+	}
+	if ok := caCertPool.AppendCertsFromPEM(certificates.OpenBankingRootCA()); !ok {
+		return nil, errors.New("http client failed to append OpenBankingRootCA")
+	}
+	if pem != "" {
+		caCertPool.AppendCertsFromPEM([]byte(pem))
+	}
+	return caCertPool, nil
+}
+
+// clientProxy returns the proxy of each request, as configured or else as the environment at the time the client
+// is made
+func clientProxy(config ClientConfig) func(*http.Request) (*url.URL, error) {
+	proxyConfig := httpproxy.FromEnvironment()
+	if config.Proxy != "" {
+		proxyConfig = &httpproxy.Config{HTTPProxy: config.Proxy, HTTPSProxy: config.Proxy, NoProxy: config.NoProxy}
+	} else if config.NoProxy != "" {
+		proxyConfig.NoProxy = config.NoProxy
+	}
+	proxy := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// execute sends the request with the client. The URL, headers and raw request sent are set back on r, as resty sets
+// them on the requests of its own client.
+func execute(client *resty.Client, r *resty.Request) (*resty.Response, error) {
+	req := copyRequest(client.R(), r)
+	resp, err := req.Execute(r.Method, r.URL)
+	r.URL, r.Header, r.RawRequest, r.Time = req.URL, req.Header, req.RawRequest, req.Time
+	return resp, err
+}
@@ -54,7 +54,7 @@ func GetHeadlessConsent(definition RunDefinition, ctx *model.Context, specRun *g
 func getPaymentHeadlessTokens(paymentTests []model.TestCase, ctx *model.Context, definition RunDefinition, requiredTokens []manifest.RequiredTokens, logger *logrus.Entry) ([]manifest.RequiredTokens, error) {
 	logger.Debug("getPaymentHeadlessTokens")
 
-	executor := Executor{}
+	executor := newRunExecutor(definition)
 	err := executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		return nil, err
@@ -62,12 +62,12 @@ func getPaymentHeadlessTokens(paymentTests []model.TestCase, ctx *model.Context,
 
 	logger.Debugf("we have %d required tokens", len(requiredTokens))
 
-	requiredTokens, err = runPaymentConsents(requiredTokens, ctx, &executor)
+	requiredTokens, err = runPaymentConsents(requiredTokens, ctx, executor)
 	if err != nil {
 		logger.Errorf("getPaymentConsents error: " + err.Error())
 	}
 
-	tokendata, err := CallPaymentHeadlessConsentUrls(&requiredTokens, ctx, logger)
+	tokendata, err := CallPaymentHeadlessConsentUrls(definition, &requiredTokens, ctx, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -86,8 +86,8 @@ func getPaymentHeadlessTokens(paymentTests []model.TestCase, ctx *model.Context,
 
 }
 
-// CallPaymentHeadlessConsentUrls -
-func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Context, logger *logrus.Entry) (map[string]string, error) {
+// CallPaymentHeadlessConsentUrls - calls the consent URLs and exchanges the codes with the executor of the run
+func CallPaymentHeadlessConsentUrls(definition RunDefinition, rt *[]manifest.RequiredTokens, ctx *model.Context, logger *logrus.Entry) (map[string]string, error) {
 	var matchingGroup []string
 	exchangeCode := ""
 	exhangeCodeRegex := "code=([^&]*)&"
@@ -97,11 +97,10 @@ func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Co
 		endpoint := tokendata.ConsentURL
 		var resp *resty.Response
 
-		resp, err := resty.R().
-			SetHeader("accept", "*/*").
-			Get(endpoint)
+		resp, err := sendRequest(definition, "headless consent "+tokendata.Name, http.MethodGet, endpoint, resty.R().
+			SetHeader("accept", "*/*"))
 
-		if err != nil {
+		if err != nil || resp.StatusCode() == http.StatusFound {
 			if resp != nil && resp.StatusCode() == http.StatusFound { // catch status code 302 redirects and pass back as good response
 				header := resp.Header()
 				logger.Debugf("redirection headers: %#v", header)
@@ -116,7 +115,7 @@ func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Co
 						return nil, fmt.Errorf("Header Regex Context Match Failed - regex (%s) failed to find anything on Header (%s) value (%s)", exhangeCodeRegex, "Location", location)
 					}
 					exchangeCode = matchingGroup[1]
-					logger.Tracef("retrieved Exchange code: %s", exchangeCode)
+					logger.Trace("retrieved Exchange code")
 				}
 
 			} else {
@@ -138,7 +137,7 @@ func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Co
 			return nil, err
 		}
 
-		resp, err = resty.R().
+		resp, err = sendRequest(definition, "headless exchange code "+tokendata.Name, http.MethodPost, params["token_endpoint"], resty.R().
 			SetHeader("content-type", "application/x-www-form-urlencoded").
 			SetHeader("accept", "application/json").
 			SetHeader("authorization", "Basic "+params["basic_authentication"]).
@@ -147,8 +146,7 @@ func CallPaymentHeadlessConsentUrls(rt *[]manifest.RequiredTokens, ctx *model.Co
 				"redirect_uri": params["redirect_url"],
 				"grant_type":   "authorization_code",
 				"scope":        "payments",
-			}).
-			Post(params["token_endpoint"])
+			}))
 
 		if err != nil {
 			logger.WithFields(logrus.Fields{
@@ -196,7 +194,7 @@ func getAccountsHeadlessTokens(tests []model.TestCase, ctx *model.Context, defin
 	}
 
 	bodyDataEnd := fmt.Sprintf(`], "TransactionFromDateTime": "%s", "TransactionToDateTime": "%s" },  "Risk": {} }`, txnFrom, txnTo)
-	executor := NewExecutor()
+	executor := newRunExecutor(definition)
 	err = executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		return nil, err
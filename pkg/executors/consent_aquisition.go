@@ -3,6 +3,7 @@ package executors
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
@@ -23,7 +24,7 @@ const (
 )
 
 // GetPsuConsent -
-func GetPsuConsent(definition RunDefinition, ctx *model.Context, runTests *generation.SpecRun, permissions map[string][]manifest.RequiredTokens) (TokenConsentIDs, map[string]string, error) {
+func GetPsuConsent(definition RunDefinition, ctx *model.RunContext, runTests *generation.SpecRun, permissions map[string][]manifest.RequiredTokens) (TokenConsentIDs, map[string]string, error) {
 	var consentIdsToReturn TokenConsentIDs
 
 	for specType := range permissions {
@@ -39,21 +40,33 @@ func GetPsuConsent(definition RunDefinition, ctx *model.Context, runTests *gener
 			}
 
 		case "payments":
-			consentIds, err := getPaymentConsents(definition, permissions["payments"], ctx)
+			var consentIds TokenConsentIDs
+			err := ctx.Update(model.ScopeJourney, func(ctx *model.Context) (err error) {
+				consentIds, err = getPaymentConsents(definition, permissions["payments"], ctx)
+				return err
+			})
 			consentIdsToReturn = append(consentIdsToReturn, consentIds...)
 			if err != nil {
 				logrus.Error("GetPSUConsent - payments error: " + err.Error())
 				return nil, nil, err
 			}
 		case "cbpii":
-			consentIds, err := getCbpiiConsents(definition, permissions["cbpii"], ctx)
+			var consentIds TokenConsentIDs
+			err := ctx.Update(model.ScopeJourney, func(ctx *model.Context) (err error) {
+				consentIds, err = getCbpiiConsents(definition, permissions["cbpii"], ctx)
+				return err
+			})
 			consentIdsToReturn = append(consentIdsToReturn, consentIds...)
 			if err != nil {
 				logrus.Error("GetPSUConsent - cbpii error: " + err.Error())
 				return nil, nil, err
 			}
 		case "vrps":
-			consentIds, err := getPaymentConsents(definition, permissions["vrps"], ctx)
+			var consentIds TokenConsentIDs
+			err := ctx.Update(model.ScopeJourney, func(ctx *model.Context) (err error) {
+				consentIds, err = getPaymentConsents(definition, permissions["vrps"], ctx)
+				return err
+			})
 			consentIdsToReturn = append(consentIdsToReturn, consentIds...)
 			if err != nil {
 				logrus.Error("GetPSUConsent - vrps error: " + err.Error())
@@ -85,7 +98,7 @@ func getSpecForSpecType(stype string, specRun *generation.SpecRun) ([]model.Test
 }
 
 // getAccountConsents - get required tokens
-func getAccountConsents(definition RunDefinition, permissions []manifest.RequiredTokens, ctx *model.Context) (TokenConsentIDs, map[string]string, error) {
+func getAccountConsents(definition RunDefinition, permissions []manifest.RequiredTokens, ctx *model.RunContext) (TokenConsentIDs, map[string]string, error) {
 	consentIDChannel := make(chan TokenConsentIDItem, 100)
 	logger := logrus.StandardLogger().WithField("module", "getAccountConsents")
 	logger.Tracef("getAccountConsents")
@@ -105,7 +118,7 @@ func getAccountConsents(definition RunDefinition, permissions []manifest.Require
 		runner := NewConsentAcquisitionRunner(logrus.StandardLogger().WithField("module", "InitiationConsentAcquisition"), definition, NewBufferedDaemonController())
 		tokenAcquisitionType := definition.DiscoModel.DiscoveryModel.TokenAcquisition
 		permissionString := buildPermissionString(permissionList)
-		consentInfo := TokenConsentIDItem{TokenName: tokenName, Permissions: permissionString}
+		consentInfo := TokenConsentIDItem{TokenName: tokenName, Permissions: permissionString, Consent: rt.Consent}
 		err := runner.RunConsentAcquisition(consentInfo, ctx, tokenAcquisitionType, consentIDChannel)
 		if err != nil {
 			logger.WithError(err).Debug("InitiationConsentAcquisition")
@@ -115,7 +128,11 @@ func getAccountConsents(definition RunDefinition, permissions []manifest.Require
 	consentItems, err := waitForConsentIDs(consentIDChannel, len(tokenParameters))
 	for _, v := range consentItems {
 		logger.Debugf("Setting Token: %s, ConsentId: %s", v.TokenName, v.ConsentID)
-		ctx.PutString(v.TokenName, v.ConsentID)
+		ctx.Put(model.ScopeJourney, v.TokenName, v.ConsentID)
+		if v.Consent != "" {
+			// the token name is reused for the access token once collected, keep the consentId of dedicated consents
+			ctx.Put(model.ScopeJourney, ConsentIDContextKey(v.Consent), v.ConsentID)
+		}
 	}
 	logrus.Debugf("we have %d consentIds: %#v", len(consentItems), consentItems)
 	return consentItems, tokenParameters, err
@@ -190,15 +207,14 @@ type ExchangeParameters struct {
 	TokenName           string
 }
 
-// ExchangeCodeForAccessToken - runs a testcase to perform this operation
-func ExchangeCodeForAccessToken(tokenName, code string, ctx *model.Context) (accesstoken string, err error) {
+// ExchangeCodeForAccessToken - exchanges the code for an access token with the executor of the run
+func ExchangeCodeForAccessToken(definition RunDefinition, tokenName, code string, ctx *model.Context) (accesstoken string, err error) {
 	logger := logrus.StandardLogger().WithFields(logrus.Fields{
 		"module":    "ExchangeCodeForAccessToken",
 		"tokenName": tokenName,
-		"code":      code,
 	})
 
-	grantToken, err := exchangeCodeForToken(code, ctx, logger)
+	grantToken, err := exchangeCodeForToken(definition, code, ctx, logger)
 	if err != nil {
 		logger.WithFields(logrus.Fields{
 			"err": err,
@@ -216,10 +232,9 @@ type grantToken struct {
 	IDToken     string `json:"id_token,omitempty"`
 }
 
-func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry) (*grantToken, error) {
+func exchangeCodeForToken(definition RunDefinition, code string, ctx *model.Context, logger *logrus.Entry) (*grantToken, error) {
 	logger = logger.WithFields(logrus.Fields{
 		"function": "exchangeCodeForToken",
-		"code":     code,
 	})
 	ctx.DumpContext()
 
@@ -266,7 +281,7 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 	var errResponse error
 	switch authMethod {
 	case authentication.ClientSecretBasic:
-		resp, errResponse = resty.R().
+		resp, errResponse = sendRequest(definition, "exchange code", http.MethodPost, tokenEndpoint, resty.R().
 			SetHeader("content-type", "application/x-www-form-urlencoded").
 			SetHeader("accept", "application/json").
 			SetHeader("authorization", "Basic "+basicAuth).
@@ -274,10 +289,9 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 				authentication.GrantType: authentication.GrantTypeAuthorizationCode,
 				"code":                   code,
 				"redirect_uri":           redirectURI,
-			}).
-			Post(tokenEndpoint)
+			}))
 	case authentication.TlsClientAuth:
-		resp, errResponse = resty.R().
+		resp, errResponse = sendRequest(definition, "exchange code", http.MethodPost, tokenEndpoint, resty.R().
 			SetHeader("content-type", "application/x-www-form-urlencoded").
 			SetHeader("accept", "application/json").
 			SetFormData(map[string]string{
@@ -285,8 +299,7 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 				"code":                   code,
 				"redirect_uri":           redirectURI,
 				"client_id":              clientID,
-			}).
-			Post(tokenEndpoint)
+			}))
 	case authentication.PrivateKeyJwt:
 		now := time.Now()
 		iat := now.Unix()
@@ -326,7 +339,7 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 			return nil, errors.Wrap(err, "executors.exchangeCodeForToken: could not generate client_assertion")
 		}
 
-		resp, errResponse = resty.R().
+		resp, errResponse = sendRequest(definition, "exchange code", http.MethodPost, tokenEndpoint, resty.R().
 			SetHeader("content-type", "application/x-www-form-urlencoded").
 			SetHeader("accept", "application/json").
 			SetFormData(map[string]string{
@@ -335,8 +348,7 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 				"redirect_uri":                     redirectURI,
 				authentication.ClientAssertionType: authentication.ClientAssertionTypeValue,
 				authentication.ClientAssertion:     clientAssertion,
-			}).
-			Post(tokenEndpoint)
+			}))
 	default:
 		return nil, errors.Errorf("executors.exchangeCodeForToken: token_endpoint_auth_method %q unsupported", authMethod)
 	}
@@ -358,9 +370,7 @@ func exchangeCodeForToken(code string, ctx *model.Context, logger *logrus.Entry)
 		return nil, err
 	}
 
-	logger.WithFields(logrus.Fields{
-		"grantToken": grantToken,
-	}).Tracef("OK")
+	logger.Tracef("OK")
 
 	return grantToken, nil
 }
@@ -15,6 +15,7 @@ type DaemonController interface {
 	AddResult(result results.TestCase)
 	AllResults() []results.TestCase
 	AllResultsGrouped() map[results.ResultKey][]results.TestCase
+	CountByStatus() map[results.Status]int
 	AddResponseFields(string)
 	ResponseFieldsJSON() string
 
@@ -104,6 +105,11 @@ func (rc *daemonController) AllResultsGrouped() map[results.ResultKey][]results.
 	return rc.resultsGrouped
 }
 
+// CountByStatus - returns the number of accumulated results of each status.
+func (rc *daemonController) CountByStatus() map[results.Status]int {
+	return results.CountByStatus(rc.resultsGrouped)
+}
+
 func (rc *daemonController) AddResponseFields(f string) {
 	rc.responseFields = f
 }
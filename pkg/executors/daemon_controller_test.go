@@ -91,6 +91,22 @@ func TestNewBufferedDaemonControllerAllResults(t *testing.T) {
 	}, controller.AllResults())
 }
 
+func TestDaemonControllerCountByStatus(t *testing.T) {
+	require := test.NewRequire(t)
+
+	controller := NewBufferedDaemonController()
+	controller.AddResult(results.NewTestCaseResult("1", true, results.NoMetrics(), nil, "endpoint", "api-name", "api-version", "", "", "200"))
+	controller.AddResult(results.NewTestCaseError("2", results.NoMetrics(), []error{errors.New("missing token")}, "endpoint", "api-name", "api-version", "", "", ""))
+	controller.AddResult(results.NewTestCaseSkipped("3", "2", "endpoint", "api-name", "api-version", "", ""))
+
+	counts := controller.CountByStatus()
+	require.Equal(1, counts[results.StatusPass])
+	require.Equal(1, counts[results.StatusError])
+	require.Equal(1, counts[results.StatusSkipped])
+	require.Equal(0, counts[results.StatusFail])
+	require.Len(counts, len(results.Statuses))
+}
+
 func TestNewBufferedDaemonControllerSetCompletedAndIsCompleted(t *testing.T) {
 	require := test.NewAssert(t)
 
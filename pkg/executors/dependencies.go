@@ -0,0 +1,35 @@
+package executors
+
+import "github.com/OpenBankingUK/conformance-suite/pkg/model"
+
+// dependencyTracker follows the context values put by the test cases of a run, so the test cases using a value
+// which was not put, because the test case putting it failed or was skipped, are skipped instead of failing on
+// unresolved replacements
+type dependencyTracker struct {
+	blocked map[string]string // Context value name to the id of the test case which did not put it
+}
+
+func newDependencyTracker() *dependencyTracker {
+	return &dependencyTracker{blocked: map[string]string{}}
+}
+
+// blockedBy returns the id of the test case which did not put a context value used by tc, if any
+func (d *dependencyTracker) blockedBy(tc model.TestCase) (string, bool) {
+	for _, name := range tc.ContextReferences() {
+		if id, blocked := d.blocked[name]; blocked {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// record notes whether the context values put by tc on success are available to the test cases run next
+func (d *dependencyTracker) record(tc model.TestCase, pass bool) {
+	for _, name := range tc.ContextPuts() {
+		if pass {
+			delete(d.blocked, name)
+		} else {
+			d.blocked[name] = tc.ID
+		}
+	}
+}
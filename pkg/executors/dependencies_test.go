@@ -0,0 +1,110 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/mocks"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func consentTestCase(url string) model.TestCase {
+	return model.TestCase{
+		ID:        "OB-301-DOP-100100",
+		Input:     model.Input{Method: http.MethodPost, Endpoint: url + "/domestic-payment-consents"},
+		Expect:    model.Expect{StatusCode: http.StatusCreated, ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "OB-301-DOP-100100-ConsentId", JSON: "Data.ConsentId"}}}},
+		Validator: schema.NewNullValidator(),
+	}
+}
+
+func paymentTestCase(url string) model.TestCase {
+	return model.TestCase{
+		ID:        "OB-301-DOP-100600",
+		Input:     model.Input{Method: http.MethodPost, Endpoint: url + "/domestic-payments", RequestBody: `{"Data":{"ConsentId":"$OB-301-DOP-100100-ConsentId"}}`},
+		Expect:    model.Expect{StatusCode: http.StatusCreated, ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "OB-301-DOP-100600-DomesticPaymentId", JSON: "Data.DomesticPaymentId"}}}},
+		Validator: schema.NewNullValidator(),
+	}
+}
+
+func getPaymentTestCase(url string) model.TestCase {
+	return model.TestCase{
+		ID:        "OB-301-DOP-100700",
+		Input:     model.Input{Method: http.MethodGet, Endpoint: url + "/domestic-payments/$OB-301-DOP-100600-DomesticPaymentId"},
+		Expect:    model.Expect{StatusCode: http.StatusOK},
+		Validator: schema.NewNullValidator(),
+	}
+}
+
+func TestDependencyTracker(t *testing.T) {
+	consent, payment, getPayment := consentTestCase(""), paymentTestCase(""), getPaymentTestCase("")
+	dependencies := newDependencyTracker()
+
+	_, blocked := dependencies.blockedBy(payment)
+	assert.False(t, blocked)
+
+	dependencies.record(consent, false)
+	blockedBy, blocked := dependencies.blockedBy(payment)
+	assert.True(t, blocked)
+	assert.Equal(t, consent.ID, blockedBy)
+
+	dependencies.record(payment, false)
+	blockedBy, _ = dependencies.blockedBy(getPayment)
+	assert.Equal(t, payment.ID, blockedBy)
+
+	dependencies.record(consent, true)
+	_, blocked = dependencies.blockedBy(payment)
+	assert.False(t, blocked)
+}
+
+func TestExecuteSpecTestsSkipsTestsBlockedByFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/domestic-payment-consents":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"Errors":[]}`)
+		default:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, `{"Data":{}}`)
+		}
+	}))
+	defer server.Close()
+
+	accounts := model.TestCase{
+		ID:        "OB-301-ACC-100000",
+		Input:     model.Input{Method: http.MethodGet, Endpoint: server.URL + "/accounts"},
+		Expect:    model.Expect{StatusCode: http.StatusOK},
+		Validator: schema.NewNullValidator(),
+	}
+	spec := generation.SpecificationTestCases{TestCases: []model.TestCase{
+		consentTestCase(server.URL), paymentTestCase(server.URL), getPaymentTestCase(server.URL), accounts,
+	}}
+
+	added := []results.TestCase{}
+	controller := &mocks.DaemonController{}
+	controller.On("ShouldStop").Return(false)
+	controller.On("AddResult", mock.Anything).Run(func(args mock.Arguments) {
+		added = append(added, args.Get(0).(results.TestCase))
+	})
+	runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{}, controller)
+	runner.executor = requestExecutor{}
+
+	runner.executeSpecTests(spec, model.NewRunContext(nil), newDependencyTracker(), test.NullLogger())
+
+	require.Len(t, added, 4)
+	assert.Equal(t, results.StatusFail, added[0].Status)
+	assert.Equal(t, results.TestCase{Id: "OB-301-DOP-100600", Status: results.StatusSkipped, Severity: results.SeverityMedium, BlockedBy: "OB-301-DOP-100100", Endpoint: server.URL + "/domestic-payments"}, added[1])
+	assert.Equal(t, results.StatusSkipped, added[2].Status)
+	assert.Equal(t, "OB-301-DOP-100600", added[2].BlockedBy)
+	assert.True(t, added[3].Pass)
+}
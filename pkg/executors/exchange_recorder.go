@@ -0,0 +1,31 @@
+package executors
+
+import (
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// exchangeRecorder records the requests sent by an executor for a test case, including those sent
+// when polling, paging and replaying, along with the responses received
+type exchangeRecorder struct {
+	executor TestCaseExecutor
+	capture  results.ExchangeCapture
+	entries  []results.HAREntry
+}
+
+// ExecuteTestCase executes the test case and records the exchange, unless the endpoint is not called
+func (e *exchangeRecorder) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, results.Metrics, error) {
+	resp, metrics, err := e.executor.ExecuteTestCase(r, t, ctx)
+	if !t.DoNotCallEndpoint {
+		e.entries = append(e.entries, results.NewHAREntry(t.ID, r, resp, err, e.capture))
+	}
+	return resp, metrics, err
+}
+
+// SetCertificates sets the certificates of the executor recorded
+func (e *exchangeRecorder) SetCertificates(certificateSigning, certificationTransport authentication.Certificate) error {
+	return e.executor.SetCertificates(certificateSigning, certificationTransport)
+}
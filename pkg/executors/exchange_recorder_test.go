@@ -0,0 +1,63 @@
+package executors
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func pollTestCase(url string) model.TestCase {
+	return model.TestCase{
+		ID: "OB-301-DOP-100700",
+		Input: model.Input{
+			Method:          http.MethodGet,
+			Endpoint:        url,
+			QueryParameters: map[string]string{"page": "1"},
+			Headers:         map[string]string{"Authorization": "Bearer 2f6c5b1e"},
+		},
+		Expect: model.Expect{
+			StatusCode: http.StatusOK,
+			Poll:       &model.Poll{JSON: "Data.Status", TerminalStates: []string{"AcceptedSettlementCompleted"}, IntervalMs: 1, TimeoutMs: 1000},
+		},
+		Validator: schema.NewNullValidator(),
+	}
+}
+
+func TestExecuteTestRecordsExchanges(t *testing.T) {
+	server, calls := statusServer(t, "Pending", "AcceptedSettlementCompleted")
+	defer server.Close()
+	runner := requestTestRunner()
+	runner.definition.ExchangeCapture = results.ExchangeCapture{Enabled: true}
+
+	result := runner.executeTest(pollTestCase(server.URL), &model.Context{}, test.NullLogger())
+
+	require.True(t, result.Pass, result.Fail)
+	require.Len(t, result.Exchanges, *calls)
+	for _, entry := range result.Exchanges {
+		assert.Equal(t, "OB-301-DOP-100700", entry.TestCaseID)
+		assert.Equal(t, http.MethodGet, entry.Request.Method)
+		assert.Contains(t, entry.Request.Headers, results.HARNameValue{Name: "Authorization", Value: redact.Value})
+		assert.Equal(t, http.StatusOK, entry.Response.Status)
+	}
+	assert.JSONEq(t, `{"Data":{"Status":"Pending"}}`, result.Exchanges[0].Response.Content.Text)
+	assert.JSONEq(t, `{"Data":{"Status":"AcceptedSettlementCompleted"}}`, result.Exchanges[1].Response.Content.Text)
+	assert.IsType(t, requestExecutor{}, runner.executor, "the executor of the runner is not replaced")
+}
+
+func TestExecuteTestDoesNotRecordExchangesByDefault(t *testing.T) {
+	server, _ := statusServer(t, "AcceptedSettlementCompleted")
+	defer server.Close()
+
+	result := requestTestRunner().executeTest(pollTestCase(server.URL), &model.Context{}, test.NullLogger())
+
+	require.True(t, result.Pass, result.Fail)
+	assert.Empty(t, result.Exchanges)
+}
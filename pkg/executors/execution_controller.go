@@ -28,6 +28,16 @@ type RunDefinition struct {
 	SpecRun       generation.SpecRun
 	SigningCert   authentication.Certificate
 	TransportCert authentication.Certificate
+	// ExchangeCapture configures the recording of the requests and responses of each test case
+	ExchangeCapture results.ExchangeCapture
+	// Cassette records the run, or replays a run recorded, nil when runs are neither recorded nor replayed
+	Cassette *Cassette
+	// RetryPolicy configures the attempts made to send the requests of the tests
+	RetryPolicy RetryPolicy
+	// Client sends the requests of the run, made by NewClient with the transport certificate
+	Client *resty.Client
+	// RateLimiter paces the requests sent to each host, shared by the runners of a journey, nil when not limited
+	RateLimiter *RateLimiter
 }
 
 type TestCaseRunner struct {
@@ -42,7 +52,7 @@ type TestCaseRunner struct {
 // NewTestCaseRunner -
 func NewTestCaseRunner(logger *logrus.Entry, definition RunDefinition, daemonController DaemonController) *TestCaseRunner {
 	return &TestCaseRunner{
-		executor:         NewExecutor(),
+		executor:         newRunExecutor(definition),
 		definition:       definition,
 		daemonController: daemonController,
 		logger:           logger.WithField("module", "TestCaseRunner"),
@@ -54,7 +64,7 @@ func NewTestCaseRunner(logger *logrus.Entry, definition RunDefinition, daemonCon
 // NewConsentAcquisitionRunner -
 func NewConsentAcquisitionRunner(logger *logrus.Entry, definition RunDefinition, daemonController DaemonController) *TestCaseRunner {
 	return &TestCaseRunner{
-		executor:         NewExecutor(),
+		executor:         newRunExecutor(definition),
 		definition:       definition,
 		daemonController: daemonController,
 		logger:           logger.WithField("module", "ConsentAcquisitionRunner"),
@@ -66,7 +76,7 @@ func NewConsentAcquisitionRunner(logger *logrus.Entry, definition RunDefinition,
 // NewExchangeComponentRunner -
 func NewExchangeComponentRunner(definition RunDefinition, daemonController DaemonController) *TestCaseRunner {
 	return &TestCaseRunner{
-		executor:         NewExecutor(),
+		executor:         newRunExecutor(definition),
 		definition:       definition,
 		daemonController: daemonController,
 		logger:           logrus.StandardLogger().WithField("module", "ExchangeComponent"),
@@ -75,8 +85,32 @@ func NewExchangeComponentRunner(definition RunDefinition, daemonController Daemo
 	}
 }
 
+// newRunExecutor returns the executor of a run: paced by the rate limiter of the definition, if any, retrying as
+// its policy, recording or replaying its cassette, if any
+func newRunExecutor(definition RunDefinition) TestCaseExecutor {
+	executor := NewExecutor(definition.Client)
+	if definition.RateLimiter != nil {
+		executor = definition.RateLimiter.Executor(executor)
+	}
+	executor = newRetryingExecutor(executor, definition.RetryPolicy)
+	if definition.Cassette == nil {
+		return executor
+	}
+	return definition.Cassette.Executor(executor)
+}
+
+// sendRequest sends a request of the token and consent acquisition, which is not a test case, with the executor of
+// the run so it is paced, retried and recorded like the requests of the test cases. name identifies the request in
+// the cassette.
+func sendRequest(definition RunDefinition, name, method, url string, r *resty.Request) (*resty.Response, error) {
+	r.Method, r.URL = method, url
+	tc := model.TestCase{ID: name, Name: name}
+	resp, _, err := newRunExecutor(definition).ExecuteTestCase(r, &tc, &model.Context{})
+	return resp, err
+}
+
 // RunTestCases runs the testCases
-func (r *TestCaseRunner) RunTestCases(ctx *model.Context) error {
+func (r *TestCaseRunner) RunTestCases(ctx *model.RunContext) error {
 	r.runningLock.Lock()
 	defer r.runningLock.Unlock()
 	if r.running {
@@ -84,13 +118,14 @@ func (r *TestCaseRunner) RunTestCases(ctx *model.Context) error {
 	}
 	r.running = true
 
+	ctx.Clear(model.ScopeRun) // values kept by the tests of a previous run are not seen by this one
 	go r.runTestCasesAsync(ctx)
 
 	return nil
 }
 
 // RunConsentAcquisition -
-func (r *TestCaseRunner) RunConsentAcquisition(item TokenConsentIDItem, ctx *model.Context, consentType string, consentIDChannel chan<- TokenConsentIDItem) error {
+func (r *TestCaseRunner) RunConsentAcquisition(item TokenConsentIDItem, ctx *model.RunContext, consentType string, consentIDChannel chan<- TokenConsentIDItem) error {
 	r.runningLock.Lock()
 	defer r.runningLock.Unlock()
 	if r.running {
@@ -103,34 +138,38 @@ func (r *TestCaseRunner) RunConsentAcquisition(item TokenConsentIDItem, ctx *mod
 	return nil
 }
 
-func (r *TestCaseRunner) runTestCasesAsync(ctx *model.Context) {
+func (r *TestCaseRunner) runTestCasesAsync(ctx *model.RunContext) {
 	err := r.executor.SetCertificates(r.definition.SigningCert, r.definition.TransportCert)
 	if err != nil {
 		r.logger.WithError(err).Error("running test cases async")
 	}
 
-	ruleCtx := r.makeRuleCtx(ctx)
-
 	ctxLogger := r.logger.WithField("id", uuid.New())
+	dependencies := newDependencyTracker() // context values are shared by the specs
 	for _, spec := range r.definition.SpecRun.SpecTestCases {
-		r.executeSpecTests(spec, ruleCtx, ctxLogger) // Run Tests for each spec
+		ctx.Clear(model.ScopeSpec)
+		ctx.Put(model.ScopeSpec, "apiName", spec.Specification.Name)
+		ctx.Put(model.ScopeSpec, "apiVersion", spec.Specification.Version)
+		r.executeSpecTests(spec, ctx, dependencies, ctxLogger) // Run Tests for each spec
 	}
 
 	collector := schemaprops.GetPropertyCollector()
 	r.daemonController.AddResponseFields(collector.OutputJSON())
 
+	r.saveCassette()
 	r.daemonController.SetCompleted()
 
 	r.setNotRunning()
 }
 
-func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx *model.Context, consentType string, consentIDChannel chan<- TokenConsentIDItem) {
+func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx *model.RunContext, consentType string, consentIDChannel chan<- TokenConsentIDItem) {
 	err := r.executor.SetCertificates(r.definition.SigningCert, r.definition.TransportCert)
 	if err != nil {
 		r.logger.WithError(err).Error("running consent acquisition async")
 	}
 
-	ruleCtx := r.makeRuleCtx(ctx)
+	snapshot := ctx.Snapshot() // consents are acquired concurrently, each with its own copy
+	ruleCtx := &snapshot
 	ruleCtx.PutString("consent_id", item.TokenName)
 	ruleCtx.PutString("token_name", item.TokenName)
 	ruleCtx.PutString("permission_list", item.Permissions)
@@ -187,15 +226,24 @@ func (r *TestCaseRunner) runConsentAcquisitionAsync(item TokenConsentIDItem, ctx
 	r.executeComponentTests(&comp, ruleCtx, ctxLogger, item, consentIDChannel, authMethod)
 	clientGrantToken, err := ruleCtx.GetString("client_access_token")
 	if err == nil {
-		logrus.StandardLogger().WithFields(logrus.Fields{
-			"clientGrantToken": clientGrantToken,
-		}).Debugf("Setting client_access_token")
-		ctx.PutString("client_access_token", clientGrantToken)
+		logrus.StandardLogger().Debugf("Setting client_access_token")
+		ctx.Put(model.ScopeJourney, "client_access_token", clientGrantToken)
 	}
 
+	r.saveCassette()
 	r.setNotRunning()
 }
 
+// saveCassette writes the cassette when the run is recorded
+func (r *TestCaseRunner) saveCassette() {
+	if !r.definition.Cassette.Recording() {
+		return
+	}
+	if err := r.definition.Cassette.Save(); err != nil {
+		r.logger.WithError(err).Error("saving cassette")
+	}
+}
+
 func (r *TestCaseRunner) executeComponentTests(comp *model.Component, ruleCtx *model.Context, logger *logrus.Entry, item TokenConsentIDItem, consentIDChannel chan<- TokenConsentIDItem, authMethod string) {
 	ctxLogger := logger.WithFields(logrus.Fields{
 		"component": comp.Name,
@@ -326,13 +374,7 @@ func (r *TestCaseRunner) setNotRunning() {
 	r.running = false
 }
 
-func (r *TestCaseRunner) makeRuleCtx(ctx *model.Context) *model.Context {
-	ruleCtx := &model.Context{}
-	ruleCtx.PutContext(ctx)
-	return ruleCtx
-}
-
-func (r *TestCaseRunner) executeSpecTests(spec generation.SpecificationTestCases, ruleCtx *model.Context, ctxLogger *logrus.Entry) {
+func (r *TestCaseRunner) executeSpecTests(spec generation.SpecificationTestCases, ctx *model.RunContext, dependencies *dependencyTracker, ctxLogger *logrus.Entry) {
 	ctxLogger = ctxLogger.WithField("spec", spec.Specification.Name)
 	collector := schemaprops.GetPropertyCollector()
 	collector.SetCollectorAPIDetails(spec.Specification.Name, spec.Specification.Version)
@@ -343,18 +385,50 @@ func (r *TestCaseRunner) executeSpecTests(spec generation.SpecificationTestCases
 			return
 		}
 		ctxLogger = ctxLogger.WithField("ID", testcase.ID)
-		ruleCtx.DumpContext("ruleCtx before: " + testcase.ID)
-		testResult := r.executeTest(testcase, ruleCtx, ctxLogger)
+		if blockedBy, blocked := dependencies.blockedBy(testcase); blocked {
+			ctxLogger.WithField("blockedBy", blockedBy).Info("test skipped, a context value it uses was not put")
+			dependencies.record(testcase, false)
+			r.daemonController.AddResult(results.NewTestCaseSkipped(
+				testcase.ID,
+				blockedBy,
+				testcase.Input.Endpoint,
+				testcase.APIName,
+				testcase.APIVersion,
+				testcase.Detail,
+				testcase.RefURI,
+			))
+			continue
+		}
+		testCtx := ctx.NewTestContext(testcase.Context)
+		testCtx.Values.DumpContext("ruleCtx before: " + testcase.ID)
+		testResult := r.executeTest(testcase, &testCtx.Values, ctxLogger)
+		testResult.Resource = testcase.Resource
+		testResult.ContextRead, testResult.ContextWritten = ctx.Commit(testCtx, &testcase)
+		dependencies.record(testcase, testResult.Pass)
 		r.daemonController.AddResult(testResult)
 	}
 }
 
+// executeTest runs a test case, recording the requests it sends and the responses it receives in its result
+// when exchanges are captured
 func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context, logger *logrus.Entry) results.TestCase {
+	if !r.definition.ExchangeCapture.Enabled {
+		return r.runTest(tc, ruleCtx, logger)
+	}
+	recorder := &exchangeRecorder{executor: r.executor, capture: r.definition.ExchangeCapture}
+	runner := *r
+	runner.executor = recorder
+	testResult := runner.runTest(tc, ruleCtx, logger)
+	testResult.Exchanges = recorder.entries
+	return testResult
+}
+
+func (r *TestCaseRunner) runTest(tc model.TestCase, ruleCtx *model.Context, logger *logrus.Entry) results.TestCase {
 	ctxLogger := logWithTestCase(logger, tc)
 	req, err := tc.Prepare(ruleCtx)
 	if err != nil {
 		ctxLogger.WithError(err).Error("preparing executing test")
-		return results.NewTestCaseFail(
+		return results.NewTestCaseError(
 			tc.ID,
 			results.NoMetrics(),
 			detailedErrors([]error{err}, nil),
@@ -366,14 +440,22 @@ func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context,
 			tc.StatusCode,
 		)
 	}
+	if tc.DoNotCallEndpoint {
+		ctxLogger.WithFields(logrus.Fields{"result": "NOT APPLICABLE", "ID": tc.ID}).Info("test result endpoint not called")
+		return results.NewTestCaseNotApplicable(tc.ID, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI)
+	}
 	resp, metrics, err := r.executor.ExecuteTestCase(req, &tc, ruleCtx)
 	ctxLogger = logWithMetrics(ctxLogger, metrics)
 	if err != nil {
-		ctxLogger.WithError(err).WithFields(logrus.Fields{"result": "FAIL", "ID": tc.ID}).Error("test result")
-		return results.NewTestCaseFail(
+		ctxLogger.WithError(err).WithFields(logrus.Fields{"result": "ERROR", "ID": tc.ID}).Error("test result")
+		var errResp *resty.Response
+		if IsInfrastructureError(err) && resp != nil && resp.RawResponse != nil {
+			errResp = resp
+		}
+		testResult := results.NewTestCaseError(
 			tc.ID,
 			metrics,
-			detailedErrors([]error{err}, nil),
+			detailedErrors([]error{err}, errResp),
 			tc.Input.Endpoint,
 			tc.APIName,
 			tc.APIVersion,
@@ -381,13 +463,40 @@ func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context,
 			tc.RefURI,
 			tc.StatusCode,
 		)
+		testResult.Infrastructure = IsInfrastructureError(err)
+		return testResult
 	}
+
+	var statusTransitions []string
+	if tc.Expect.Poll != nil {
+		resp, metrics, statusTransitions, err = r.pollUntilTerminal(req, &tc, ruleCtx, resp, metrics)
+		if err != nil {
+			ctxLogger.WithError(err).WithFields(logrus.Fields{"result": "FAIL", "ID": tc.ID, "states": statusTransitions}).Error("test result poll")
+			testResult := results.NewTestCaseFail(
+				tc.ID,
+				metrics,
+				detailedErrors([]error{err}, resp),
+				tc.Input.Endpoint,
+				tc.APIName,
+				tc.APIVersion,
+				tc.Detail,
+				tc.RefURI,
+				tc.StatusCode,
+			)
+			if IsInfrastructureError(err) {
+				testResult.Status, testResult.Infrastructure = results.StatusError, true
+			}
+			testResult.StatusTransitions = statusTransitions
+			return testResult
+		}
+	}
+
 	tc.StatusCode = resp.Status()
 	result, errs := tc.Validate(resp, ruleCtx)
 	if errs != nil {
 		detailedErrors := detailedErrors(errs, resp)
 		ctxLogger.WithField("errs", detailedErrors).WithFields(logrus.Fields{"result": passText()[result], "ID": tc.ID}).Error("test result validate")
-		return results.NewTestCaseFail(
+		testResult := results.NewTestCaseFail(
 			tc.ID,
 			metrics,
 			detailedErrors,
@@ -398,6 +507,53 @@ func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context,
 			tc.RefURI,
 			tc.StatusCode,
 		)
+		testResult.StatusTransitions = statusTransitions
+		return testResult
+	}
+
+	var pages int
+	if result && tc.Paging != nil {
+		var pageResp *resty.Response
+		pageResp, pages, errs = r.traversePages(req, &tc, ruleCtx, resp, ctxLogger)
+		if len(errs) > 0 {
+			detailedErrors := detailedErrors(errs, pageResp)
+			ctxLogger.WithField("errs", detailedErrors).WithFields(logrus.Fields{"result": "FAIL", "ID": tc.ID, "pages": pages}).Error("test result paging")
+			testResult := results.NewTestCaseFail(
+				tc.ID,
+				metrics,
+				detailedErrors,
+				tc.Input.Endpoint,
+				tc.APIName,
+				tc.APIVersion,
+				tc.Detail,
+				tc.RefURI,
+				tc.StatusCode,
+			)
+			testResult.Pages = pages
+			return testResult
+		}
+	}
+
+	if result && tc.Replay != nil {
+		replayResp, errs := r.replayTest(req, &tc, ruleCtx, resp)
+		if errs != nil {
+			detailedErrors := detailedErrors(errs, replayResp)
+			ctxLogger.WithField("errs", detailedErrors).WithFields(logrus.Fields{"result": "FAIL", "ID": tc.ID}).Error("test result replay")
+			testResult := results.NewTestCaseFail(
+				tc.ID,
+				metrics,
+				detailedErrors,
+				tc.Input.Endpoint,
+				tc.APIName,
+				tc.APIVersion,
+				tc.Detail,
+				tc.RefURI,
+				tc.StatusCode,
+			)
+			testResult.StatusTransitions = statusTransitions
+			testResult.Pages = pages
+			return testResult
+		}
 	}
 
 	if !result {
@@ -406,7 +562,17 @@ func (r *TestCaseRunner) executeTest(tc model.TestCase, ruleCtx *model.Context,
 		ctxLogger.WithError(err).WithFields(logrus.Fields{"result": passText()[result], "ID": tc.ID}).Info("test result")
 	}
 
-	return results.NewTestCaseResult(tc.ID, result, metrics, []error{}, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+	testResult := results.NewTestCaseResult(tc.ID, result, metrics, []error{}, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+	if result {
+		if warnings := tc.ValidateAdvisory(resp, ruleCtx); len(warnings) > 0 {
+			detailedWarnings := detailedErrors(warnings, resp)
+			ctxLogger.WithField("warnings", detailedWarnings).WithFields(logrus.Fields{"result": "WARNING", "ID": tc.ID}).Warn("test result advisory")
+			testResult = results.NewTestCaseWarning(tc.ID, metrics, detailedWarnings, tc.Input.Endpoint, tc.APIName, tc.APIVersion, tc.Detail, tc.RefURI, tc.StatusCode)
+		}
+	}
+	testResult.StatusTransitions = statusTransitions
+	testResult.Pages = pages
+	return testResult
 }
 
 type DetailError struct {
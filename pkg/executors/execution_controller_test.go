@@ -1,12 +1,22 @@
 package executors
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTestCaseRunner(t *testing.T) {
@@ -19,3 +29,114 @@ func TestNewTestCaseRunner(t *testing.T) {
 	assert.Equal(t, controller, runner.daemonController)
 	assert.False(t, runner.running)
 }
+
+func TestExecuteTestAdvisoryAssertsAreWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"Data":{}}`)
+	}))
+	defer server.Close()
+
+	tc := model.TestCase{
+		ID:             "OB-301-ACC-100000",
+		Input:          model.Input{Method: http.MethodGet, Endpoint: server.URL + "/accounts"},
+		Expect:         model.Expect{StatusCode: http.StatusOK},
+		ExpectAdvisory: []model.Expect{{Matches: []model.Match{{HeaderPresent: "x-fapi-interaction-id"}}}},
+		Validator:      schema.NewNullValidator(),
+	}
+
+	result := requestTestRunner().executeTest(tc, &model.Context{}, test.NullLogger())
+	assert.True(t, result.Pass)
+	assert.Equal(t, results.StatusWarning, result.Status)
+	assert.Equal(t, results.SeverityLow, result.Severity)
+	assert.Empty(t, result.Fail)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "x-fapi-interaction-id")
+
+	tc.Expect.StatusCode = http.StatusCreated
+	result = requestTestRunner().executeTest(tc, &model.Context{}, test.NullLogger())
+	assert.Equal(t, results.StatusFail, result.Status)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestExecuteTestEndpointNotCalledIsNotApplicable(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	tc := model.TestCase{
+		ID:                "OB-301-ACC-100000",
+		Input:             model.Input{Method: http.MethodGet, Endpoint: server.URL + "/accounts"},
+		Expect:            model.Expect{StatusCode: http.StatusOK},
+		DoNotCallEndpoint: true,
+		Validator:         schema.NewNullValidator(),
+	}
+
+	result := requestTestRunner().executeTest(tc, &model.Context{}, test.NullLogger())
+	assert.False(t, called)
+	assert.True(t, result.Pass)
+	assert.Equal(t, results.StatusNotApplicable, result.Status)
+	assert.Equal(t, results.SeverityInfo, result.Severity)
+	assert.Equal(t, server.URL+"/accounts", result.Endpoint)
+}
+
+func TestExecuteSpecTestsRecordsContextChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"Data":{"ConsentId":"sdp-1-b5bbdb18","DomesticPaymentId":"pv3-1"}}`)
+	}))
+	defer server.Close()
+
+	consent := consentTestCase(server.URL)
+	consent.Bearer = "$payment_ccg_token"
+	consent.Context = model.Context{"postData": "{}"}
+	spec := generation.SpecificationTestCases{
+		Specification: discovery.ModelAPISpecification{Name: "Payment Initiation API Specification", Version: "v3.1.6"},
+		TestCases:     []model.TestCase{consent, paymentTestCase(server.URL)},
+	}
+
+	added := []results.TestCase{}
+	controller := &mocks.DaemonController{}
+	controller.On("ShouldStop").Return(false)
+	controller.On("AddResult", mock.Anything).Run(func(args mock.Arguments) {
+		added = append(added, args.Get(0).(results.TestCase))
+	})
+	runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{}, controller)
+	runner.executor = requestExecutor{}
+	ctx := model.NewRunContext(model.Context{"phase": "run"})
+	ctx.Put(model.ScopeJourney, "payment_ccg_token", "ccg-token")
+
+	runner.executeSpecTests(spec, ctx, newDependencyTracker(), test.NullLogger())
+
+	require.Len(t, added, 2)
+	assert.Equal(t, []model.ContextChange{{Key: "payment_ccg_token", Scope: "journey", Value: "[redacted]"}}, added[0].ContextRead)
+	assert.Equal(t, []model.ContextChange{{Key: "OB-301-DOP-100100-ConsentId", Scope: "run", Value: "sdp-1-b5bbdb18"}}, added[0].ContextWritten)
+	assert.Equal(t, []model.ContextChange{{Key: "OB-301-DOP-100100-ConsentId", Scope: "run", Value: "sdp-1-b5bbdb18"}}, added[1].ContextRead)
+	assert.Equal(t, []model.ContextChange{{Key: "OB-301-DOP-100600-DomesticPaymentId", Scope: "run", Value: "pv3-1"}}, added[1].ContextWritten)
+
+	_, exists := ctx.Get("postData")
+	assert.False(t, exists, "the local context of a test is not kept after it")
+}
+
+func TestRunTestCasesClearsTheRunScope(t *testing.T) {
+	completed := make(chan struct{})
+	controller := &mocks.DaemonController{}
+	controller.On("AddResponseFields", mock.Anything)
+	controller.On("SetCompleted").Run(func(mock.Arguments) { close(completed) })
+	runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{}, controller)
+	runner.executor = requestExecutor{}
+	ctx := model.NewRunContext(model.Context{"phase": "run"})
+	ctx.Put(model.ScopeJourney, "Token001", "token-1")
+	ctx.Put(model.ScopeRun, "OB-301-DOP-100600-DomesticPaymentId", "pv3-1")
+
+	require.NoError(t, runner.RunTestCases(ctx))
+	<-completed
+
+	_, exists := ctx.Get("OB-301-DOP-100600-DomesticPaymentId")
+	assert.False(t, exists, "values kept by the tests of a previous run are cleared")
+	_, exists = ctx.Get("Token001")
+	assert.True(t, exists, "tokens are kept across runs")
+}
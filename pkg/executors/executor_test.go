@@ -6,7 +6,6 @@ import (
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 
 	"github.com/stretchr/testify/require"
-	resty "gopkg.in/resty.v1"
 )
 
 const (
@@ -140,9 +139,6 @@ func disableTestExecutor_SetCertificates(t *testing.T) {
 	t.Run("InvalidTransportCertificate", func(t *testing.T) {
 		require := require.New(t)
 
-		executor := NewExecutor()
-		require.NotNil(executor)
-
 		certificateSigning, err := authentication.NewCertificate(signingPublic, signingPrivate)
 		require.NotNil(certificateSigning)
 		require.NoError(err)
@@ -151,10 +147,14 @@ func disableTestExecutor_SetCertificates(t *testing.T) {
 		require.NotNil(certificateTransport)
 		require.NoError(err)
 
+		client, err := NewClient(ClientConfig{}, certificateTransport)
+		require.NoError(err)
+		executor := NewExecutor(client)
+		require.NotNil(executor)
 		require.NoError(executor.SetCertificates(certificateSigning, certificateTransport))
 
 		// https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp
-		res, err := resty.R().Get("https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp")
+		res, err := client.R().Get("https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp")
 		require.NotNil(res)
 		require.NoError(err)
 
@@ -167,9 +167,6 @@ func disableTestExecutor_SetCertificates(t *testing.T) {
 	t.Run("ValidTransportCertificate", func(t *testing.T) {
 		require := require.New(t)
 
-		executor := NewExecutor()
-		require.NotNil(executor)
-
 		certificateSigning, err := authentication.NewCertificate(signingPublic, signingPrivate)
 		require.NotNil(certificateSigning)
 		require.NoError(err)
@@ -178,10 +175,14 @@ func disableTestExecutor_SetCertificates(t *testing.T) {
 		require.NotNil(certificateTransport)
 		require.NoError(err)
 
+		client, err := NewClient(ClientConfig{}, certificateTransport)
+		require.NoError(err)
+		executor := NewExecutor(client)
+		require.NotNil(executor)
 		require.NoError(executor.SetCertificates(certificateSigning, certificateTransport))
 
 		// https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp
-		res, err := resty.R().Get("https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp")
+		res, err := client.R().Get("https://ob19-rs1.o3bank.co.uk:4501/open-banking/v3.1/aisp")
 		require.NotNil(res)
 		require.NoError(err)
 
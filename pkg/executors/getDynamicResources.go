@@ -1,6 +1,7 @@
 package executors
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
@@ -12,15 +13,15 @@ import (
 	resty "gopkg.in/resty.v1"
 )
 
-// GetDynamicResourceIds retrieves the accounts and statements resource ids for the current token
-func GetDynamicResourceIds(tokenName, token string, ctx *model.Context, requiredTokens []manifest.RequiredTokens) error {
+// GetDynamicResourceIds retrieves the accounts and statements resource ids for the current token, with the
+// executor of the run
+func GetDynamicResourceIds(definition RunDefinition, tokenName, token string, ctx *model.Context, requiredTokens []manifest.RequiredTokens) error {
 	logger := logrus.WithFields(logrus.Fields{
 		"module":    "GetDynamicResourceIds",
 		"tokenName": tokenName,
-		"token":     token,
 	})
 
-	err := getDynamicResourceIds(tokenName, token, ctx, logger, requiredTokens)
+	err := getDynamicResourceIds(definition, tokenName, token, ctx, logger, requiredTokens)
 	if err != nil {
 		logger.WithFields(logrus.Fields{
 			"err": err,
@@ -30,7 +31,7 @@ func GetDynamicResourceIds(tokenName, token string, ctx *model.Context, required
 	return nil
 }
 
-func getDynamicResourceIds(tokenName, token string, ctx *model.Context, logger *logrus.Entry, requiredTokens []manifest.RequiredTokens) error {
+func getDynamicResourceIds(definition RunDefinition, tokenName, token string, ctx *model.Context, logger *logrus.Entry, requiredTokens []manifest.RequiredTokens) error {
 
 	if !strings.HasPrefix(tokenName, "account") {
 		return nil
@@ -53,12 +54,11 @@ func getDynamicResourceIds(tokenName, token string, ctx *model.Context, logger *
 
 	accountsEndpoint := resourceBaseURL + "/open-banking/" + apiVersion + "/aisp/accounts"
 	var resp *resty.Response
-	resp, err = resty.R().
+	resp, err = sendRequest(definition, "GetDynamicResourceIdsAccounts", http.MethodGet, accountsEndpoint, resty.R().
 		SetHeader("Authorization", "Bearer "+token).
 		SetHeader("X-Fapi-Financial-Id", xFapiFinancialID).
 		SetHeader("X-Fapi-Interaction-Id", interactionId).
-		SetHeader("X-Fcs-Testcase-Id", "GetDynamicResourceIdsAccounts").
-		Get(accountsEndpoint)
+		SetHeader("X-Fcs-Testcase-Id", "GetDynamicResourceIdsAccounts"))
 
 	if err != nil {
 		logger.Errorln("error calling /accounts for account number dynamic resource", err)
@@ -1,10 +1,17 @@
 package executors
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 )
 
 func TestGetAccountFromBody(t *testing.T) {
@@ -43,3 +50,24 @@ var (
 	}
  }`)
 )
+
+func TestGetDynamicResourceIdsIsRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Data":{"Account":[{"AccountId":"500000000000000000000001"}]}}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(ClientConfig{}, nil)
+	require.NoError(t, err)
+	limiter, waits := fakeClockLimiter(t, RateLimit{RequestsPerSecond: 1, Burst: 1})
+	definition := RunDefinition{Client: client, RateLimiter: limiter}
+	ctx := &model.Context{"resource_server": server.URL, "api-version": "v3.1", "x-fapi-financial-id": "0015800001041RHAAY"}
+	requiredTokens := []manifest.RequiredTokens{{Name: "account-token-1"}}
+
+	require.NoError(t, GetDynamicResourceIds(definition, "account-token-1", "token-1", ctx, requiredTokens))
+	require.NoError(t, GetDynamicResourceIds(definition, "account-token-1", "token-1", ctx, requiredTokens))
+
+	assert.Equal(t, "500000000000000000000001", requiredTokens[0].AccountID)
+	assert.Equal(t, []time.Duration{time.Second}, *waits, "the requests of the token acquisition are paced like the tests")
+}
@@ -57,6 +57,22 @@ func (_m *DaemonController) AllResultsGrouped() map[results.ResultKey][]results.
 	return r0
 }
 
+// CountByStatus provides a mock function with given fields:
+func (_m *DaemonController) CountByStatus() map[results.Status]int {
+	ret := _m.Called()
+
+	var r0 map[results.Status]int
+	if rf, ok := ret.Get(0).(func() map[results.Status]int); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[results.Status]int)
+		}
+	}
+
+	return r0
+}
+
 // IsCompleted provides a mock function with given fields:
 func (_m *DaemonController) IsCompleted() <-chan bool {
 	ret := _m.Called()
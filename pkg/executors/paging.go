@@ -0,0 +1,42 @@
+package executors
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// traversePages follows the Links.Next of a testcase with `paging` until the last page, or the maximum
+// number of pages, is reached. Every page received is checked and the number of pages checked is returned
+// along with the last response and any errors found.
+func (r *TestCaseRunner) traversePages(req *resty.Request, tc *model.TestCase, ctx *model.Context, resp *resty.Response, logger *logrus.Entry) (*resty.Response, int, []error) {
+	traversal, err := tc.Paging.NewTraversal(tc, req.URL)
+	if err != nil {
+		return resp, 0, []error{err}
+	}
+
+	next, errs := traversal.Check(resp)
+	for len(errs) == 0 && next != "" {
+		if traversal.Pages() >= tc.Paging.Limit() {
+			logger.WithFields(logrus.Fields{"ID": tc.ID, "pages": traversal.Pages()}).Info("paging: maximum number of pages reached")
+			return resp, traversal.Pages(), nil
+		}
+		if r.daemonController.ShouldStop() {
+			return resp, traversal.Pages(), []error{errors.New("paging: test run stopped while paging")}
+		}
+
+		pageReq := reissueRequest(req)
+		pageReq.URL = next
+		resp, _, err = r.executor.ExecuteTestCase(pageReq, tc, ctx)
+		if err != nil {
+			return resp, traversal.Pages(), []error{errors.Wrapf(err, "paging: requesting %s", next)}
+		}
+		next, errs = traversal.Check(resp)
+	}
+	if len(errs) > 0 {
+		return resp, traversal.Pages(), errs
+	}
+	return resp, traversal.Pages(), traversal.Finish(true)
+}
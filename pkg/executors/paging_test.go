@@ -0,0 +1,68 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+// pagedServer serves `total` pages of transactions, one transaction per page
+func pagedServer(total int) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		link := func(p int) string { return fmt.Sprintf("%s/transactions?page=%d", server.URL, p) }
+		links := fmt.Sprintf(`"Self":"%s","First":"%s","Last":"%s"`, link(page), link(1), link(total))
+		if page < total {
+			links += fmt.Sprintf(`,"Next":"%s"`, link(page+1))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"Data":{"Transaction":[{"TransactionId":"t%d"}]},"Links":{%s},"Meta":{"TotalPages":%d}}`, page, links, total)
+	}))
+	return server
+}
+
+func executePaging(t *testing.T, url string, paging *model.Paging) (int, []error) {
+	runner := requestTestRunner()
+	tc := &model.TestCase{Paging: paging}
+	ctx := &model.Context{}
+	req := resty.R()
+	req.Method = http.MethodGet
+	req.URL = url + "/transactions"
+
+	resp, _, err := runner.executor.ExecuteTestCase(req, tc, ctx)
+	require.NoError(t, err)
+
+	_, pages, errs := runner.traversePages(req, tc, ctx, resp, test.NullLogger())
+	return pages, errs
+}
+
+func TestTraversePagesFollowsNextUntilLastPage(t *testing.T) {
+	server := pagedServer(3)
+	defer server.Close()
+
+	pages, errs := executePaging(t, server.URL, &model.Paging{UniqueJSON: "Data.Transaction.#.TransactionId"})
+	assert.Empty(t, errs)
+	assert.Equal(t, 3, pages)
+}
+
+func TestTraversePagesStopsAtMaxPages(t *testing.T) {
+	server := pagedServer(5)
+	defer server.Close()
+
+	pages, errs := executePaging(t, server.URL, &model.Paging{MaxPages: 2})
+	assert.Empty(t, errs)
+	assert.Equal(t, 2, pages)
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func getPaymentConsents(definition RunDefinition, requiredTokens []manifest.RequiredTokens, ctx *model.Context) (TokenConsentIDs, error) {
-	executor := &Executor{}
+	executor := newRunExecutor(definition)
 	err := executor.SetCertificates(definition.SigningCert, definition.TransportCert)
 	if err != nil {
 		logrus.Error("error running payment consent acquisition async: " + err.Error())
@@ -37,7 +37,7 @@ func getPaymentConsents(definition RunDefinition, requiredTokens []manifest.Requ
 	return consentItems, err
 }
 
-func runPaymentConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor *Executor) ([]manifest.RequiredTokens, error) {
+func runPaymentConsents(rt []manifest.RequiredTokens, ctx *model.Context, executor TestCaseExecutor) ([]manifest.RequiredTokens, error) {
 	localCtx := model.Context{}
 	localCtx.PutContext(ctx)
 	localCtx.PutString("scope", "payments")
@@ -162,7 +162,7 @@ func runPaymentConsents(rt []manifest.RequiredTokens, ctx *model.Context, execut
 	return rt, nil
 }
 
-func executePaymentTest(tc *model.TestCase, ctx *model.Context, executor *Executor) error {
+func executePaymentTest(tc *model.TestCase, ctx *model.Context, executor TestCaseExecutor) error {
 	req, err := tc.Prepare(ctx)
 	if err != nil {
 		logrus.Errorf("preparing to execute test %s: %s", tc.ID, err.Error())
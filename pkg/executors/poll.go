@@ -0,0 +1,79 @@
+package executors
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// pollUntilTerminal re-issues the request of a testcase with a `poll` expectation until the polled
+// value reaches one of the terminal states, a failure state being an error. It returns the last response received along with its metrics
+// and the sequence of distinct states observed, which is recorded in the test result.
+func (r *TestCaseRunner) pollUntilTerminal(req *resty.Request, tc *model.TestCase, ctx *model.Context, resp *resty.Response, metrics results.Metrics) (*resty.Response, results.Metrics, []string, error) {
+	poll := tc.Expect.Poll
+	deadline := time.Now().Add(poll.Timeout())
+	observed := []string{}
+
+	for {
+		state, err := poll.State(resp.String())
+		if err != nil {
+			return resp, metrics, observed, err
+		}
+
+		if len(observed) > 0 {
+			previous := observed[len(observed)-1]
+			if err := poll.CheckTransition(previous, state); err != nil {
+				return resp, metrics, append(observed, state), err
+			}
+		}
+		if len(observed) == 0 || observed[len(observed)-1] != state {
+			observed = append(observed, state)
+		}
+
+		if poll.IsFailure(state) {
+			return resp, metrics, observed, fmt.Errorf("poll: failure state %s reached", state)
+		}
+		if poll.IsTerminal(state) {
+			return resp, metrics, observed, nil
+		}
+
+		if time.Now().Add(poll.Interval()).After(deadline) {
+			return resp, metrics, observed, fmt.Errorf("poll: no terminal state reached within %s, last observed state %s", poll.Timeout(), state)
+		}
+		time.Sleep(poll.Interval())
+
+		if r.daemonController.ShouldStop() {
+			return resp, metrics, observed, errors.New("poll: test run stopped while polling")
+		}
+
+		resp, metrics, err = r.executor.ExecuteTestCase(reissueRequest(req), tc, ctx)
+		if err != nil {
+			return resp, metrics, observed, errors.Wrap(err, "poll: re-issuing request")
+		}
+	}
+}
+
+// reissueRequest builds a copy of an already executed request so that it can be sent again.
+// Once executed, the URL of a request already carries its encoded query parameters, so they are not copied.
+func reissueRequest(r *resty.Request) *resty.Request {
+	req := resty.R()
+	req.Method = r.Method
+	req.URL = r.URL
+	req.Header = r.Header.Clone()
+	if r.Body != nil {
+		req.SetBody(r.Body)
+	}
+	if len(r.FormData) > 0 {
+		req.FormData = url.Values{}
+		for k, v := range r.FormData {
+			req.FormData[k] = append([]string{}, v...)
+		}
+	}
+	return req
+}
@@ -0,0 +1,131 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/mocks"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+// requestExecutor sends requests unchanged, without any certificate handling
+type requestExecutor struct{}
+
+func (requestExecutor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, results.Metrics, error) {
+	resp, err := r.Execute(r.Method, r.URL)
+	return resp, results.Metrics{}, err
+}
+
+func (requestExecutor) SetCertificates(certificateSigning, certificationTransport authentication.Certificate) error {
+	return nil
+}
+
+// statusServer replies with each of the statuses in turn, repeating the last one
+func statusServer(t *testing.T, statuses ...string) (*httptest.Server, *int) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		assert.Len(t, r.URL.Query()["page"], 1)
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"Data":{"Status":"%s"}}`, status)
+	}))
+	return server, &calls
+}
+
+func requestTestRunner() *TestCaseRunner {
+	controller := &mocks.DaemonController{}
+	controller.On("ShouldStop").Return(false)
+	runner := NewTestCaseRunner(test.NullLogger(), RunDefinition{}, controller)
+	runner.executor = requestExecutor{}
+	return runner
+}
+
+func executePoll(t *testing.T, runner *TestCaseRunner, url string, poll *model.Poll) ([]string, error) {
+	tc := &model.TestCase{Expect: model.Expect{Poll: poll}}
+	ctx := &model.Context{}
+	req := resty.R().SetQueryParam("page", "1")
+	req.Method = http.MethodGet
+	req.URL = url
+	resp, _, err := runner.executor.ExecuteTestCase(req, tc, ctx)
+	require.NoError(t, err)
+	_, _, states, err := runner.pollUntilTerminal(req, tc, ctx, resp, results.Metrics{})
+	return states, err
+}
+
+func TestPollUntilTerminalReachesTerminalState(t *testing.T) {
+	server, calls := statusServer(t, "Pending", "Pending", "AcceptedSettlementInProcess", "AcceptedSettlementCompleted")
+	defer server.Close()
+
+	states, err := executePoll(t, requestTestRunner(), server.URL, &model.Poll{
+		JSON:           "Data.Status",
+		TerminalStates: []string{"AcceptedSettlementCompleted", "Rejected"},
+		IntervalMs:     1,
+		TimeoutMs:      1000,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Pending", "AcceptedSettlementInProcess", "AcceptedSettlementCompleted"}, states)
+	assert.Equal(t, 4, *calls)
+}
+
+func TestPollUntilTerminalFailureState(t *testing.T) {
+	server, calls := statusServer(t, "Pending", "Rejected")
+	defer server.Close()
+
+	states, err := executePoll(t, requestTestRunner(), server.URL, &model.Poll{
+		JSON:           "Data.Status",
+		TerminalStates: []string{"AcceptedSettlementCompleted"},
+		FailureStates:  []string{"Rejected"},
+		IntervalMs:     1,
+		TimeoutMs:      1000,
+	})
+
+	assert.EqualError(t, err, "poll: failure state Rejected reached")
+	assert.Equal(t, []string{"Pending", "Rejected"}, states)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestPollUntilTerminalIllegalTransition(t *testing.T) {
+	server, _ := statusServer(t, "AcceptedSettlementInProcess", "Pending")
+	defer server.Close()
+
+	states, err := executePoll(t, requestTestRunner(), server.URL, &model.Poll{
+		JSON:           "Data.Status",
+		TerminalStates: []string{"AcceptedSettlementCompleted"},
+		IntervalMs:     1,
+		TimeoutMs:      1000,
+	})
+
+	assert.EqualError(t, err, "poll: illegal status transition from AcceptedSettlementInProcess to Pending")
+	assert.Equal(t, []string{"AcceptedSettlementInProcess", "Pending"}, states)
+}
+
+func TestPollUntilTerminalTimeout(t *testing.T) {
+	server, _ := statusServer(t, "Pending")
+	defer server.Close()
+
+	states, err := executePoll(t, requestTestRunner(), server.URL, &model.Poll{
+		JSON:           "Data.Status",
+		TerminalStates: []string{"AcceptedSettlementCompleted"},
+		IntervalMs:     5,
+		TimeoutMs:      30,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no terminal state reached within 30ms")
+	assert.Equal(t, []string{"Pending"}, states)
+}
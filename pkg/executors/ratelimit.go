@@ -0,0 +1,241 @@
+package executors
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// RateLimit paces the requests sent to a host: at most RequestsPerSecond, in bursts of at most Burst, with at
+// most MaxInFlight awaiting their response. The limit with an empty host, or `*`, applies to each of the hosts
+// without a limit of their own.
+type RateLimit struct {
+	Host              string  `json:"host,omitempty"`                // Host, with its port if not the default, e.g. ob19-rs1.o3bank.co.uk:4501
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"` // Not limited if not set
+	Burst             int     `json:"burst,omitempty"`               // Requests sent at once after a pause, 1 if not set
+	MaxInFlight       int     `json:"max_in_flight,omitempty"`       // Not limited if not set
+}
+
+// Validate checks the rate and caps of the limit are not negative and that it limits something
+func (l RateLimit) Validate() error {
+	if l.RequestsPerSecond < 0 || l.Burst < 0 || l.MaxInFlight < 0 {
+		return fmt.Errorf("rate limit %s: requests_per_second, burst and max_in_flight must not be negative", l.host())
+	}
+	if l.RequestsPerSecond == 0 && l.MaxInFlight == 0 {
+		return fmt.Errorf("rate limit %s: neither requests_per_second nor max_in_flight is set", l.host())
+	}
+	return nil
+}
+
+func (l RateLimit) host() string {
+	if l.Host == "" {
+		return "*"
+	}
+	return strings.ToLower(l.Host)
+}
+
+func (l RateLimit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+// RateLimiter paces the requests sent to each host as configured, and pauses the requests to a host for the
+// time a `429 Too Many Requests` or a `503 Service Unavailable` response asks with its `Retry-After` header. It is
+// shared by the runners of a journey.
+type RateLimiter struct {
+	limits map[string]RateLimit
+	lock   sync.Mutex
+	hosts  map[string]*hostLimiter
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+type hostLimiter struct {
+	limit       RateLimit
+	lock        sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	inFlight    chan struct{}
+}
+
+// NewRateLimiter returns the rate limiter of limits, nil when there are none
+func NewRateLimiter(limits []RateLimit) (*RateLimiter, error) {
+	if len(limits) == 0 {
+		return nil, nil
+	}
+	l := &RateLimiter{limits: map[string]RateLimit{}, hosts: map[string]*hostLimiter{}, now: time.Now, sleep: time.Sleep}
+	for _, limit := range limits {
+		if err := limit.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := l.limits[limit.host()]; ok {
+			return nil, fmt.Errorf("rate limit %s: limited more than once", limit.host())
+		}
+		l.limits[limit.host()] = limit
+	}
+	return l, nil
+}
+
+// Executor returns executor, its requests paced by the limiter
+func (l *RateLimiter) Executor(executor TestCaseExecutor) TestCaseExecutor {
+	return &rateLimitedExecutor{executor: executor, limiter: l}
+}
+
+// host returns the limiter of the host, nil when it is not limited
+func (l *RateLimiter) host(host string) *hostLimiter {
+	host = strings.ToLower(host)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if h, ok := l.hosts[host]; ok {
+		return h
+	}
+	limit, ok := l.limits[host]
+	if !ok {
+		limit, ok = l.limits["*"]
+	}
+	if !ok {
+		l.hosts[host] = nil
+		return nil
+	}
+	h := &hostLimiter{limit: limit, tokens: limit.burst(), last: l.now()}
+	if limit.MaxInFlight > 0 {
+		h.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	l.hosts[host] = h
+	return h
+}
+
+// acquire waits until a request can be sent to the host, returning the time waited and the function to call once
+// the response is received
+func (l *RateLimiter) acquire(host string) (time.Duration, func()) {
+	h := l.host(host)
+	if h == nil {
+		return 0, func() {}
+	}
+	start := l.now()
+	if h.inFlight != nil {
+		h.inFlight <- struct{}{}
+	}
+	for {
+		wait := h.take(l.now())
+		if wait <= 0 {
+			break
+		}
+		l.sleep(wait)
+	}
+	release := func() {
+		if h.inFlight != nil {
+			<-h.inFlight
+		}
+	}
+	return l.now().Sub(start), release
+}
+
+// pause stops the requests to the host until the time asked by the response, if any
+func (l *RateLimiter) pause(host string, resp *resty.Response) {
+	if resp == nil || resp.RawResponse == nil {
+		return
+	}
+	if resp.StatusCode() != http.StatusTooManyRequests && resp.StatusCode() != http.StatusServiceUnavailable {
+		return
+	}
+	retryAfter, ok := parseRetryAfter(resp.Header().Get("Retry-After"), l.now())
+	if !ok {
+		return
+	}
+	if h := l.host(host); h != nil {
+		h.pauseUntil(l.now().Add(retryAfter))
+	}
+}
+
+// take takes a token, returning 0, or returns the time to wait for one
+func (h *hostLimiter) take(now time.Time) time.Duration {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if now.Before(h.pausedUntil) {
+		return h.pausedUntil.Sub(now)
+	}
+	if h.limit.RequestsPerSecond == 0 {
+		return 0
+	}
+	h.tokens = math.Min(h.limit.burst(), h.tokens+now.Sub(h.last).Seconds()*h.limit.RequestsPerSecond)
+	h.last = now
+	if h.tokens >= 1 {
+		h.tokens--
+		return 0
+	}
+	return time.Duration((1 - h.tokens) / h.limit.RequestsPerSecond * float64(time.Second))
+}
+
+func (h *hostLimiter) pauseUntil(until time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if until.After(h.pausedUntil) {
+		h.pausedUntil = until
+	}
+}
+
+// parseRetryAfter returns the wait asked by a `Retry-After` header, in seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if date.Before(now) {
+		return 0, true
+	}
+	return date.Sub(now), true
+}
+
+// rateLimitedExecutor sends the requests of an executor when the limiter of their host allows it
+type rateLimitedExecutor struct {
+	executor TestCaseExecutor
+	limiter  *RateLimiter
+}
+
+// ExecuteTestCase waits for the limiter of the host of the request, then executes the test case. The time waited
+// is recorded in the metrics.
+func (e *rateLimitedExecutor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, results.Metrics, error) {
+	if t.DoNotCallEndpoint {
+		return e.executor.ExecuteTestCase(r, t, ctx)
+	}
+	host := r.URL
+	if u, err := url.Parse(r.URL); err == nil {
+		host = u.Host
+	}
+	throttled, release := e.limiter.acquire(host)
+	resp, metrics, err := e.executor.ExecuteTestCase(r, t, ctx)
+	release()
+	e.limiter.pause(host, resp)
+	metrics.Throttled = throttled
+	return resp, metrics, err
+}
+
+// SetCertificates sets the certificates of the executor limited
+func (e *rateLimitedExecutor) SetCertificates(certificateSigning, certificationTransport authentication.Certificate) error {
+	return e.executor.SetCertificates(certificateSigning, certificationTransport)
+}
@@ -0,0 +1,154 @@
+package executors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+// fakeClockLimiter returns a limiter whose sleeps advance its clock, and the waits it slept
+func fakeClockLimiter(t *testing.T, limits ...RateLimit) (*RateLimiter, *[]time.Duration) {
+	limiter, err := NewRateLimiter(limits)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	waits := []time.Duration{}
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(wait time.Duration) {
+		waits = append(waits, wait)
+		now = now.Add(wait)
+	}
+	return limiter, &waits
+}
+
+func TestRateLimitValidate(t *testing.T) {
+	assert.NoError(t, RateLimit{RequestsPerSecond: 5}.Validate())
+	assert.NoError(t, RateLimit{Host: "ob19-rs1.o3bank.co.uk:4501", MaxInFlight: 2}.Validate())
+	assert.EqualError(t, RateLimit{RequestsPerSecond: -1}.Validate(), "rate limit *: requests_per_second, burst and max_in_flight must not be negative")
+	assert.EqualError(t, RateLimit{Host: "ob19-rs1.o3bank.co.uk:4501", Burst: 5}.Validate(), "rate limit ob19-rs1.o3bank.co.uk:4501: neither requests_per_second nor max_in_flight is set")
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	limiter, err := NewRateLimiter(nil)
+	require.NoError(t, err)
+	assert.Nil(t, limiter)
+
+	_, err = NewRateLimiter([]RateLimit{{RequestsPerSecond: 5}, {Host: "*", MaxInFlight: 1}})
+	assert.EqualError(t, err, "rate limit *: limited more than once")
+}
+
+func TestRateLimiterPacesRequests(t *testing.T) {
+	limiter, waits := fakeClockLimiter(t, RateLimit{RequestsPerSecond: 4, Burst: 2})
+
+	throttled := []time.Duration{}
+	for i := 0; i < 4; i++ {
+		wait, release := limiter.acquire("ob19-rs1.o3bank.co.uk:4501")
+		release()
+		throttled = append(throttled, wait)
+	}
+
+	assert.Equal(t, []time.Duration{0, 0, 250 * time.Millisecond, 250 * time.Millisecond}, throttled)
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}, *waits)
+}
+
+func TestRateLimiterHosts(t *testing.T) {
+	limiter, _ := fakeClockLimiter(t,
+		RateLimit{Host: "OB19-RS1.o3bank.co.uk:4501", RequestsPerSecond: 1},
+		RateLimit{Host: "*", RequestsPerSecond: 10},
+	)
+
+	assert.Equal(t, 1.0, limiter.host("ob19-rs1.o3bank.co.uk:4501").limit.RequestsPerSecond)
+	assert.Equal(t, 10.0, limiter.host("ob19-auth1.o3bank.co.uk:4201").limit.RequestsPerSecond)
+
+	limiter, _ = fakeClockLimiter(t, RateLimit{Host: "ob19-rs1.o3bank.co.uk:4501", RequestsPerSecond: 1})
+	assert.Nil(t, limiter.host("ob19-auth1.o3bank.co.uk:4201"))
+	wait, release := limiter.acquire("ob19-auth1.o3bank.co.uk:4201")
+	release()
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	limiter, err := NewRateLimiter([]RateLimit{{MaxInFlight: 2}})
+	require.NoError(t, err)
+
+	lock := sync.Mutex{}
+	inFlight, maxInFlight := 0, 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, release := limiter.acquire("ob19-rs1.o3bank.co.uk:4501")
+			lock.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			lock.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, maxInFlight)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = parseRetryAfter("Mon, 19 Oct 2026 08:00:05 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+
+	wait, ok = parseRetryAfter("Mon, 19 Oct 2026 07:59:00 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok = parseRetryAfter(value, now)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestRateLimitedExecutorPausesOnRetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"Data":{}}`))
+	}))
+	defer server.Close()
+	limiter, waits := fakeClockLimiter(t, RateLimit{RequestsPerSecond: 100})
+	runner, retryWaits := retryTestRunner(RetryPolicy{MaxAttempts: 2, Backoff: "10ms"})
+	runner.executor.(*retryingExecutor).executor = limiter.Executor(requestExecutor{})
+
+	result := runner.executeTest(retryTestCase(http.MethodGet, server.URL, nil), &model.Context{}, test.NullLogger())
+
+	require.True(t, result.Pass, result.Fail)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, result.Metrics.Attempts)
+	assert.Equal(t, []time.Duration{2 * time.Second}, *retryWaits, "the retry waits as asked by Retry-After")
+	assert.Equal(t, []time.Duration{2 * time.Second}, *waits, "the host is paused as asked by Retry-After")
+	assert.Equal(t, 2*time.Second, result.Metrics.Throttled)
+}
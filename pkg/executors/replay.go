@@ -0,0 +1,29 @@
+package executors
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// replayTest sends the request of a testcase with a `replay` a second time, keeping its x-idempotency-key,
+// and compares the replayed response with the original one. It returns the replayed response along with
+// any differences found.
+func (r *TestCaseRunner) replayTest(req *resty.Request, tc *model.TestCase, ctx *model.Context, resp *resty.Response) (*resty.Response, []error) {
+	replayReq := reissueRequest(req)
+	if err := tc.ApplyReplay(replayReq, ctx); err != nil {
+		return nil, []error{err}
+	}
+
+	replayResp, _, err := r.executor.ExecuteTestCase(replayReq, tc, ctx)
+	if err != nil {
+		return replayResp, []error{errors.Wrap(err, "replay: executing request")}
+	}
+
+	errs := tc.Expect.Replay.Check(resp, replayResp)
+	if len(errs) > 0 {
+		return replayResp, errs
+	}
+	return replayResp, nil
+}
@@ -0,0 +1,78 @@
+package executors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// idempotentServer creates a consent per x-idempotency-key and rejects a key reused with a different body
+func idempotentServer(t *testing.T) *httptest.Server {
+	bodies := map[string]string{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		key := r.Header.Get("x-idempotency-key")
+		w.Header().Set("Content-Type", "application/json")
+		if previous, seen := bodies[key]; seen && previous != string(body) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"Errors":[{"ErrorCode":"UK.OBIE.Resource.ConsentMismatch"}]}`)
+			return
+		}
+		bodies[key] = string(body)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"Data":{"ConsentId":"consent-%s"}}`, key)
+	}))
+}
+
+func executeReplay(t *testing.T, url string, tc *model.TestCase) []error {
+	runner := requestTestRunner()
+	ctx := &model.Context{}
+	tc.Input = model.Input{RequestBody: `{"Data":{"Initiation":{"CreditorAccount":{"Name":"Original"}}}}`}
+	req := resty.R().SetHeader("x-idempotency-key", "key-1").SetBody(tc.Input.RequestBody)
+	req.Method = http.MethodPost
+	req.URL = url
+
+	resp, _, err := runner.executor.ExecuteTestCase(req, tc, ctx)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	_, errs := runner.replayTest(req, tc, ctx, resp)
+	return errs
+}
+
+func TestReplayTestSameBodyReturnsSameConsent(t *testing.T) {
+	server := idempotentServer(t)
+	defer server.Close()
+
+	errs := executeReplay(t, server.URL, &model.TestCase{
+		Replay: &model.Replay{},
+		Expect: model.Expect{Replay: &model.ReplayExpect{StatusCode: http.StatusCreated, SameJSON: []string{"Data.ConsentId"}}},
+	})
+	assert.Empty(t, errs)
+}
+
+func TestReplayTestChangedBodyIsRejected(t *testing.T) {
+	server := idempotentServer(t)
+	defer server.Close()
+
+	errs := executeReplay(t, server.URL, &model.TestCase{
+		Replay: &model.Replay{Body: map[string]string{"Data.Initiation.CreditorAccount.Name": "Changed"}},
+		Expect: model.Expect{Replay: &model.ReplayExpect{StatusCode: http.StatusBadRequest}},
+	})
+	assert.Empty(t, errs)
+
+	errs = executeReplay(t, server.URL, &model.TestCase{
+		Replay: &model.Replay{Body: map[string]string{"Data.Initiation.CreditorAccount.Name": "Changed"}},
+	})
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "replay: (400) actual status code does not match expected status code (201)")
+}
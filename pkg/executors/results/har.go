@@ -0,0 +1,332 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
+	"github.com/OpenBankingUK/conformance-suite/pkg/version"
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+)
+
+// HARVersion is the version of the HTTP Archive format of exchange archives
+const HARVersion = "1.2"
+
+// ExchangeCapture configures the recording of the HTTP requests and responses of each test case, by default
+// they are not recorded
+type ExchangeCapture struct {
+	Enabled       bool     `json:"enabled"`
+	RedactHeaders []string `json:"redact_headers,omitempty"` // Headers redacted besides those redact.IsSensitiveHeader, case insensitive
+	RedactFields  []string `json:"redact_fields,omitempty"`  // Fields and parameters redacted besides those redact.IsSensitiveKey, case insensitive
+}
+
+// Validate checks the names of the headers and fields to redact are not empty
+func (c ExchangeCapture) Validate() error {
+	for _, name := range append(append([]string{}, c.RedactHeaders...), c.RedactFields...) {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("exchange capture: redacted header or field name is empty")
+		}
+	}
+	return nil
+}
+
+func (c ExchangeCapture) redactsHeader(name string) bool {
+	return redact.IsSensitiveHeader(name) || containsFold(c.RedactHeaders, name)
+}
+
+func (c ExchangeCapture) redactsField(name string) bool {
+	return redact.IsSensitiveKey(name) || containsFold(c.RedactFields, name)
+}
+
+// HAR is an HTTP Archive, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the root of an HTTP Archive
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator is the application which created an HTTP Archive
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a request sent and the response received. TestCaseID is the `_testCaseId` custom field, the id
+// of the test case which sent the request.
+type HAREntry struct {
+	TestCaseID      string      `json:"_testCaseId"`
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"` // Error sending the request, if any
+}
+
+// HARRequest is the request of an entry
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse is the response of an entry, status 0 when no response was received
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue is a header, cookie or query parameter
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a request
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the body of a response
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARTimings are the durations, in milliseconds, of an exchange. Only the time waiting for the response is known.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// NewHAR returns an HTTP Archive of entries
+func NewHAR(entries []HAREntry) HAR {
+	if entries == nil {
+		entries = []HAREntry{}
+	}
+	return HAR{Log: HARLog{
+		Version: HARVersion,
+		Creator: HARCreator{Name: "Functional Conformance Suite", Version: version.FullVersion},
+		Entries: entries,
+	}}
+}
+
+// ExchangeArchive returns an HTTP Archive of the exchanges recorded by the results, in the order they were run
+func ExchangeArchive(grouped map[ResultKey][]TestCase) HAR {
+	entries := []HAREntry{}
+	for _, key := range sortedKeys(grouped) {
+		for _, result := range grouped[key] {
+			entries = append(entries, result.Exchanges...)
+		}
+	}
+	return NewHAR(entries)
+}
+
+// NewHAREntry records the request sent by a test case and the response received, err being the error sending
+// the request, if any. Headers, fields and parameters are redacted as configured by capture.
+func NewHAREntry(testCaseID string, req *resty.Request, resp *resty.Response, err error, capture ExchangeCapture) HAREntry {
+	entry := HAREntry{
+		TestCaseID:      testCaseID,
+		StartedDateTime: req.Time.Format(time.RFC3339Nano),
+		Request:         newHARRequest(req, capture),
+		Response:        HARResponse{Cookies: []HARNameValue{}, Headers: []HARNameValue{}, HeadersSize: -1, BodySize: -1},
+	}
+	if req.Time.IsZero() {
+		entry.StartedDateTime = time.Now().Format(time.RFC3339Nano)
+	}
+	if err != nil {
+		entry.Comment = err.Error()
+	}
+	if resp == nil || resp.RawResponse == nil {
+		return entry
+	}
+
+	entry.Time = float64(resp.Time()) / float64(time.Millisecond)
+	entry.Timings = HARTimings{Wait: entry.Time}
+	contentType := resp.Header().Get("Content-Type")
+	body := redactBody(string(resp.Body()), contentType, capture)
+	entry.Response = HARResponse{
+		Status:      resp.StatusCode(),
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status(), fmt.Sprint(resp.StatusCode()))),
+		HTTPVersion: resp.RawResponse.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(resp.Header(), capture),
+		Content:     HARContent{Size: len(resp.Body()), MimeType: contentType, Text: body},
+		RedirectURL: resp.Header().Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(resp.Body()),
+	}
+	return entry
+}
+
+func newHARRequest(req *resty.Request, capture ExchangeCapture) HARRequest {
+	rawURL, headers, httpVersion := req.URL, req.Header, "HTTP/1.1"
+	if req.RawRequest != nil {
+		rawURL, headers, httpVersion = req.RawRequest.URL.String(), req.RawRequest.Header, req.RawRequest.Proto
+	}
+	request := HARRequest{
+		Method:      req.Method,
+		URL:         rawURL,
+		HTTPVersion: httpVersion,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(headers, capture),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		query := u.Query()
+		if redactValues(query, capture) {
+			u.RawQuery = query.Encode()
+			request.URL = u.String()
+		}
+		request.QueryString = harValues(query)
+	}
+
+	mimeType := headers.Get("Content-Type")
+	var text string
+	switch body := req.Body.(type) {
+	case nil:
+		if len(req.FormData) == 0 {
+			return request
+		}
+		text = req.FormData.Encode()
+		if mimeType == "" {
+			mimeType = "application/x-www-form-urlencoded"
+		}
+	case string:
+		text = body
+	case []byte:
+		text = string(body)
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return request
+		}
+		text = string(raw)
+	}
+	request.BodySize = len(text)
+	request.PostData = &HARPostData{MimeType: mimeType, Text: redactBody(text, mimeType, capture)}
+	return request
+}
+
+// harHeaders returns the headers sorted by name, those redacted by capture with their value replaced
+func harHeaders(headers http.Header, capture ExchangeCapture) []HARNameValue {
+	values := []HARNameValue{}
+	for name, vs := range headers {
+		for _, v := range vs {
+			if capture.redactsHeader(name) {
+				v = redact.Value
+			}
+			values = append(values, HARNameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+func harValues(values url.Values) []HARNameValue {
+	nameValues := []HARNameValue{}
+	for name, vs := range values {
+		for _, v := range vs {
+			nameValues = append(nameValues, HARNameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(nameValues, func(i, j int) bool { return nameValues[i].Name < nameValues[j].Name })
+	return nameValues
+}
+
+// redactValues redacts the form or query parameters of capture, returning true when any was redacted
+func redactValues(values url.Values, capture ExchangeCapture) bool {
+	redacted := false
+	for name, vs := range values {
+		if redact.IsSensitiveParameter(name) || capture.redactsField(name) {
+			for i := range vs {
+				vs[i] = redact.Value
+			}
+			redacted = true
+		}
+	}
+	return redacted
+}
+
+// redactBody redacts the fields of a JSON or form body, the body is returned as is when nothing is redacted
+func redactBody(body, contentType string, capture ExchangeCapture) string {
+	if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(body)
+		if err != nil || !redactValues(values, capture) {
+			return body
+		}
+		return values.Encode()
+	}
+
+	var document interface{}
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		return body
+	}
+	if !redactJSON(document, capture) {
+		return body
+	}
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+	return string(raw)
+}
+
+func redactJSON(node interface{}, capture ExchangeCapture) bool {
+	redacted := false
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			if capture.redactsField(k) {
+				n[k] = redact.Value
+				redacted = true
+				continue
+			}
+			redacted = redactJSON(v, capture) || redacted
+		}
+	case []interface{}:
+		for _, v := range n {
+			redacted = redactJSON(v, capture) || redacted
+		}
+	}
+	return redacted
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
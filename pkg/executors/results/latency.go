@@ -0,0 +1,158 @@
+package results
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Latency is the distribution of the response times of the tests of an endpoint, in milliseconds
+type Latency struct {
+	Endpoint string  `json:"endpoint"` // Path of the endpoint, without query
+	Count    int     `json:"count"`    // Number of responses
+	Min      float64 `json:"min"`
+	Avg      float64 `json:"avg"`
+	P50      float64 `json:"p50"`
+	P95      float64 `json:"p95"`
+	Max      float64 `json:"max"`
+}
+
+// Latencies returns the latency of each endpoint called during the run, sorted by endpoint.
+// Tests not run, without a response time, are left out.
+func Latencies(grouped map[ResultKey][]TestCase) []Latency {
+	times := map[string][]time.Duration{}
+	for _, results := range grouped {
+		for _, result := range results {
+			if result.Metrics.ResponseTime <= 0 {
+				continue
+			}
+			endpoint := endpointPath(result.Endpoint)
+			times[endpoint] = append(times[endpoint], result.Metrics.ResponseTime)
+		}
+	}
+
+	latencies := make([]Latency, 0, len(times))
+	for endpoint, durations := range times {
+		latencies = append(latencies, newLatency(endpoint, durations))
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i].Endpoint < latencies[j].Endpoint
+	})
+	return latencies
+}
+
+func newLatency(endpoint string, durations []time.Duration) Latency {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return Latency{
+		Endpoint: endpoint,
+		Count:    len(durations),
+		Min:      milliseconds(durations[0]),
+		Avg:      milliseconds(total / time.Duration(len(durations))),
+		P50:      milliseconds(percentile(durations, 50)),
+		P95:      milliseconds(percentile(durations, 95)),
+		Max:      milliseconds(durations[len(durations)-1]),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted, which must not be empty
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// endpointPath strips the scheme, host and query of the endpoint of a result
+func endpointPath(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Path == "" {
+		return endpoint
+	}
+	return u.Path
+}
+
+// PerformanceBudget is the maximum response time of a percentile of the responses of the endpoints, or of the
+// manifest resource, selected, e.g. 95% of the responses of `/accounts` under 1s
+type PerformanceBudget struct {
+	Endpoint   string  `json:"endpoint,omitempty"`   // Regular expression matched against the endpoint path
+	Resource   string  `json:"resource,omitempty"`   // Manifest resource of the tests, e.g. DomesticPayment
+	Percentile float64 `json:"percentile,omitempty"` // Percentile of the responses, 95 if not set
+	Max        string  `json:"max"`                  // Maximum response time, a duration such as 500ms or 1s
+}
+
+// Validate checks the budget can be evaluated
+func (b PerformanceBudget) Validate() error {
+	if _, err := regexp.Compile(b.Endpoint); err != nil {
+		return fmt.Errorf("performance budget endpoint %q: %s", b.Endpoint, err.Error())
+	}
+	if b.Percentile < 0 || b.Percentile > 100 {
+		return fmt.Errorf("performance budget percentile %g: must be between 0 and 100", b.Percentile)
+	}
+	max, err := time.ParseDuration(b.Max)
+	if err != nil {
+		return fmt.Errorf("performance budget max %q: %s", b.Max, err.Error())
+	}
+	if max <= 0 {
+		return fmt.Errorf("performance budget max %q: must be positive", b.Max)
+	}
+	return nil
+}
+
+// BudgetResult is the evaluation of a budget against the responses it selects
+type BudgetResult struct {
+	Budget PerformanceBudget `json:"budget"`
+	Count  int               `json:"count"`  // Number of responses selected
+	Actual float64           `json:"actual"` // Response time of the percentile, in milliseconds
+	Met    bool              `json:"met"`    // True when no response is selected
+}
+
+// EvaluateBudgets checks the response times of the run against each budget
+func EvaluateBudgets(budgets []PerformanceBudget, grouped map[ResultKey][]TestCase) ([]BudgetResult, error) {
+	evaluated := make([]BudgetResult, 0, len(budgets))
+	for _, budget := range budgets {
+		if err := budget.Validate(); err != nil {
+			return nil, err
+		}
+		endpoint := regexp.MustCompile(budget.Endpoint)
+		max, _ := time.ParseDuration(budget.Max)
+		p := budget.Percentile
+		if p == 0 {
+			p = 95
+		}
+
+		durations := []time.Duration{}
+		for _, key := range sortedKeys(grouped) {
+			for _, result := range grouped[key] {
+				if result.Metrics.ResponseTime <= 0 || !endpoint.MatchString(endpointPath(result.Endpoint)) {
+					continue
+				}
+				if budget.Resource != "" && budget.Resource != result.Resource {
+					continue
+				}
+				durations = append(durations, result.Metrics.ResponseTime)
+			}
+		}
+
+		budgetResult := BudgetResult{Budget: budget, Count: len(durations), Met: true}
+		if len(durations) > 0 {
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			actual := percentile(durations, p)
+			budgetResult.Actual = milliseconds(actual)
+			budgetResult.Met = actual <= max
+		}
+		evaluated = append(evaluated, budgetResult)
+	}
+	return evaluated, nil
+}
@@ -0,0 +1,66 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func latencyResult(endpoint, resource string, responseTime time.Duration) TestCase {
+	return TestCase{Endpoint: endpoint, Resource: resource, Metrics: Metrics{ResponseTime: responseTime}}
+}
+
+func latencyResults() map[ResultKey][]TestCase {
+	accounts := []TestCase{latencyResult("https://ob.example.com/open-banking/v3.1/aisp/accounts?page=2", "Account", 0)}
+	for i := 1; i <= 20; i++ {
+		accounts = append(accounts, latencyResult("https://ob.example.com/open-banking/v3.1/aisp/accounts", "Account", time.Duration(i)*100*time.Millisecond))
+	}
+	return map[ResultKey][]TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: accounts,
+		{APIName: "Payments", APIVersion: "v3.1"}: {
+			latencyResult("https://ob.example.com/open-banking/v3.1/pisp/domestic-payment-consents", "DomesticPayment", 300*time.Millisecond),
+			latencyResult("https://ob.example.com/open-banking/v3.1/pisp/domestic-payment-consents", "DomesticPayment", 100*time.Millisecond),
+		},
+	}
+}
+
+func TestLatencies(t *testing.T) {
+	latencies := Latencies(latencyResults())
+
+	assert.Equal(t, []Latency{
+		{Endpoint: "/open-banking/v3.1/aisp/accounts", Count: 20, Min: 100, Avg: 1050, P50: 1000, P95: 1900, Max: 2000},
+		{Endpoint: "/open-banking/v3.1/pisp/domestic-payment-consents", Count: 2, Min: 100, Avg: 200, P50: 100, P95: 300, Max: 300},
+	}, latencies)
+	assert.Empty(t, Latencies(map[ResultKey][]TestCase{}))
+}
+
+func TestEvaluateBudgets(t *testing.T) {
+	budgets := []PerformanceBudget{
+		{Endpoint: "/accounts$", Max: "1s"},
+		{Endpoint: "/accounts$", Percentile: 50, Max: "1s"},
+		{Resource: "DomesticPayment", Percentile: 100, Max: "300ms"},
+		{Endpoint: "/balances$", Max: "1s"},
+	}
+
+	evaluated, err := EvaluateBudgets(budgets, latencyResults())
+	require.NoError(t, err)
+	assert.Equal(t, []BudgetResult{
+		{Budget: budgets[0], Count: 20, Actual: 1900, Met: false},
+		{Budget: budgets[1], Count: 20, Actual: 1000, Met: true},
+		{Budget: budgets[2], Count: 2, Actual: 300, Met: true},
+		{Budget: budgets[3], Count: 0, Actual: 0, Met: true},
+	}, evaluated)
+}
+
+func TestPerformanceBudgetValidate(t *testing.T) {
+	assert.NoError(t, PerformanceBudget{Endpoint: "/accounts", Max: "1s"}.Validate())
+	assert.EqualError(t, PerformanceBudget{Endpoint: "(", Max: "1s"}.Validate(), "performance budget endpoint \"(\": error parsing regexp: missing closing ): `(`")
+	assert.EqualError(t, PerformanceBudget{Percentile: 101, Max: "1s"}.Validate(), "performance budget percentile 101: must be between 0 and 100")
+	assert.EqualError(t, PerformanceBudget{Max: "fast"}.Validate(), "performance budget max \"fast\": time: invalid duration \"fast\"")
+	assert.EqualError(t, PerformanceBudget{Max: "0s"}.Validate(), "performance budget max \"0s\": must be positive")
+
+	_, err := EvaluateBudgets([]PerformanceBudget{{Max: "fast"}}, latencyResults())
+	assert.Error(t, err)
+}
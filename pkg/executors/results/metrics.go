@@ -12,6 +12,8 @@ type Metrics struct {
 	TestCase     *model.TestCase
 	ResponseTime time.Duration // Http Response Time
 	ResponseSize int           // Size in bytes of the HTTP Response body
+	Attempts     int           // Requests sent to get the response, 0 when not known
+	Throttled    time.Duration // Time waiting for the rate limits of the host before sending the requests
 }
 
 // MarshalJSON is a custom marshaler which formats a Metrics struct
@@ -21,9 +23,13 @@ func (m Metrics) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		ResponseTime float64 `json:"response_time"`
 		ResponseSize int     `json:"response_size"`
+		Attempts     int     `json:"attempts,omitempty"`
+		Throttled    float64 `json:"throttled,omitempty"`
 	}{
 		ResponseTime: float64(m.ResponseTime) / float64(time.Millisecond),
 		ResponseSize: m.ResponseSize,
+		Attempts:     m.Attempts,
+		Throttled:    float64(m.Throttled) / float64(time.Millisecond),
 	})
 }
 
@@ -42,3 +48,14 @@ func NewMetrics(testCase *model.TestCase, responseTime time.Duration, responseSi
 		ResponseSize: responseSize,
 	}
 }
+
+// TotalThrottled returns the time the requests of the results waited for the rate limits of their host
+func TotalThrottled(grouped map[ResultKey][]TestCase) time.Duration {
+	total := time.Duration(0)
+	for _, results := range grouped {
+		for _, result := range results {
+			total += result.Metrics.Throttled
+		}
+	}
+	return total
+}
@@ -1,6 +1,7 @@
 package results
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -29,3 +30,26 @@ func TestNewMetrics(t *testing.T) {
 	assert.Equal(t, time.Second, metrics.ResponseTime)
 	assert.Equal(t, 1, metrics.ResponseSize)
 }
+
+func TestMetricsMarshalJSON(t *testing.T) {
+	raw, err := json.Marshal(NewMetrics(nil, 1500*time.Microsecond, 10))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"response_time": 1.5, "response_size": 10}`, string(raw))
+
+	metrics := NewMetrics(nil, time.Millisecond, 10)
+	metrics.Attempts = 3
+	metrics.Throttled = 250 * time.Millisecond
+	raw, err = json.Marshal(metrics)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"response_time": 1, "response_size": 10, "attempts": 3, "throttled": 250}`, string(raw))
+}
+
+func TestTotalThrottled(t *testing.T) {
+	grouped := map[ResultKey][]TestCase{
+		{APIName: "Accounts"}: {{Metrics: Metrics{Throttled: time.Second}}, {}},
+		{APIName: "Payments"}: {{Metrics: Metrics{Throttled: 500 * time.Millisecond}}},
+	}
+
+	assert.Equal(t, 1500*time.Millisecond, TotalThrottled(grouped))
+	assert.Equal(t, time.Duration(0), TotalThrottled(nil))
+}
@@ -1,17 +1,145 @@
 package results
 
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
+)
+
+// Status is the outcome of a test case
+type Status string
+
+const (
+	// StatusPass - the implementation conforms
+	StatusPass Status = "pass"
+	// StatusFail - the implementation does not conform
+	StatusFail Status = "fail"
+	// StatusSkipped - not run, a test case it depends on did not pass
+	StatusSkipped Status = "skipped"
+	// StatusError - the suite could not run the test, e.g. bad configuration or a missing token
+	StatusError Status = "error"
+	// StatusWarning - conforms, with advisory findings
+	StatusWarning Status = "warning"
+	// StatusNotApplicable - not run, the test does not apply to the implementation, e.g. an optional endpoint
+	StatusNotApplicable Status = "not-applicable"
+)
+
+// Statuses lists all the statuses, in the order they are reported
+var Statuses = []Status{StatusPass, StatusFail, StatusSkipped, StatusError, StatusWarning, StatusNotApplicable}
+
+// Passed is true when the status does not prevent conformance
+func (s Status) Passed() bool {
+	return s == StatusPass || s == StatusWarning || s == StatusNotApplicable
+}
+
+// Severity is how serious a test case not passing is
+type Severity string
+
+const (
+	// SeverityHigh - non-conformance or a test the suite could not run
+	SeverityHigh Severity = "high"
+	// SeverityMedium - not run because of another test case
+	SeverityMedium Severity = "medium"
+	// SeverityLow - advisory finding
+	SeverityLow Severity = "low"
+	// SeverityInfo - for information only
+	SeverityInfo Severity = "info"
+)
+
 // TestCase result for a run
 type TestCase struct {
-	Id         string   `json:"id"`
-	Pass       bool     `json:"pass"`
-	Metrics    Metrics  `json:"metrics"`
-	Fail       []string `json:"fail,omitempty"`
-	Detail     string   `json:"detail"`
-	RefURI     string   `json:"refURI"`
-	Endpoint   string   `json:"endpoint"`
-	API        string   `json:"-"`
-	APIVersion string   `json:"-"`
-	HttpStatus string   `json:"httpStatusCode"`
+	Id                string   `json:"id"`
+	Pass              bool     `json:"pass"`   // Kept for older clients, true when Status passed
+	Status            Status   `json:"status"` // Derived from Pass when reading results without a status
+	Severity          Severity `json:"severity,omitempty"`
+	Metrics           Metrics  `json:"metrics"`
+	Fail              []string `json:"fail,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"` // Advisory asserts not met, they do not fail the test
+	Detail            string   `json:"detail"`
+	RefURI            string   `json:"refURI"`
+	Endpoint          string   `json:"endpoint"`
+	Resource          string   `json:"resource,omitempty"` // Manifest resource tested, used by performance budgets
+	API               string   `json:"-"`
+	APIVersion        string   `json:"-"`
+	HttpStatus        string   `json:"httpStatusCode"`
+	StatusTransitions []string `json:"statusTransitions,omitempty"` // Sequence of states observed while polling
+	Pages             int      `json:"pages,omitempty"`             // Number of pages checked when following a paged response
+	BlockedBy         string   `json:"blockedBy,omitempty"`         // Id of the test case which should have put the context value
+	Infrastructure    bool     `json:"infrastructure,omitempty"`    // In error because of the network or the infrastructure of the implementation, not of conformance
+	// Context values the test case referenced, and those it put in context, tokens and credentials redacted
+	ContextRead    []model.ContextChange `json:"contextRead,omitempty"`
+	ContextWritten []model.ContextChange `json:"contextWritten,omitempty"`
+	// Requests sent and responses received when exchanges are captured, exported as an HTTP Archive
+	Exchanges []HAREntry `json:"-"`
+}
+
+// UnmarshalJSON reads a result, deriving its status from pass for results written before statuses were added,
+// e.g. in older report archives
+func (t *TestCase) UnmarshalJSON(data []byte) error {
+	type testCase TestCase
+	result := testCase{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*t = TestCase(result)
+	if t.Status == "" {
+		t.Status = StatusFail
+		if t.Pass {
+			t.Status = StatusPass
+		}
+	}
+	return nil
+}
+
+// Redacted returns a copy of the result with the secrets in its failures, warnings, detail and context values
+// redacted, as sent in websocket events and exported in reports
+func (t TestCase) Redacted() TestCase {
+	t.Fail = redactStrings(t.Fail)
+	t.Warnings = redactStrings(t.Warnings)
+	t.Detail = redact.String(t.Detail)
+	t.ContextRead = redactContextChanges(t.ContextRead)
+	t.ContextWritten = redactContextChanges(t.ContextWritten)
+	return t
+}
+
+// Redacted returns a copy of the grouped results, each redacted
+func Redacted(grouped map[ResultKey][]TestCase) map[ResultKey][]TestCase {
+	redacted := make(map[ResultKey][]TestCase, len(grouped))
+	for key, results := range grouped {
+		redacted[key] = make([]TestCase, 0, len(results))
+		for _, result := range results {
+			redacted[key] = append(redacted[key], result.Redacted())
+		}
+	}
+	return redacted
+}
+
+func redactStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	redacted := make([]string, 0, len(values))
+	for _, value := range values {
+		redacted = append(redacted, redact.String(value))
+	}
+	return redacted
+}
+
+func redactContextChanges(changes []model.ContextChange) []model.ContextChange {
+	if changes == nil {
+		return nil
+	}
+	redacted := make([]model.ContextChange, 0, len(changes))
+	for _, change := range changes {
+		change.Value = redact.String(change.Value)
+		if redact.IsSensitiveKey(change.Key) {
+			change.Value = redact.Value
+		}
+		redacted = append(redacted, change)
+	}
+	return redacted
 }
 
 // NewTestCaseFail returns a failed test
@@ -19,17 +147,72 @@ func NewTestCaseFail(id string, metrics Metrics, errs []error, endpoint, api, ap
 	return NewTestCaseResult(id, false, metrics, errs, endpoint, api, apiVersion, detail, refURI, httpStatus)
 }
 
+// NewTestCaseWarning returns a passed test with advisory findings, warnings are the advisory asserts not met
+func NewTestCaseWarning(id string, metrics Metrics, warnings []error, endpoint, apiName, apiVersion, detail, refURI, httpStatus string) TestCase {
+	result := NewTestCaseResult(id, true, metrics, nil, endpoint, apiName, apiVersion, detail, refURI, httpStatus)
+	result.Status = StatusWarning
+	result.Severity = SeverityLow
+	for _, warning := range warnings {
+		result.Warnings = append(result.Warnings, warning.Error())
+	}
+	return result
+}
+
+// NewTestCaseError returns a test the suite could not run, the errors are not a failure of the implementation
+func NewTestCaseError(id string, metrics Metrics, errs []error, endpoint, api, apiVersion, detail, refURI, httpStatus string) TestCase {
+	result := NewTestCaseResult(id, false, metrics, errs, endpoint, api, apiVersion, detail, refURI, httpStatus)
+	result.Status = StatusError
+	return result
+}
+
+// NewTestCaseNotApplicable returns a test not run because it does not apply to the implementation
+func NewTestCaseNotApplicable(id, endpoint, apiName, apiVersion, detail, refURI string) TestCase {
+	return TestCase{
+		API:        apiName,
+		APIVersion: apiVersion,
+		Id:         id,
+		Pass:       true,
+		Status:     StatusNotApplicable,
+		Severity:   SeverityInfo,
+		Endpoint:   endpoint,
+		Detail:     detail,
+		RefURI:     refURI,
+	}
+}
+
+// NewTestCaseSkipped returns a test not run because blockedBy, which puts the context value it uses, failed or
+// was skipped itself
+func NewTestCaseSkipped(id, blockedBy, endpoint, apiName, apiVersion, detail, refURI string) TestCase {
+	return TestCase{
+		API:        apiName,
+		APIVersion: apiVersion,
+		Id:         id,
+		Status:     StatusSkipped,
+		Severity:   SeverityMedium,
+		BlockedBy:  blockedBy,
+		Endpoint:   endpoint,
+		Detail:     detail,
+		RefURI:     refURI,
+	}
+}
+
 // NewTestCaseResult return a new TestCase instance
 func NewTestCaseResult(id string, pass bool, metrics Metrics, errs []error, endpoint, apiName, apiVersion, detail, refURI, httpStatus string) TestCase {
 	reasons := []string{}
 	for _, err := range errs {
 		reasons = append(reasons, err.Error())
 	}
+	status, severity := StatusPass, Severity("")
+	if !pass {
+		status, severity = StatusFail, SeverityHigh
+	}
 	return TestCase{
 		API:        apiName,
 		APIVersion: apiVersion,
 		Id:         id,
 		Pass:       pass,
+		Status:     status,
+		Severity:   severity,
 		Metrics:    metrics,
 		Fail:       reasons,
 		Endpoint:   endpoint,
@@ -39,7 +222,75 @@ func NewTestCaseResult(id string, pass bool, metrics Metrics, errs []error, endp
 	}
 }
 
+// CountByStatus returns the number of results of each status, zero counts included
+func CountByStatus(grouped map[ResultKey][]TestCase) map[Status]int {
+	counts := make(map[Status]int, len(Statuses))
+	for _, status := range Statuses {
+		counts[status] = 0
+	}
+	for _, results := range grouped {
+		for _, result := range results {
+			counts[result.Status]++
+		}
+	}
+	return counts
+}
+
 type ResultKey struct {
 	APIName    string
 	APIVersion string
 }
+
+// FailedIDs returns the ids of the test cases which did not pass: failed, in error or skipped, in the order
+// they were run
+func FailedIDs(grouped map[ResultKey][]TestCase) []string {
+	ids := []string{}
+	for _, key := range sortedKeys(grouped) {
+		for _, result := range grouped[key] {
+			if !result.Pass {
+				ids = append(ids, result.Id)
+			}
+		}
+	}
+	return ids
+}
+
+// Merge combines the results of a re-run with those of the previous run, a re-run result replaces
+// the previous result with the same id and results of test cases not run previously are appended
+func Merge(previous, rerun map[ResultKey][]TestCase) map[ResultKey][]TestCase {
+	merged := make(map[ResultKey][]TestCase, len(previous))
+	for key, results := range previous {
+		merged[key] = append([]TestCase{}, results...)
+	}
+
+	for key, results := range rerun {
+		for _, result := range results {
+			replaced := false
+			for i, previousResult := range merged[key] {
+				if previousResult.Id == result.Id {
+					merged[key][i] = result
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				merged[key] = append(merged[key], result)
+			}
+		}
+	}
+	return merged
+}
+
+func sortedKeys(grouped map[ResultKey][]TestCase) []ResultKey {
+	keys := make([]ResultKey, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].APIName != keys[j].APIName {
+			return keys[i].APIName < keys[j].APIName
+		}
+		return keys[i].APIVersion < keys[j].APIVersion
+	})
+	return keys
+}
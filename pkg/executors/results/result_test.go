@@ -3,6 +3,8 @@ package results
 import (
 	"encoding/json"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/stretchr/testify/require"
 
@@ -54,6 +56,7 @@ func TestTestCaseResultJsonMarshal(t *testing.T) {
 	"endpoint": "endpoint",
 	"id": "123",
 	"pass": true,
+	"status": "pass",
 	"metrics": {
 		"response_time": 0,
 		"response_size": 0
@@ -69,3 +72,150 @@ func TestTestCaseResultJsonMarshal(t *testing.T) {
 
 	require.JSONEq(t, expected, string(actual))
 }
+
+func TestNewTestCaseSkipped(t *testing.T) {
+	result := NewTestCaseSkipped("OB-301-DOP-100600", "OB-301-DOP-100100", "/domestic-payments", "api-name", "api-version", "detailed description", "https://openbanking.org.uk/ref/uri")
+
+	expected := `
+{
+	"endpoint": "/domestic-payments",
+	"id": "OB-301-DOP-100600",
+	"pass": false,
+	"status": "skipped",
+	"severity": "medium",
+	"blockedBy": "OB-301-DOP-100100",
+	"metrics": {
+		"response_time": 0,
+		"response_size": 0
+	},
+	"detail": "detailed description",
+	"refURI": "https://openbanking.org.uk/ref/uri",
+	"httpStatusCode": ""
+}
+	`
+	actual, err := json.Marshal(result)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(actual))
+}
+
+func TestNewTestCaseStatuses(t *testing.T) {
+	err := errors.New("some error")
+
+	fail := NewTestCaseFail("id", NoMetrics(), []error{err}, "endpoint", "api-name", "api-version", "detail", "ref", "400")
+	require.Equal(t, StatusFail, fail.Status)
+	require.Equal(t, SeverityHigh, fail.Severity)
+
+	suiteError := NewTestCaseError("id", NoMetrics(), []error{err}, "endpoint", "api-name", "api-version", "detail", "ref", "")
+	require.Equal(t, StatusError, suiteError.Status)
+	require.False(t, suiteError.Pass)
+	require.Equal(t, []string{"some error"}, suiteError.Fail)
+
+	warning := NewTestCaseWarning("id", NoMetrics(), []error{err}, "endpoint", "api-name", "api-version", "detail", "ref", "200")
+	require.Equal(t, StatusWarning, warning.Status)
+	require.Equal(t, SeverityLow, warning.Severity)
+	require.True(t, warning.Pass)
+	require.Empty(t, warning.Fail)
+	require.Equal(t, []string{"some error"}, warning.Warnings)
+
+	notApplicable := NewTestCaseNotApplicable("id", "endpoint", "api-name", "api-version", "detail", "ref")
+	require.Equal(t, StatusNotApplicable, notApplicable.Status)
+	require.True(t, notApplicable.Pass)
+
+	pass := NewTestCaseResult("id", true, NoMetrics(), nil, "endpoint", "api-name", "api-version", "detail", "ref", "200")
+	require.Equal(t, StatusPass, pass.Status)
+	require.Empty(t, pass.Severity)
+}
+
+func TestStatusPassed(t *testing.T) {
+	passed := []Status{}
+	for _, status := range Statuses {
+		if status.Passed() {
+			passed = append(passed, status)
+		}
+	}
+	require.Equal(t, []Status{StatusPass, StatusWarning, StatusNotApplicable}, passed)
+}
+
+func TestTestCaseUnmarshalJSONWithoutStatus(t *testing.T) {
+	results := []TestCase{}
+	err := json.Unmarshal([]byte(`[{"id": "1", "pass": true}, {"id": "2", "pass": false}, {"id": "3", "pass": false, "status": "error"}]`), &results)
+	require.NoError(t, err)
+
+	require.Equal(t, StatusPass, results[0].Status)
+	require.Equal(t, StatusFail, results[1].Status)
+	require.Equal(t, StatusError, results[2].Status)
+}
+
+func TestCountByStatus(t *testing.T) {
+	grouped := map[ResultKey][]TestCase{
+		{APIName: "Payments", APIVersion: "v3.1"}: {{Id: "1", Status: StatusFail}, {Id: "2", Status: StatusSkipped}},
+		{APIName: "Accounts", APIVersion: "v3.1"}: {{Id: "3", Status: StatusPass}, {Id: "4", Status: StatusFail}},
+	}
+
+	require.Equal(t, map[Status]int{
+		StatusPass:          1,
+		StatusFail:          2,
+		StatusSkipped:       1,
+		StatusError:         0,
+		StatusWarning:       0,
+		StatusNotApplicable: 0,
+	}, CountByStatus(grouped))
+}
+
+func TestFailedIDs(t *testing.T) {
+	grouped := map[ResultKey][]TestCase{
+		{APIName: "Payments", APIVersion: "v3.1"}: {{Id: "OB-301-DOP-100100", Pass: false}, {Id: "OB-301-DOP-100200", Pass: true}},
+		{APIName: "Accounts", APIVersion: "v3.1"}: {{Id: "OB-301-ACC-100100", Pass: true}, {Id: "OB-301-ACC-120100", Pass: false}},
+	}
+
+	require.Equal(t, []string{"OB-301-ACC-120100", "OB-301-DOP-100100"}, FailedIDs(grouped))
+	require.Empty(t, FailedIDs(map[ResultKey][]TestCase{}))
+}
+
+func TestMerge(t *testing.T) {
+	accounts := ResultKey{APIName: "Accounts", APIVersion: "v3.1"}
+	payments := ResultKey{APIName: "Payments", APIVersion: "v3.1"}
+	previous := map[ResultKey][]TestCase{
+		accounts: {{Id: "OB-301-ACC-100100", Pass: true}, {Id: "OB-301-ACC-120100", Pass: false, Fail: []string{"boom"}}},
+		payments: {{Id: "OB-301-DOP-100300", Pass: true}, {Id: "OB-301-DOP-100400", Pass: false}},
+	}
+	rerun := map[ResultKey][]TestCase{
+		accounts: {{Id: "OB-301-ACC-120100", Pass: true}, {Id: "OB-301-ACC-130100", Pass: true}},
+		payments: {{Id: "OB-301-DOP-100300", Pass: true}, {Id: "OB-301-DOP-100400", Pass: false}},
+	}
+
+	merged := Merge(previous, rerun)
+
+	require.Equal(t, map[ResultKey][]TestCase{
+		accounts: {{Id: "OB-301-ACC-100100", Pass: true}, {Id: "OB-301-ACC-120100", Pass: true}, {Id: "OB-301-ACC-130100", Pass: true}},
+		payments: {{Id: "OB-301-DOP-100300", Pass: true}, {Id: "OB-301-DOP-100400", Pass: false}},
+	}, merged)
+	require.False(t, previous[accounts][1].Pass, "previous results are left untouched")
+}
+
+func TestTestCaseRedacted(t *testing.T) {
+	result := TestCase{
+		Id:             "OB-301-ACC-120100",
+		Fail:           []string{`token endpoint replied {"error":"invalid_client","client_secret":"s3cr3t"}`},
+		Warnings:       []string{"called with Bearer 2f6c5b1e"},
+		ContextRead:    []model.ContextChange{{Key: "access_token", Scope: "global", Value: "2f6c5b1e"}, {Key: "consent_id", Scope: "global", Value: "aac-1"}},
+		ContextWritten: []model.ContextChange{{Key: "signed_claims", Scope: "local", Value: "eyJhbGciOiJQUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln"}},
+	}
+
+	redacted := result.Redacted()
+
+	require.Equal(t, []string{`token endpoint replied {"error":"invalid_client","client_secret":"[redacted]"}`}, redacted.Fail)
+	require.Equal(t, []string{"called with Bearer [redacted]"}, redacted.Warnings)
+	require.Equal(t, []model.ContextChange{{Key: "access_token", Scope: "global", Value: redact.Value}, {Key: "consent_id", Scope: "global", Value: "aac-1"}}, redacted.ContextRead)
+	require.Equal(t, []model.ContextChange{{Key: "signed_claims", Scope: "local", Value: redact.Value}}, redacted.ContextWritten)
+	require.Equal(t, "2f6c5b1e", result.ContextRead[0].Value, "the result is not modified")
+	require.Nil(t, TestCase{}.Redacted().Fail)
+}
+
+func TestRedacted(t *testing.T) {
+	key := ResultKey{APIName: "Accounts", APIVersion: "v3.1"}
+	grouped := map[ResultKey][]TestCase{key: {{Id: "OB-301-ACC-120100", Fail: []string{"Bearer 2f6c5b1e"}}}}
+
+	require.Equal(t, map[ResultKey][]TestCase{key: {{Id: "OB-301-ACC-120100", Fail: []string{"Bearer [redacted]"}}}}, Redacted(grouped))
+	require.Equal(t, []string{"Bearer 2f6c5b1e"}, grouped[key][0].Fail)
+}
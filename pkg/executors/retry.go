@@ -0,0 +1,271 @@
+package executors
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// Classes of transient errors sending a request
+const (
+	ErrorTimeout           = "timeout"
+	ErrorConnectionReset   = "connection_reset"
+	ErrorConnectionRefused = "connection_refused"
+	ErrorEOF               = "eof"
+)
+
+// DefaultRetryStatusCodes are the statuses of the responses of a throttled or unavailable infrastructure
+var DefaultRetryStatusCodes = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// DefaultRetryErrors are the classes of transient errors
+var DefaultRetryErrors = []string{ErrorTimeout, ErrorConnectionReset, ErrorConnectionRefused, ErrorEOF}
+
+// RetryPolicy configures the attempts made to send the requests of the tests when the response, or the error
+// sending the request, is transient. By default requests are sent once. Non idempotent requests, POST and PATCH
+// without an `x-idempotency-key` header, are never sent again.
+type RetryPolicy struct {
+	MaxAttempts      int      `json:"max_attempts,omitempty"`       // Attempts, including the first, 1 if not set
+	Backoff          string   `json:"backoff,omitempty"`            // Wait before the second attempt, doubled for each attempt after, 500ms if not set
+	MaxBackoff       string   `json:"max_backoff,omitempty"`        // Maximum wait between attempts, 10s if not set
+	Jitter           float64  `json:"jitter,omitempty"`             // Fraction of each wait randomly added or removed, between 0 and 1
+	RetryStatusCodes []int    `json:"retry_status_codes,omitempty"` // Transient statuses, DefaultRetryStatusCodes if not set
+	RetryErrors      []string `json:"retry_errors,omitempty"`       // Transient classes of errors, DefaultRetryErrors if not set
+}
+
+// Validate checks the attempts, waits, jitter, statuses and classes of errors of the policy
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("retry policy max_attempts %d: must not be negative", p.MaxAttempts)
+	}
+	for _, duration := range []string{p.Backoff, p.MaxBackoff} {
+		if duration == "" {
+			continue
+		}
+		wait, err := time.ParseDuration(duration)
+		if err != nil {
+			return fmt.Errorf("retry policy backoff %q: %s", duration, err.Error())
+		}
+		if wait < 0 {
+			return fmt.Errorf("retry policy backoff %q: must not be negative", duration)
+		}
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry policy jitter %g: must be between 0 and 1", p.Jitter)
+	}
+	for _, code := range p.RetryStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("retry policy status code %d: not an HTTP status", code)
+		}
+	}
+	for _, class := range p.RetryErrors {
+		if !containsString(DefaultRetryErrors, class) {
+			return fmt.Errorf("retry policy error %q: not one of %s", class, strings.Join(DefaultRetryErrors, ", "))
+		}
+	}
+	return nil
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// wait returns the wait before the attempt following attempt, with jitter
+func (p RetryPolicy) wait(attempt int) time.Duration {
+	backoff := durationOr(p.Backoff, 500*time.Millisecond)
+	maxBackoff := durationOr(p.MaxBackoff, 10*time.Second)
+	wait := time.Duration(math.Min(float64(backoff)*math.Pow(2, float64(attempt-1)), float64(maxBackoff)))
+	if p.Jitter > 0 {
+		wait += time.Duration(float64(wait) * p.Jitter * (2*rand.Float64() - 1))
+	}
+	return wait
+}
+
+// retryWait returns the wait before the attempt following attempt, the wait asked by the `Retry-After` header
+// of the response when longer, up to max_backoff
+func (p RetryPolicy) retryWait(attempt int, resp *resty.Response) time.Duration {
+	wait := p.wait(attempt)
+	if resp == nil || resp.RawResponse == nil {
+		return wait
+	}
+	retryAfter, ok := parseRetryAfter(resp.Header().Get("Retry-After"), time.Now())
+	if !ok || retryAfter <= wait {
+		return wait
+	}
+	return time.Duration(math.Min(float64(retryAfter), float64(durationOr(p.MaxBackoff, 10*time.Second))))
+}
+
+// transientStatus returns true when the status of the response is transient
+func (p RetryPolicy) transientStatus(code int) bool {
+	codes := p.RetryStatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// transientError returns true when the error is of one of the transient classes of the policy
+func (p RetryPolicy) transientError(err error) bool {
+	classes := p.RetryErrors
+	if len(classes) == 0 {
+		classes = DefaultRetryErrors
+	}
+	class := ErrorClass(err)
+	return class != "" && containsString(classes, class)
+}
+
+// ErrorClass returns the class of a transient error sending a request, empty when the error is not transient
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return ErrorConnectionReset
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorConnectionRefused
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorEOF
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	}
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "connection reset"), strings.Contains(message, "broken pipe"):
+		return ErrorConnectionReset
+	case strings.Contains(message, "connection refused"):
+		return ErrorConnectionRefused
+	case strings.HasSuffix(message, "EOF"):
+		return ErrorEOF
+	}
+	return ""
+}
+
+// InfrastructureError is a transient response or error, e.g. a connection reset or a 503, which persisted after
+// the attempts of the retry policy. It is an error of the network or of the infrastructure of the implementation,
+// not a failure to conform.
+type InfrastructureError struct {
+	Attempts int
+	Cause    string
+}
+
+func (e *InfrastructureError) Error() string {
+	return fmt.Sprintf("infrastructure error after %d attempt(s): %s", e.Attempts, e.Cause)
+}
+
+// IsInfrastructureError returns true when err is, or wraps, an InfrastructureError
+func IsInfrastructureError(err error) bool {
+	var infrastructureErr *InfrastructureError
+	return errors.As(err, &infrastructureErr)
+}
+
+// retryingExecutor sends the request of a test case again while the response, or the error, is transient
+type retryingExecutor struct {
+	executor TestCaseExecutor
+	policy   RetryPolicy
+	sleep    func(time.Duration)
+}
+
+func newRetryingExecutor(executor TestCaseExecutor, policy RetryPolicy) *retryingExecutor {
+	return &retryingExecutor{executor: executor, policy: policy, sleep: time.Sleep}
+}
+
+// ExecuteTestCase executes the test case, again while the outcome is transient and the request idempotent, up to
+// the attempts of the policy. The attempts are recorded in the metrics. A transient outcome of the last attempt of a
+// request sent again is returned as an InfrastructureError, unless the test expects the status received. The outcome
+// of a request sent once, as by default, is returned unchanged so the test validates it as usual.
+func (e *retryingExecutor) ExecuteTestCase(r *resty.Request, t *model.TestCase, ctx *model.Context) (*resty.Response, results.Metrics, error) {
+	if t.DoNotCallEndpoint {
+		return e.executor.ExecuteTestCase(r, t, ctx)
+	}
+
+	req := r
+	throttled := time.Duration(0)
+	for attempt := 1; ; attempt++ {
+		resp, metrics, err := e.executor.ExecuteTestCase(req, t, ctx)
+		throttled += metrics.Throttled
+		metrics.Attempts, metrics.Throttled = attempt, throttled
+		cause := e.transientCause(resp, err, t)
+		if cause == "" {
+			return resp, metrics, err
+		}
+		if attempt >= e.policy.attempts() || !idempotent(r) {
+			if attempt == 1 {
+				return resp, metrics, err
+			}
+			return resp, metrics, &InfrastructureError{Attempts: attempt, Cause: cause}
+		}
+		e.sleep(e.policy.retryWait(attempt, resp))
+		req = reissueRequest(req)
+	}
+}
+
+// transientCause describes the transient error or status, empty when the outcome is not transient
+func (e *retryingExecutor) transientCause(resp *resty.Response, err error, t *model.TestCase) string {
+	if err != nil {
+		if e.policy.transientError(err) {
+			return err.Error()
+		}
+		return ""
+	}
+	if resp == nil || resp.RawResponse == nil || resp.StatusCode() == t.Expect.StatusCode {
+		return ""
+	}
+	if e.policy.transientStatus(resp.StatusCode()) {
+		return "status " + resp.Status()
+	}
+	return ""
+}
+
+// SetCertificates sets the certificates of the executor retried
+func (e *retryingExecutor) SetCertificates(certificateSigning, certificationTransport authentication.Certificate) error {
+	return e.executor.SetCertificates(certificateSigning, certificationTransport)
+}
+
+// idempotent returns true when the request can be sent again: neither a POST nor a PATCH, or one with an
+// idempotency key
+func idempotent(r *resty.Request) bool {
+	switch strings.ToUpper(r.Method) {
+	case http.MethodPost, http.MethodPatch:
+		return r.Header.Get("x-idempotency-key") != ""
+	}
+	return true
+}
+
+func durationOr(duration string, defaultDuration time.Duration) time.Duration {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return defaultDuration
+	}
+	return d
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
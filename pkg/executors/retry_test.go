@@ -0,0 +1,212 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+// flakyServer replies with each of the statuses in turn, repeating the last one
+func flakyServer(statuses ...int) (*httptest.Server, *int) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"Data":{}}`))
+	}))
+	return server, &calls
+}
+
+func retryTestCase(method, url string, headers map[string]string) model.TestCase {
+	return model.TestCase{
+		ID:        "OB-301-ACC-120100",
+		Input:     model.Input{Method: method, Endpoint: url, Headers: headers},
+		Expect:    model.Expect{StatusCode: http.StatusOK},
+		Validator: schema.NewNullValidator(),
+	}
+}
+
+func retryTestRunner(policy RetryPolicy) (*TestCaseRunner, *[]time.Duration) {
+	waits := []time.Duration{}
+	executor := newRetryingExecutor(requestExecutor{}, policy)
+	executor.sleep = func(wait time.Duration) { waits = append(waits, wait) }
+	runner := requestTestRunner()
+	runner.executor = executor
+	return runner, &waits
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	assert.NoError(t, RetryPolicy{}.Validate())
+	assert.NoError(t, RetryPolicy{MaxAttempts: 3, Backoff: "200ms", MaxBackoff: "2s", Jitter: 0.2, RetryStatusCodes: []int{429, 503}, RetryErrors: []string{ErrorTimeout}}.Validate())
+	assert.EqualError(t, RetryPolicy{MaxAttempts: -1}.Validate(), "retry policy max_attempts -1: must not be negative")
+	assert.EqualError(t, RetryPolicy{Backoff: "soon"}.Validate(), `retry policy backoff "soon": time: invalid duration "soon"`)
+	assert.EqualError(t, RetryPolicy{Jitter: 1.5}.Validate(), "retry policy jitter 1.5: must be between 0 and 1")
+	assert.EqualError(t, RetryPolicy{RetryStatusCodes: []int{5030}}.Validate(), "retry policy status code 5030: not an HTTP status")
+	assert.EqualError(t, RetryPolicy{RetryErrors: []string{"tls"}}.Validate(), `retry policy error "tls": not one of timeout, connection_reset, connection_refused, eof`)
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	policy := RetryPolicy{Backoff: "100ms", MaxBackoff: "300ms"}
+	assert.Equal(t, 100*time.Millisecond, policy.wait(1))
+	assert.Equal(t, 200*time.Millisecond, policy.wait(2))
+	assert.Equal(t, 300*time.Millisecond, policy.wait(3))
+	assert.Equal(t, 500*time.Millisecond, RetryPolicy{}.wait(1))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		wait := policy.wait(1)
+		assert.True(t, wait >= 50*time.Millisecond && wait <= 150*time.Millisecond, wait)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorClass(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://ob19-rs1.o3bank.co.uk:4501", Err: err}
+	}
+	assert.Equal(t, ErrorConnectionReset, ErrorClass(urlError(syscall.ECONNRESET)))
+	assert.Equal(t, ErrorConnectionRefused, ErrorClass(urlError(syscall.ECONNREFUSED)))
+	assert.Equal(t, ErrorEOF, ErrorClass(urlError(errors.New("unexpected EOF"))))
+	assert.Equal(t, ErrorTimeout, ErrorClass(urlError(timeoutError{})))
+	assert.Equal(t, ErrorTimeout, ErrorClass(context.DeadlineExceeded))
+	assert.Equal(t, "", ErrorClass(urlError(errors.New("x509: certificate signed by unknown authority"))))
+	assert.Equal(t, "", ErrorClass(nil))
+}
+
+func TestRetryingExecutorRetriesTransientStatus(t *testing.T) {
+	server, calls := flakyServer(http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	defer server.Close()
+	runner, waits := retryTestRunner(RetryPolicy{MaxAttempts: 3, Backoff: "10ms"})
+
+	result := runner.executeTest(retryTestCase(http.MethodGet, server.URL, nil), &model.Context{}, test.NullLogger())
+
+	require.True(t, result.Pass, result.Fail)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, 3, result.Metrics.Attempts)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *waits)
+}
+
+func TestRetryingExecutorReportsInfrastructureError(t *testing.T) {
+	server, calls := flakyServer(http.StatusServiceUnavailable)
+	defer server.Close()
+	runner, _ := retryTestRunner(RetryPolicy{MaxAttempts: 2})
+
+	result := runner.executeTest(retryTestCase(http.MethodGet, server.URL, nil), &model.Context{}, test.NullLogger())
+
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, results.StatusError, result.Status)
+	assert.True(t, result.Infrastructure)
+	assert.Equal(t, 2, result.Metrics.Attempts)
+	require.Len(t, result.Fail, 1)
+	assert.Contains(t, result.Fail[0], "infrastructure error after 2 attempt(s): status 503 Service Unavailable")
+}
+
+func TestRetryingExecutorDoesNotRetryNonIdempotentRequests(t *testing.T) {
+	server, calls := flakyServer(http.StatusServiceUnavailable, http.StatusOK)
+	defer server.Close()
+	runner, _ := retryTestRunner(RetryPolicy{MaxAttempts: 3})
+
+	result := runner.executeTest(retryTestCase(http.MethodPost, server.URL, nil), &model.Context{}, test.NullLogger())
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, results.StatusFail, result.Status, "a request sent once is validated as usual")
+	assert.False(t, result.Infrastructure)
+
+	server, calls = flakyServer(http.StatusServiceUnavailable, http.StatusOK)
+	defer server.Close()
+	result = runner.executeTest(retryTestCase(http.MethodPost, server.URL, map[string]string{"x-idempotency-key": "a1b2c3"}), &model.Context{}, test.NullLogger())
+
+	require.True(t, result.Pass, result.Fail)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, 2, result.Metrics.Attempts)
+}
+
+func TestRetryingExecutorRetriesTransientErrors(t *testing.T) {
+	server, _ := flakyServer(http.StatusOK)
+	server.Close() // connections are refused
+	runner, waits := retryTestRunner(RetryPolicy{MaxAttempts: 2})
+
+	result := runner.executeTest(retryTestCase(http.MethodGet, server.URL, nil), &model.Context{}, test.NullLogger())
+
+	assert.Len(t, *waits, 1)
+	assert.True(t, result.Infrastructure)
+	assert.Equal(t, results.StatusError, result.Status)
+}
+
+func TestRetryingExecutorKeepsExpectedStatus(t *testing.T) {
+	server, calls := flakyServer(http.StatusServiceUnavailable)
+	defer server.Close()
+	runner, _ := retryTestRunner(RetryPolicy{MaxAttempts: 3})
+	tc := retryTestCase(http.MethodGet, server.URL, nil)
+	tc.Expect.StatusCode = http.StatusServiceUnavailable
+
+	result := runner.executeTest(tc, &model.Context{}, test.NullLogger())
+
+	require.True(t, result.Pass, result.Fail)
+	assert.Equal(t, 1, *calls)
+	assert.False(t, result.Infrastructure)
+}
+
+func TestRetryingExecutorDefaultPolicyReportsFailures(t *testing.T) {
+	for _, status := range DefaultRetryStatusCodes {
+		server, calls := flakyServer(status)
+		runner, _ := retryTestRunner(RetryPolicy{})
+
+		result := runner.executeTest(retryTestCase(http.MethodGet, server.URL, nil), &model.Context{}, test.NullLogger())
+		server.Close()
+
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, results.StatusFail, result.Status, "status %d", status)
+		assert.False(t, result.Infrastructure)
+		assert.Equal(t, 1, result.Metrics.Attempts)
+	}
+}
+
+func TestRetryingExecutorConformanceFailure(t *testing.T) {
+	server, calls := flakyServer(http.StatusBadRequest)
+	defer server.Close()
+	runner, _ := retryTestRunner(RetryPolicy{MaxAttempts: 3})
+
+	result := runner.executeTest(retryTestCase(http.MethodGet, server.URL, nil), &model.Context{}, test.NullLogger())
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, results.StatusFail, result.Status)
+	assert.False(t, result.Infrastructure)
+}
+
+func TestIdempotent(t *testing.T) {
+	request := func(method string) *resty.Request {
+		req := resty.R()
+		req.Method = method
+		return req
+	}
+	assert.True(t, idempotent(request(http.MethodGet)))
+	assert.True(t, idempotent(request(http.MethodDelete)))
+	assert.False(t, idempotent(request(http.MethodPost)))
+	assert.False(t, idempotent(request(http.MethodPatch)))
+	assert.True(t, idempotent(request(http.MethodPost).SetHeader("x-idempotency-key", "a1b2c3")))
+}
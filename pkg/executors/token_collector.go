@@ -34,6 +34,13 @@ type TokenConsentIDItem struct {
 	AccessToken string
 	ConsentURL  string
 	Error       string
+	Consent     string // Name of a dedicated consent, empty when the consent is shared
+}
+
+// ConsentIDContextKey returns the context key holding the consentId of a dedicated consent, so that
+// testcases can refer to it, for example to check its status or to revoke it, as `$<consent>_consent_id`.
+func ConsentIDContextKey(consent string) string {
+	return consent + "_consent_id"
 }
 
 // TokenCollector - collects tokens
@@ -81,7 +88,6 @@ func (c *tokenCollector) Collect(tokenName, accessToken string) error {
 	tokenNameExists := c.tokenNameExists(tokenName)
 	logger.WithFields(logrus.Fields{
 		"tokenName":       tokenName,
-		"accessToken":     accessToken,
 		"tokenNameExists": tokenNameExists,
 	}).Debug("Collecting ...")
 	if !tokenNameExists {
@@ -0,0 +1,42 @@
+package executors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestTokenCollectorCollectsTokens(t *testing.T) {
+	done := false
+	consentIds := TokenConsentIDs{
+		{TokenName: "accountToken0001", ConsentID: "aac-1"},
+		{TokenName: "accountToken0002", ConsentID: "aac-2", Consent: "revocation"},
+	}
+	collector := NewTokenCollector(test.NullLogger(), consentIds, func() { done = true }, events.NewEvents())
+
+	require.NoError(t, collector.Collect("accountToken0002", "token-2"))
+	assert.False(t, done)
+
+	tokens := collector.Tokens()
+	assert.Empty(t, tokens[0].AccessToken)
+	assert.Equal(t, "token-2", tokens[1].AccessToken)
+	assert.Equal(t, "revocation", tokens[1].Consent)
+
+	require.NoError(t, collector.Collect("accountToken0001", "token-1"))
+	assert.True(t, done)
+	assert.Equal(t, "token-1", collector.Tokens()[0].AccessToken)
+}
+
+func TestTokenCollectorRejectsUnknownToken(t *testing.T) {
+	collector := NewTokenCollector(test.NullLogger(), TokenConsentIDs{{TokenName: "accountToken0001"}}, nil, events.NewEvents())
+
+	assert.EqualError(t, collector.Collect("accountToken0009", "token"), "invalid token name: accountToken0009")
+}
+
+func TestConsentIDContextKey(t *testing.T) {
+	assert.Equal(t, "revocation_consent_id", ConsentIDContextKey("revocation"))
+}
@@ -26,6 +26,7 @@ type GeneratorConfig struct {
 	AuthorizationEndpoint string
 	RedirectURL           string
 	ResourceIDs           model.ResourceIDs
+	Selection             manifest.Selection // Narrows the tests generated
 }
 
 // Generator - generates test cases from discovery model
@@ -110,6 +111,7 @@ func (g generator) GenerateManifestTests(log *logrus.Entry, config GeneratorConf
 			ManifestPath: item.APISpecification.Manifest,
 			Validator:    validator,
 			Conditional:  conditionalProperties,
+			Selection:    config.Selection,
 		}
 		tcs, fsc, err := manifest.GenerateTestCases(&params)
 
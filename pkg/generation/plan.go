@@ -0,0 +1,210 @@
+package generation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// ExecutionPlan is the graph of the test cases of a run: an edge goes from the test case putting a value in
+// context, with `keepContextOnSuccess`, to each test case using it. Test cases are listed in run order along
+// with the tokens they are run with.
+type ExecutionPlan struct {
+	Nodes   []PlanNode  `json:"nodes"`
+	Edges   []PlanEdge  `json:"edges"`
+	Tokens  []PlanToken `json:"tokens"`
+	Failed  string      `json:"failed,omitempty"`  // Test case assumed to fail, see WithFailure
+	Skipped []string    `json:"skipped,omitempty"` // Test cases which cannot run when Failed fails
+}
+
+// PlanNode is a test case of the plan
+type PlanNode struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	APIName  string   `json:"apiName"`
+	Produces []string `json:"produces,omitempty"` // Context values put by the test case
+	Consumes []string `json:"consumes,omitempty"` // Context values put by other test cases used by the test case
+	Token    string   `json:"token,omitempty"`    // Name of the token the test case is run with
+}
+
+// PlanEdge is a context value put by a test case and used by another
+type PlanEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Context string `json:"context"`
+}
+
+// PlanToken is a token acquired before the run, for the permissions grouped by the permissions resolver
+type PlanToken struct {
+	Name        string   `json:"name"`
+	Scope       string   `json:"scope"` // accounts, payments, cbpii or vrps
+	Permissions []string `json:"permissions,omitempty"`
+	Consent     string   `json:"consent,omitempty"` // Name of the dedicated consent, if any
+	TestIDs     []string `json:"testIds"`
+}
+
+// NewExecutionPlan builds the plan of the test cases generated in run, run with the tokens required
+func NewExecutionPlan(run SpecRun, tokens map[string][]manifest.RequiredTokens) ExecutionPlan {
+	plan := ExecutionPlan{Nodes: []PlanNode{}, Edges: []PlanEdge{}, Tokens: []PlanToken{}}
+
+	testToken := map[string]string{}
+	scopes := make([]string, 0, len(tokens))
+	for scope := range tokens {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	for _, scope := range scopes {
+		for _, token := range tokens[scope] {
+			plan.Tokens = append(plan.Tokens, PlanToken{
+				Name:        token.Name,
+				Scope:       scope,
+				Permissions: token.Perms,
+				Consent:     token.Consent,
+				TestIDs:     token.IDs,
+			})
+			for _, id := range token.IDs {
+				testToken[id] = token.Name
+			}
+		}
+	}
+
+	testCases := []model.TestCase{}
+	for _, spec := range run.SpecTestCases {
+		testCases = append(testCases, spec.TestCases...)
+	}
+
+	// producers lists, for each context value, the positions of the test cases putting it
+	producers := map[string][]int{}
+	for i, tc := range testCases {
+		for _, name := range tc.ContextPuts() {
+			producers[name] = append(producers[name], i)
+		}
+	}
+
+	for i, tc := range testCases {
+		node := PlanNode{ID: tc.ID, Name: tc.Name, APIName: tc.APIName, Produces: tc.ContextPuts(), Token: testToken[tc.ID]}
+		for _, name := range tc.ContextReferences() {
+			positions, exists := producers[name]
+			if !exists {
+				continue
+			}
+			from := producer(positions, i)
+			if from == i {
+				continue
+			}
+			node.Consumes = append(node.Consumes, name)
+			plan.Edges = append(plan.Edges, PlanEdge{From: testCases[from].ID, To: tc.ID, Context: name})
+		}
+		plan.Nodes = append(plan.Nodes, node)
+	}
+	return plan
+}
+
+// producer returns the position of the last producer running before the consumer at position, or of the
+// first producer when they all run later
+func producer(positions []int, position int) int {
+	found := positions[0]
+	for _, p := range positions {
+		if p < position {
+			found = p
+		}
+	}
+	return found
+}
+
+// SkippedIfFails lists, in run order, the test cases which cannot run when the test case id fails: those using
+// a context value it puts, and the test cases depending on them in turn
+func (p ExecutionPlan) SkippedIfFails(id string) []string {
+	skipped := map[string]bool{}
+	for added := true; added; {
+		added = false
+		for _, edge := range p.Edges {
+			if (edge.From == id || skipped[edge.From]) && !skipped[edge.To] && edge.To != id {
+				skipped[edge.To] = true
+				added = true
+			}
+		}
+	}
+
+	ids := []string{}
+	for _, node := range p.Nodes {
+		if skipped[node.ID] && !contains(ids, node.ID) {
+			ids = append(ids, node.ID)
+		}
+	}
+	return ids
+}
+
+// WithFailure returns the plan assuming the test case id fails, with the test cases skipped as a result
+func (p ExecutionPlan) WithFailure(id string) (ExecutionPlan, error) {
+	for _, node := range p.Nodes {
+		if node.ID == id {
+			p.Failed = id
+			p.Skipped = p.SkippedIfFails(id)
+			return p, nil
+		}
+	}
+	return ExecutionPlan{}, fmt.Errorf("test case %s is not in the execution plan", id)
+}
+
+// DOT renders the plan in the Graphviz DOT language. Test cases run with the same token are grouped in a
+// cluster, edges are labelled with the context value. The failed test case is drawn in red and the test cases
+// it skips in grey.
+func (p ExecutionPlan) DOT() string {
+	skipped := map[string]bool{}
+	for _, id := range p.Skipped {
+		skipped[id] = true
+	}
+	tokens := map[string]bool{}
+	for _, token := range p.Tokens {
+		tokens[token.Name] = true
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintln(b, `digraph "execution plan" {`)
+	fmt.Fprintln(b, "  rankdir=LR;")
+	fmt.Fprintln(b, "  node [shape=box];")
+
+	writeNode := func(indent string, node PlanNode) {
+		attributes := fmt.Sprintf("tooltip=%q", node.Name)
+		if node.ID == p.Failed {
+			attributes += ", style=filled, fillcolor=red"
+		} else if skipped[node.ID] {
+			attributes += ", style=filled, fillcolor=grey"
+		}
+		fmt.Fprintf(b, "%s%q [%s];\n", indent, node.ID, attributes)
+	}
+
+	for _, token := range p.Tokens {
+		fmt.Fprintf(b, "  subgraph %q {\n", "cluster_"+token.Name)
+		fmt.Fprintf(b, "    label=%q;\n", token.Name+" ("+token.Scope+")")
+		for _, node := range p.Nodes {
+			if node.Token == token.Name {
+				writeNode("    ", node)
+			}
+		}
+		fmt.Fprintln(b, "  }")
+	}
+	for _, node := range p.Nodes {
+		if !tokens[node.Token] {
+			writeNode("  ", node)
+		}
+	}
+	for _, edge := range p.Edges {
+		fmt.Fprintf(b, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Context)
+	}
+	fmt.Fprintln(b, "}")
+	return b.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
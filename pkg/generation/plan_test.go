@@ -0,0 +1,125 @@
+package generation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+func testPlanRun() SpecRun {
+	consent := model.TestCase{
+		ID:     "OB-301-DOP-100100",
+		Name:   "Domestic Payment consents is AwaitingAuthorisation",
+		Input:  model.Input{Method: "POST", Endpoint: "/domestic-payment-consents"},
+		Expect: model.Expect{ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "OB-301-DOP-100100-ConsentId", JSON: "Data.ConsentId"}}}},
+	}
+	getConsent := model.TestCase{
+		ID:      "OB-301-DOP-100200",
+		Name:    "Domestic Payment consent can be retrieved",
+		Input:   model.Input{Method: "GET", Endpoint: "/domestic-payment-consents/$consentId"},
+		Context: model.Context{"consentId": "$OB-301-DOP-100100-ConsentId"},
+	}
+	payment := model.TestCase{
+		ID:     "OB-301-DOP-100600",
+		Name:   "Domestic Payment succeeds",
+		Input:  model.Input{Method: "POST", Endpoint: "/domestic-payments", RequestBody: `{"Data": {"ConsentId": "$OB-301-DOP-100100-ConsentId"}}`},
+		Bearer: "$paymentToken0001",
+		Expect: model.Expect{ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "OB-301-DOP-100600-DomesticPaymentId", JSON: "Data.DomesticPaymentId"}}}},
+	}
+	getPayment := model.TestCase{
+		ID:    "OB-301-DOP-100700",
+		Name:  "Domestic Payment can be retrieved",
+		Input: model.Input{Method: "GET", Endpoint: "/domestic-payments/$OB-301-DOP-100600-DomesticPaymentId"},
+	}
+	accounts := model.TestCase{
+		ID:    "OB-301-ACC-100000",
+		Name:  "Accounts",
+		Input: model.Input{Method: "GET", Endpoint: "/accounts/$consentedAccountId"},
+	}
+	return SpecRun{SpecTestCases: []SpecificationTestCases{
+		{TestCases: []model.TestCase{accounts}},
+		{TestCases: []model.TestCase{consent, getConsent, payment, getPayment}},
+	}}
+}
+
+func testPlanTokens() map[string][]manifest.RequiredTokens {
+	return map[string][]manifest.RequiredTokens{
+		"payments": {{Name: "paymentToken0001", IDs: []string{"OB-301-DOP-100600", "OB-301-DOP-100700"}}},
+		"accounts": {{Name: "accountToken0001", IDs: []string{"OB-301-ACC-100000"}, Perms: []string{"ReadAccountsBasic"}}},
+	}
+}
+
+func TestNewExecutionPlan(t *testing.T) {
+	plan := NewExecutionPlan(testPlanRun(), testPlanTokens())
+
+	require.Len(t, plan.Nodes, 5)
+	assert.Equal(t, PlanNode{ID: "OB-301-ACC-100000", Name: "Accounts", Token: "accountToken0001"}, plan.Nodes[0])
+	assert.Equal(t, []string{"OB-301-DOP-100100-ConsentId"}, plan.Nodes[1].Produces)
+	assert.Equal(t, []string{"OB-301-DOP-100100-ConsentId"}, plan.Nodes[2].Consumes)
+	assert.Equal(t, "paymentToken0001", plan.Nodes[3].Token)
+
+	assert.Equal(t, []PlanEdge{
+		{From: "OB-301-DOP-100100", To: "OB-301-DOP-100200", Context: "OB-301-DOP-100100-ConsentId"},
+		{From: "OB-301-DOP-100100", To: "OB-301-DOP-100600", Context: "OB-301-DOP-100100-ConsentId"},
+		{From: "OB-301-DOP-100600", To: "OB-301-DOP-100700", Context: "OB-301-DOP-100600-DomesticPaymentId"},
+	}, plan.Edges)
+
+	assert.Equal(t, []PlanToken{
+		{Name: "accountToken0001", Scope: "accounts", Permissions: []string{"ReadAccountsBasic"}, TestIDs: []string{"OB-301-ACC-100000"}},
+		{Name: "paymentToken0001", Scope: "payments", TestIDs: []string{"OB-301-DOP-100600", "OB-301-DOP-100700"}},
+	}, plan.Tokens)
+}
+
+func TestExecutionPlanUsesLastProducerRunningBefore(t *testing.T) {
+	run := SpecRun{SpecTestCases: []SpecificationTestCases{{TestCases: []model.TestCase{
+		{ID: "A", Expect: model.Expect{ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "id"}}}}},
+		{ID: "B", ExpectOneOf: []model.Expect{{ContextPut: model.ContextAccessor{Matches: []model.Match{{ContextName: "id"}}}}}},
+		{ID: "C", Input: model.Input{Headers: map[string]string{"x-id": "$id"}}},
+	}}}}
+
+	plan := NewExecutionPlan(run, nil)
+
+	assert.Equal(t, []PlanEdge{{From: "B", To: "C", Context: "id"}}, plan.Edges)
+}
+
+func TestExecutionPlanSkippedIfFails(t *testing.T) {
+	plan := NewExecutionPlan(testPlanRun(), testPlanTokens())
+
+	assert.Equal(t, []string{"OB-301-DOP-100200", "OB-301-DOP-100600", "OB-301-DOP-100700"}, plan.SkippedIfFails("OB-301-DOP-100100"))
+	assert.Equal(t, []string{"OB-301-DOP-100700"}, plan.SkippedIfFails("OB-301-DOP-100600"))
+	assert.Empty(t, plan.SkippedIfFails("OB-301-ACC-100000"))
+}
+
+func TestExecutionPlanWithFailure(t *testing.T) {
+	plan := NewExecutionPlan(testPlanRun(), testPlanTokens())
+
+	failed, err := plan.WithFailure("OB-301-DOP-100600")
+	require.NoError(t, err)
+	assert.Equal(t, "OB-301-DOP-100600", failed.Failed)
+	assert.Equal(t, []string{"OB-301-DOP-100700"}, failed.Skipped)
+
+	_, err = plan.WithFailure("OB-301-XXX-000000")
+	assert.EqualError(t, err, "test case OB-301-XXX-000000 is not in the execution plan")
+}
+
+func TestExecutionPlanDOT(t *testing.T) {
+	plan, err := NewExecutionPlan(testPlanRun(), testPlanTokens()).WithFailure("OB-301-DOP-100600")
+	require.NoError(t, err)
+
+	dot := plan.DOT()
+
+	assert.True(t, strings.HasPrefix(dot, `digraph "execution plan" {`))
+	assert.Contains(t, dot, `subgraph "cluster_paymentToken0001" {
+    label="paymentToken0001 (payments)";
+    "OB-301-DOP-100600" [tooltip="Domestic Payment succeeds", style=filled, fillcolor=red];
+    "OB-301-DOP-100700" [tooltip="Domestic Payment can be retrieved", style=filled, fillcolor=grey];
+  }`)
+	assert.Contains(t, dot, `  "OB-301-DOP-100100" [tooltip="Domestic Payment consents is AwaitingAuthorisation"];`)
+	assert.Contains(t, dot, `  "OB-301-DOP-100100" -> "OB-301-DOP-100600" [label="OB-301-DOP-100100-ConsentId"];`)
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+}
@@ -0,0 +1,200 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const draft04 = "http://json-schema.org/draft-04/schema#"
+
+// Generate returns the JSON Schema of the documents unmarshalled into v, with the title and id given.
+// Structs describe the properties of their json tagged fields and allow others, as encoding/json ignores
+// them, so files with keys of earlier versions still load. Named structs are put in `definitions` and
+// referenced with `$ref`, next to the description of the field.
+// Descriptions are looked up in descriptions, see ParseDescriptions. No property is required, required
+// values are checked once unmarshalled, e.g. by discovery.Validate. A `$schema` property is allowed at the
+// root so editors can find the schema.
+func Generate(id, title string, v interface{}, descriptions map[string]string) ([]byte, error) {
+	g := generator{definitions: map[string]map[string]interface{}{}, names: map[reflect.Type]string{}, descriptions: descriptions}
+	root, err := g.structSchema(indirect(reflect.TypeOf(v)))
+	if err != nil {
+		return nil, err
+	}
+	root["properties"].(map[string]interface{})["$schema"] = map[string]interface{}{
+		"type":        "string",
+		"description": "JSON Schema of the document",
+	}
+
+	schema := map[string]interface{}{
+		"$schema":    draft04,
+		"id":         id,
+		"title":      title,
+		"type":       root["type"],
+		"properties": root["properties"],
+	}
+	if len(g.definitions) > 0 {
+		schema["definitions"] = g.definitions
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type generator struct {
+	definitions  map[string]map[string]interface{}
+	names        map[reflect.Type]string
+	descriptions map[string]string
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func (g generator) schema(t reflect.Type) (map[string]interface{}, error) {
+	t = indirect(t)
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("jsonschema: map key of %s is not a string", t)
+		}
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return g.definition(t)
+	}
+	return nil, fmt.Errorf("jsonschema: unsupported type %s", t)
+}
+
+// definition puts the schema of the named struct in the definitions and returns a reference to it
+func (g generator) definition(t reflect.Type) (map[string]interface{}, error) {
+	name, exists := g.names[t]
+	if !exists {
+		name = t.Name()
+		if _, taken := g.definitions[name]; taken || name == "" {
+			name = strings.Replace(t.String(), ".", "_", -1)
+		}
+		g.names[t] = name
+		g.definitions[name] = nil // placeholder, stops recursive types
+		schema, err := g.structSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		g.definitions[name] = schema
+	}
+	return map[string]interface{}{"$ref": "#/definitions/" + name}, nil
+}
+
+func (g generator) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	if err := g.addFields(t, properties); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}, nil
+}
+
+// addFields adds the fields of t, and of its embedded structs, the way encoding/json marshals them
+func (g generator) addFields(t reflect.Type, properties map[string]interface{}) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			if err := g.addFields(indirect(field.Type), properties); err != nil {
+				return err
+			}
+			continue
+		}
+		// unexported fields are only kept when tagged, they are read from the file by the web UI
+		if field.PkgPath != "" && name == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, err := g.schema(field.Type)
+		if err != nil {
+			return err
+		}
+		if description, exists := g.descriptions[t.String()+"."+field.Name]; exists {
+			schema["description"] = description
+		}
+		properties[name] = schema
+	}
+	return nil
+}
+
+// ParseDescriptions reads the comments of the struct fields of the Go package in dir, keyed by
+// `package.Type.Field` as in the reflect type names. Line comments are preferred over doc comments.
+func ParseDescriptions(dir string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := map[string]string{}
+	for pkgName, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				st, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					return false
+				}
+				for _, field := range st.Fields.List {
+					text := strings.TrimSpace(field.Comment.Text())
+					if text == "" {
+						text = strings.TrimSpace(field.Doc.Text())
+					}
+					if text == "" {
+						continue
+					}
+					for _, name := range field.Names {
+						descriptions[pkgName+"."+spec.Name.Name+"."+name.Name] = strings.Join(strings.Fields(text), " ")
+					}
+				}
+				return false
+			})
+		}
+	}
+	return descriptions, nil
+}
@@ -0,0 +1,70 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type generatedItem struct {
+	Name    string            `json:"name,omitempty"`
+	Count   int               `json:"count"`
+	Tags    []string          `json:"tags,omitempty"`
+	Values  map[string]string `json:"values,omitempty"`
+	Any     interface{}       `json:"any,omitempty"`
+	Ignored string            `json:"-"`
+	private string
+}
+
+type generatedDocument struct {
+	Items []generatedItem `json:"items"`
+	Item  *generatedItem  `json:"item,omitempty"`
+}
+
+func TestGenerate(t *testing.T) {
+	descriptions := map[string]string{"jsonschema.generatedDocument.Items": "The items"}
+	raw, err := Generate(BaseID+"test.json", "Test", generatedDocument{}, descriptions)
+	require.NoError(t, err)
+
+	expected := `{
+	  "$schema": "http://json-schema.org/draft-04/schema#",
+	  "id": "https://github.com/OpenBankingUK/conformance-suite/schemas/v1/test.json",
+	  "title": "Test",
+	  "type": "object",
+	  "properties": {
+	    "$schema": { "type": "string", "description": "JSON Schema of the document" },
+	    "items": { "type": "array", "items": { "$ref": "#/definitions/generatedItem" }, "description": "The items" },
+	    "item": { "$ref": "#/definitions/generatedItem" }
+	  },
+	  "definitions": {
+	    "generatedItem": {
+	      "type": "object",
+	      "properties": {
+	        "name": { "type": "string" },
+	        "count": { "type": "integer" },
+	        "tags": { "type": "array", "items": { "type": "string" } },
+	        "values": { "type": "object", "additionalProperties": { "type": "string" } },
+	        "any": {}
+	      }
+	    }
+	  }
+	}`
+	assert.JSONEq(t, expected, string(raw))
+}
+
+func TestGenerateUnsupportedType(t *testing.T) {
+	_, err := Generate("", "", struct {
+		Channel chan int `json:"channel"`
+	}{}, nil)
+	assert.Error(t, err)
+}
+
+func TestParseDescriptions(t *testing.T) {
+	descriptions, err := ParseDescriptions(".")
+	require.NoError(t, err)
+
+	assert.Equal(t, "What does not match", descriptions["jsonschema.ValidationError.Message"])
+	_, exists := descriptions["jsonschema.generatedItem.Name"]
+	assert.False(t, exists, "test files are not parsed")
+}
@@ -0,0 +1,48 @@
+// Package generator generates the JSON Schemas of package jsonschema from the Go types of the files they describe
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+)
+
+// documents are the types the files described by each schema are unmarshalled into
+var documents = []struct {
+	name  string
+	title string
+	value interface{}
+}{
+	{jsonschema.Manifest, "Functional Conformance Suite manifest", manifest.Scripts{}},
+	{jsonschema.Assertions, "Functional Conformance Suite assertions and data", manifest.References{}},
+	{jsonschema.Discovery, "Functional Conformance Suite discovery model", discovery.Model{}},
+}
+
+// describedPackages are the directories, relative to the repository root, of the packages the documents use
+var describedPackages = []string{"pkg/discovery", "pkg/manifest", "pkg/model"}
+
+// Schemas generates the JSON Schemas, by name, with the field descriptions of the sources found under root
+func Schemas(root string) (map[string][]byte, error) {
+	descriptions := map[string]string{}
+	for _, dir := range describedPackages {
+		pkgDescriptions, err := jsonschema.ParseDescriptions(filepath.Join(root, dir))
+		if err != nil {
+			return nil, err
+		}
+		for key, description := range pkgDescriptions {
+			descriptions[key] = description
+		}
+	}
+
+	schemas := map[string][]byte{}
+	for _, document := range documents {
+		schema, err := jsonschema.Generate(jsonschema.BaseID+document.name+".json", document.title, document.value, descriptions)
+		if err != nil {
+			return nil, err
+		}
+		schemas[document.name] = append(schema, '\n')
+	}
+	return schemas, nil
+}
@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
+)
+
+// TestSchemasAreUpToDate fails when the Go types changed without running `go generate ./pkg/jsonschema`
+func TestSchemasAreUpToDate(t *testing.T) {
+	schemas, err := Schemas("../../..")
+	require.NoError(t, err)
+
+	for _, name := range jsonschema.Names {
+		shipped, err := jsonschema.Schema(name)
+		require.NoError(t, err)
+		assert.Equal(t, string(schemas[name]), string(shipped), "%s schema is not up to date, run go generate ./pkg/jsonschema", name)
+	}
+}
+
+func TestShippedFilesValidate(t *testing.T) {
+	for name, pattern := range map[string]string{
+		jsonschema.Manifest:   "../../../manifests/ob_*.json",
+		jsonschema.Assertions: "../../../manifests/[ad]*.json",
+		jsonschema.Discovery:  "../../discovery/templates/*.json",
+	} {
+		files, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		require.NotEmpty(t, files)
+
+		for _, file := range files {
+			document, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			assert.NoError(t, jsonschema.Validate(name, document), file)
+		}
+	}
+}
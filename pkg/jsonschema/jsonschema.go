@@ -0,0 +1,334 @@
+// Package jsonschema ships the JSON Schemas of the files written by users: manifests, assertions and
+// discovery models. They are generated from the Go types, see cmd/jsonschema, and validate the files on load.
+package jsonschema
+
+//go:generate go run ../../cmd/jsonschema -root ../.. -out v1
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+)
+
+// Version of the schemas, part of their id and of the path they are published at
+const Version = "v1"
+
+// Names of the schemas
+const (
+	Manifest   = "manifest"
+	Assertions = "assertions"
+	Discovery  = "discovery"
+)
+
+// Names lists the schemas shipped
+var Names = []string{Manifest, Assertions, Discovery}
+
+// BaseID is the id of the schemas, without their file name
+const BaseID = "https://github.com/OpenBankingUK/conformance-suite/schemas/" + Version + "/"
+
+//go:embed v1/*.json
+var schemas embed.FS
+
+// Schema returns the JSON Schema called name
+func Schema(name string) ([]byte, error) {
+	schema, err := schemas.ReadFile(Version + "/" + name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("unknown json schema %q", name)
+	}
+	return schema, nil
+}
+
+// ValidationError is a document value not matching the schema
+type ValidationError struct {
+	Path    string `json:"path"`    // Dot separated path of the value, e.g. scripts.3.method
+	Line    int    `json:"line"`    // Line of the value in the document, starting at 1
+	Message string `json:"message"` // What does not match
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ValidationErrors are all the values of a document not matching the schema
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return "json schema validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validate checks the JSON document against the schema called name. Values which do not match are
+// returned as ValidationErrors, sorted by line. Like encoding/json, keys match properties ignoring case.
+func Validate(name string, document []byte) error {
+	schema, err := compile(name)
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(document, &data); err != nil {
+		return syntaxError(document, err)
+	}
+
+	err = schema.VisitJSON(matchKeys(data, schema), openapi3.MultiErrors())
+	if err == nil {
+		return nil
+	}
+	validationErrors := ValidationErrors{}
+	for _, err := range flatten(err) {
+		validationErrors = append(validationErrors, validationError(document, err))
+	}
+	sort.SliceStable(validationErrors, func(i, j int) bool { return validationErrors[i].Line < validationErrors[j].Line })
+	return validationErrors
+}
+
+// Inline is a JSON Schema written within a document, such as the schema of a match in an assertion. It is
+// validated with the same engine as the shipped schemas.
+type Inline struct {
+	schema *openapi3.Schema
+}
+
+// CompileInline returns the inline schema, decoded from JSON
+func CompileInline(schema interface{}) (*Inline, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "schema")
+	}
+	compiled := &openapi3.Schema{}
+	if err := json.Unmarshal(raw, compiled); err != nil {
+		return nil, errors.Wrap(err, "schema")
+	}
+	return &Inline{schema: compiled}, nil
+}
+
+// Validate checks value, decoded from JSON, against the inline schema. The values which do not match are
+// returned as a single error.
+func (s *Inline) Validate(value interface{}) error {
+	err := s.schema.VisitJSON(value, openapi3.MultiErrors())
+	if err == nil {
+		return nil
+	}
+	messages := []string{}
+	for _, err := range flatten(err) {
+		if e, ok := err.(*openapi3.SchemaError); ok {
+			_, message := schemaErrorMessage(e)
+			messages = append(messages, message)
+			continue
+		}
+		messages = append(messages, err.Error())
+	}
+	return errors.New(strings.Join(messages, ", "))
+}
+
+// compile loads the schema called name with its definitions inlined, references to definitions are
+// only resolved within OpenAPI components by openapi3
+func compile(name string) (*openapi3.Schema, error) {
+	raw, err := Schema(name)
+	if err != nil {
+		return nil, err
+	}
+	document := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, errors.Wrapf(err, "json schema %s", name)
+	}
+	definitions, _ := document["definitions"].(map[string]interface{})
+	for _, key := range []string{"$schema", "id", "definitions"} {
+		delete(document, key)
+	}
+
+	inlined, err := json.Marshal(inline(document, definitions))
+	if err != nil {
+		return nil, errors.Wrapf(err, "json schema %s", name)
+	}
+	schema := &openapi3.Schema{}
+	if err := json.Unmarshal(inlined, schema); err != nil {
+		return nil, errors.Wrapf(err, "json schema %s", name)
+	}
+	return schema, nil
+}
+
+func inline(value interface{}, definitions map[string]interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		inlined := map[string]interface{}{}
+		if ref, ok := value["$ref"].(string); ok {
+			inlined = inline(definitions[strings.TrimPrefix(ref, "#/definitions/")], definitions).(map[string]interface{})
+		}
+		for key, v := range value {
+			if key != "$ref" {
+				inlined[key] = inline(v, definitions)
+			}
+		}
+		return inlined
+	case []interface{}:
+		inlined := make([]interface{}, 0, len(value))
+		for _, v := range value {
+			inlined = append(inlined, inline(v, definitions))
+		}
+		return inlined
+	}
+	return value
+}
+
+// matchKeys renames the keys of the objects in data matching a property of the schema ignoring case
+func matchKeys(data interface{}, schema *openapi3.Schema) interface{} {
+	if schema == nil {
+		return data
+	}
+	switch data := data.(type) {
+	case map[string]interface{}:
+		matched := map[string]interface{}{}
+		for key, value := range data {
+			name := key
+			if _, exists := schema.Properties[key]; !exists {
+				for property := range schema.Properties {
+					if _, taken := data[property]; !taken && strings.EqualFold(property, key) {
+						name = property
+					}
+				}
+			}
+			if property, exists := schema.Properties[name]; exists {
+				matched[name] = matchKeys(value, property.Value)
+			} else if schema.AdditionalProperties != nil {
+				matched[name] = matchKeys(value, schema.AdditionalProperties.Value)
+			} else {
+				matched[name] = value
+			}
+		}
+		return matched
+	case []interface{}:
+		if schema.Items == nil {
+			return data
+		}
+		matched := make([]interface{}, 0, len(data))
+		for _, value := range data {
+			matched = append(matched, matchKeys(value, schema.Items.Value))
+		}
+		return matched
+	}
+	return data
+}
+
+func flatten(err error) []error {
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+	errs := []error{}
+	for _, e := range multi {
+		errs = append(errs, flatten(e)...)
+	}
+	return errs
+}
+
+func validationError(document []byte, err error) ValidationError {
+	e, ok := err.(*openapi3.SchemaError)
+	if !ok {
+		return ValidationError{Line: 1, Message: err.Error()}
+	}
+	pointer, message := schemaErrorMessage(e)
+	return ValidationError{Path: strings.Join(pointer, "."), Line: lineOf(document, pointer), Message: message}
+}
+
+// schemaErrorMessage returns the path of the value not matching and the reason prefixed by the dot separated path
+func schemaErrorMessage(e *openapi3.SchemaError) ([]string, string) {
+	pointer := e.JSONPointer()
+	path := strings.Join(pointer, ".")
+	if path == "" {
+		return pointer, e.Reason
+	}
+	return pointer, path + ": " + e.Reason
+}
+
+// syntaxError reports the line of the invalid JSON, offsets of encoding/json errors are after the faulty character
+func syntaxError(document []byte, err error) error {
+	if e, ok := err.(*json.SyntaxError); ok {
+		return ValidationErrors{{Line: lineAt(document, int(e.Offset)-1), Message: e.Error()}}
+	}
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		return ValidationErrors{{Path: e.Field, Line: lineAt(document, int(e.Offset)-1), Message: e.Error()}}
+	}
+	return err
+}
+
+// lineOf returns the line of the value at path in the document, or of its closest parent found
+func lineOf(document []byte, path []string) int {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	return lineAt(document, locate(decoder, path))
+}
+
+// locate reads the value next in decoder and returns the offset of the value at path within it
+func locate(decoder *json.Decoder, path []string) int {
+	start := int(decoder.InputOffset())
+	if len(path) == 0 {
+		return start
+	}
+	token, err := decoder.Token()
+	if err != nil {
+		return start
+	}
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return start
+			}
+			if key, _ := token.(string); strings.EqualFold(key, path[0]) {
+				if len(path) == 1 {
+					return int(decoder.InputOffset())
+				}
+				return locate(decoder, path[1:])
+			}
+			if err := skip(decoder); err != nil {
+				return start
+			}
+		}
+	case json.Delim('['):
+		index, err := strconv.Atoi(path[0])
+		if err != nil {
+			return start
+		}
+		for i := 0; decoder.More(); i++ {
+			if i == index {
+				if len(path) == 1 {
+					return int(decoder.InputOffset())
+				}
+				return locate(decoder, path[1:])
+			}
+			if err := skip(decoder); err != nil {
+				return start
+			}
+		}
+	}
+	return start
+}
+
+func skip(decoder *json.Decoder) error {
+	var value json.RawMessage
+	return decoder.Decode(&value)
+}
+
+// lineAt returns the line of the first non blank character at or after offset
+func lineAt(document []byte, offset int) int {
+	if offset > len(document) {
+		offset = len(document)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	for offset < len(document) && strings.IndexByte(" \t\r\n,:", document[offset]) >= 0 {
+		offset++
+	}
+	return bytes.Count(document[:offset], []byte("\n")) + 1
+}
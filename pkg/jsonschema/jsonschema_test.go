@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	for _, name := range Names {
+		schema, err := Schema(name)
+		require.NoError(t, err)
+
+		document := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(schema, &document))
+		assert.Equal(t, BaseID+name+".json", document["id"])
+	}
+
+	_, err := Schema("unknown")
+	assert.EqualError(t, err, `unknown json schema "unknown"`)
+}
+
+func TestValidate(t *testing.T) {
+	manifest := `{
+  "$schema": "https://0.0.0.0:8443/api/schemas/v1/manifest.json",
+  "scripts": [
+    {
+      "id": "OB-301-ACC-000100",
+      "parameters": { "tokenRequestScope": "accounts" },
+      "asserts": ["OB3GLOAssertOn200"],
+      "keepContextOnSuccess": { "name": "accountId", "value": "Data.Account.0.AccountId" },
+      "method": "get",
+      "schemaCheck": true
+    }
+  ]
+}`
+	assert.NoError(t, Validate(Manifest, []byte(manifest)))
+}
+
+func TestValidateReportsPathAndLine(t *testing.T) {
+	manifest := `{
+  "scripts": [
+    {
+      "id": "OB-301-ACC-000100",
+      "method": "get"
+    },
+    {
+      "id": "OB-301-ACC-000200",
+      "asserts": "OB3GLOAssertOn200",
+      "schemaCheck": "yes",
+      "methd": "get"
+    }
+  ]
+}`
+	err := Validate(Manifest, []byte(manifest))
+
+	require.IsType(t, ValidationErrors{}, err)
+	errs := err.(ValidationErrors)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "scripts.1.asserts", errs[0].Path)
+	assert.Equal(t, 9, errs[0].Line)
+	assert.Equal(t, "scripts.1.schemaCheck", errs[1].Path)
+	assert.Equal(t, 10, errs[1].Line)
+}
+
+func TestValidateAllowsUnknownProperties(t *testing.T) {
+	assertions := `{
+  "references": {
+    "OB3GLOAAssertConsentId": {
+      "expect": {
+        "matches": [{ "type": "ConsentId", "JSON": "Data.ConsentId" }]
+      }
+    }
+  }
+}`
+	assert.NoError(t, Validate(Assertions, []byte(assertions)), "keys of earlier versions are ignored")
+}
+
+func TestValidateIgnoresCaseOfKeys(t *testing.T) {
+	assertions := `{
+  "references": {
+    "OB3GLOAssertConsentId": {
+      "expect": {
+        "matches": [{ "JSON": "Data.ConsentId" }]
+      }
+    }
+  }
+}`
+	assert.NoError(t, Validate(Assertions, []byte(assertions)))
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	err := Validate(Discovery, []byte("{\n  \"discoveryModel\": {\n    \"name\": \n  }\n}"))
+
+	require.IsType(t, ValidationErrors{}, err)
+	assert.Equal(t, 4, err.(ValidationErrors)[0].Line)
+}
+
+func TestValidateUnknownSchema(t *testing.T) {
+	assert.EqualError(t, Validate("unknown", []byte("{}")), `unknown json schema "unknown"`)
+}
+
+func TestCompileInline(t *testing.T) {
+	schema, err := CompileInline(map[string]interface{}{
+		"type":       "object",
+		"required":   []interface{}{"Status"},
+		"properties": map[string]interface{}{"Status": map[string]interface{}{"enum": []interface{}{"Authorised"}}},
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate(map[string]interface{}{"Status": "Authorised"}))
+	assert.EqualError(t, schema.Validate(map[string]interface{}{}), `Status: property "Status" is missing`)
+
+	_, err = CompileInline(map[string]interface{}{"type": []interface{}{"string", "null"}})
+	assert.Error(t, err, "type is a single type, as in OpenAPI 3")
+}
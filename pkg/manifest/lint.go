@@ -0,0 +1,343 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+)
+
+// Severities of the issues found by Lint
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// journeyContextNames are the context values put by the journey, from the configuration and while acquiring
+// tokens, that scripts may reference. Keep in line with the `Ctx` constants of the server package.
+var journeyContextNames = []string{
+	"acrValuesSupported",
+	"api-version",
+	"authorisation_endpoint",
+	"basic_authentication",
+	"baseurl",
+	"cbpiiDebtorAccountIdentification",
+	"cbpiiDebtorAccountName",
+	"cbpiiDebtorAccountSchemeName",
+	"client_access_token",
+	"client_id",
+	"client_secret",
+	"consentedAccountId",
+	"creditorIdentification",
+	"creditorName",
+	"creditorScheme",
+	"currencyOfTransfer",
+	"dynamicResourceIDs",
+	"firstPaymentDateTime",
+	"instructedAmountCurrency",
+	"instructedAmountValue",
+	"internationalCreditorIdentification",
+	"internationalCreditorName",
+	"internationalCreditorScheme",
+	"issuer",
+	"jwks_uri",
+	"payment_frequency",
+	"payment_frequency_count_per_period",
+	"payment_frequency_point_in_time",
+	"phase",
+	"redirect_url",
+	"requestObjectSigningAlg",
+	"requestedExecutionDateTime",
+	"resource_server",
+	"responseType",
+	"signingPrivate",
+	"signingPublic",
+	"statementId",
+	"token_endpoint",
+	"token_endpoint_auth_method",
+	"tpp_signature_issuer",
+	"tpp_signature_kid",
+	"tpp_signature_tan",
+	"transactionFromDate",
+	"transactionToDate",
+	"x-fapi-customer-ip-address",
+	"x-fapi-financial-id",
+	"x-fapi-interaction-id",
+}
+
+// contextPutKeys are the keys of `keepContextOnSuccess`
+var contextPutKeys = []string{"name", "value"}
+
+// LintIssue is a problem found in a manifest
+type LintIssue struct {
+	Manifest string `json:"manifest"`
+	ID       string `json:"id,omitempty"` // Id of the script, empty when the issue is about the whole manifest
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.ID == "" {
+		return fmt.Sprintf("%s: %s: %s", i.Manifest, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", i.Manifest, i.ID, i.Severity, i.Message)
+}
+
+// LintIssues - issues found by Lint
+type LintIssues []LintIssue
+
+// Errors is the number of issues with error severity
+func (l LintIssues) Errors() int {
+	errs := 0
+	for _, issue := range l {
+		if issue.Severity == LintError {
+			errs++
+		}
+	}
+	return errs
+}
+
+// LintDiscovery lints the manifests of every API specification of the discovery model, see Lint.
+// Manifests which cannot be loaded are reported as errors.
+func LintDiscovery(disco discovery.Model) LintIssues {
+	issues := LintIssues{}
+	for _, item := range disco.DiscoveryModel.DiscoveryItems {
+		specIssues, err := Lint(item.APISpecification)
+		if err != nil {
+			issues = append(issues, LintIssue{Manifest: item.APISpecification.Manifest, Severity: LintError, Message: err.Error()})
+			continue
+		}
+		issues = append(issues, specIssues...)
+	}
+	return issues
+}
+
+// Lint statically checks the scripts of the manifest of an API specification, loaded for its version with
+// LoadGenerationResources, without generating any test case. It reports as errors:
+// duplicated ids, asserts missing from the assertions or whose jsonpath, quantifier, bounds, schema or custom check
+// are invalid, `$fn:` macros which do not exist or are given the wrong number of parameters, `${ expression }`
+// templates which do not parse or call functions which do not exist,
+// `$name` references resolving neither to a parameter, a reference, a journey context value nor a context value
+// put by a previous script, references to context values only put by a later script and `keepContextOnSuccess`
+// with unknown or missing keys.
+// Methods and uris not defined by the OpenAPI specification are reported as warnings, since negative tests use them.
+// An error is only returned when the manifest or the assertions cannot be loaded.
+func Lint(spec discovery.ModelAPISpecification) (LintIssues, error) {
+	specType, err := GetSpecType(spec.SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	ctx := model.Context{}
+	ctx.PutStringSlice("apiversions", []string{specType + "_" + spec.Version})
+	scripts, refs, err := LoadGenerationResources(specType, spec.Manifest, &ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load the manifest")
+	}
+
+	l := linter{manifest: spec.Manifest, refs: refs, issues: LintIssues{}}
+	ops, err := schema.SpecOperations(spec.Name, spec.Version)
+	if err != nil {
+		l.add("", LintWarning, "uris not checked, cannot load the %s %s specification: %s", spec.Name, spec.Version, err.Error())
+	}
+
+	l.checkIDs(scripts)
+
+	producers := l.producers(scripts)
+	available := map[string]bool{}
+	for _, name := range journeyContextNames {
+		available[name] = true
+	}
+	// dedicated consents are all acquired before the run, see executors.ConsentIDContextKey
+	for _, s := range scripts.Scripts {
+		if s.Consent != "" {
+			available[s.Consent+"_consent_id"] = true
+		}
+	}
+	for _, s := range scripts.Scripts {
+		l.checkAsserts(s)
+		l.checkMacros(s)
+		l.checkTemplates(s)
+		l.checkReferences(s, available, producers)
+		l.checkContextPut(s)
+		if ops != nil {
+			l.checkURI(s, ops, spec)
+		}
+
+		for name := range s.Parameters {
+			available[name] = true
+		}
+		if name := s.ContextPut["name"]; name != "" {
+			available[name] = true
+		}
+	}
+
+	return l.issues, nil
+}
+
+type linter struct {
+	manifest string
+	refs     References
+	issues   LintIssues
+}
+
+func (l *linter) add(id, severity, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{Manifest: l.manifest, ID: id, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) checkIDs(scripts Scripts) {
+	seen := map[string]bool{}
+	for _, s := range scripts.Scripts {
+		if s.ID == "" {
+			l.add("", LintError, "script %q has no id", s.Description)
+			continue
+		}
+		if seen[s.ID] {
+			l.add(s.ID, LintError, "duplicate id")
+		}
+		seen[s.ID] = true
+	}
+}
+
+// producers maps the values kept in context on success to the first script keeping them
+func (l *linter) producers(scripts Scripts) map[string]string {
+	producers := map[string]string{}
+	for _, s := range scripts.Scripts {
+		if name := s.ContextPut["name"]; name != "" {
+			if _, exists := producers[name]; !exists {
+				producers[name] = s.ID
+			}
+		}
+	}
+	return producers
+}
+
+func (l *linter) checkAsserts(s Script) {
+	for _, field := range []struct {
+		name    string
+		asserts []string
+	}{
+		{"asserts", s.Asserts},
+		{"asserts_one_of", s.AssertsOneOf},
+		{"asserts_last_if_all", s.AssertsLastIfAll},
+		{"advisory_asserts", s.AdvisoryAsserts},
+	} {
+		for _, name := range field.asserts {
+			ref, exists := l.refs.References[name]
+			if !exists {
+				l.add(s.ID, LintError, "%s: assertion %s does not exist", field.name, name)
+				continue
+			}
+			for _, match := range ref.Expect.Matches {
+				if err := match.Validate(); err != nil {
+					l.add(s.ID, LintError, "%s: assertion %s: %s", field.name, name, err.Error())
+				}
+			}
+		}
+	}
+}
+
+func (l *linter) checkMacros(s Script) {
+	for _, name := range sortedKeys(s.Parameters) {
+		value := s.Parameters[name]
+		if !isFunction(value) {
+			continue
+		}
+		fnName, fnArgs, err := fnNameAndArgs(value)
+		if err != nil {
+			l.add(s.ID, LintError, "parameter %s: %s", name, err.Error())
+			continue
+		}
+		arity, found := model.MacroArity(fnName)
+		if !found {
+			l.add(s.ID, LintError, "parameter %s: macro %s does not exist", name, fnName)
+			continue
+		}
+		if arity != len(fnArgs) {
+			l.add(s.ID, LintError, "parameter %s: macro %s takes %d parameters, %d given", name, fnName, arity, len(fnArgs))
+		}
+	}
+}
+
+// checkTemplates checks the `${ expression }` templates of the uri, body, headers, query parameters and parameters
+func (l *linter) checkTemplates(s Script) {
+	values := map[string]string{"uri": s.URI, "body": s.Body}
+	for prefix, params := range map[string]map[string]string{"header": s.Headers, "query parameter": s.QueryParameters, "parameter": s.Parameters} {
+		for key, value := range params {
+			values[prefix+" "+key] = value
+		}
+	}
+
+	for _, name := range sortedKeys(values) {
+		if err := model.ValidateTemplates(values[name]); err != nil {
+			l.add(s.ID, LintError, "%s: %s", name, err.Error())
+		}
+	}
+}
+
+// checkReferences checks the `$name` references of the script, and of the reference data it uses, resolve
+func (l *linter) checkReferences(s Script, available map[string]bool, producers map[string]string) {
+	reported := map[string]bool{}
+	for _, name := range l.scriptReferences(s) {
+		if reported[name] || s.Parameters[name] != "" || available[name] {
+			continue
+		}
+		if _, exists := l.refs.References[name]; exists {
+			continue
+		}
+		reported[name] = true
+		if producer, exists := producers[name]; exists {
+			l.add(s.ID, LintError, "$%s is put in context by %s which runs later", name, producer)
+			continue
+		}
+		l.add(s.ID, LintError, "$%s does not resolve to a parameter, reference or context value", name)
+	}
+}
+
+// scriptReferences lists the names referenced by the script and the bodies of the reference data it uses
+func (l *linter) scriptReferences(s Script) []string {
+	names := []string{}
+	for _, name := range model.ContextReferences(s.referencingValues()...) {
+		names = append(names, name)
+		if ref, exists := l.refs.References[name]; exists {
+			names = append(names, model.ContextReferences(ref.getValue())...)
+		}
+	}
+	return names
+}
+
+func (l *linter) checkContextPut(s Script) {
+	if len(s.ContextPut) == 0 {
+		return
+	}
+	for _, key := range sortedKeys(s.ContextPut) {
+		if !containsFold(contextPutKeys, key) {
+			l.add(s.ID, LintError, "keepContextOnSuccess: unknown key %s, expected %s", key, strings.Join(contextPutKeys, " and "))
+		}
+	}
+	for _, key := range contextPutKeys {
+		if s.ContextPut[key] == "" {
+			l.add(s.ID, LintError, "keepContextOnSuccess: missing %s", key)
+		}
+	}
+}
+
+func (l *linter) checkURI(s Script, ops schema.Operations, spec discovery.ModelAPISpecification) {
+	uri := strings.SplitN(s.URI, "?", 2)[0]
+	if _, found := ops.Find(s.Method, uri); !found {
+		l.add(s.ID, LintWarning, "%s %s is not defined by the %s %s specification", strings.ToUpper(s.Method), s.URI, spec.Name, spec.Version)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
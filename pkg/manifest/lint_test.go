@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+var lintSpec = discovery.ModelAPISpecification{
+	Name:          "Account and Transaction API Specification",
+	Version:       "v3.1.6",
+	SchemaVersion: "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/v3.1.6/dist/swagger/account-info-swagger.json",
+	Manifest:      "file://testdata/lint_manifest.json",
+}
+
+func TestLint(t *testing.T) {
+	issues, err := Lint(lintSpec)
+	require.NoError(t, err)
+
+	const manifest = "file://testdata/lint_manifest.json"
+	expected := LintIssues{
+		{Manifest: manifest, ID: "OB-301-LNT-000200", Severity: LintError, Message: "duplicate id"},
+		{Manifest: manifest, ID: "OB-301-LNT-000300", Severity: LintError, Message: "asserts_one_of: assertion OB3LNTAssertUnknown does not exist"},
+		{Manifest: manifest, ID: "OB-301-LNT-000300", Severity: LintError, Message: "advisory_asserts: assertion OB3LNTAssertUnknownAdvisory does not exist"},
+		{Manifest: manifest, ID: "OB-301-LNT-000300", Severity: LintError, Message: "parameter arity: macro nextDayDateTime takes 1 parameters, 0 given"},
+		{Manifest: manifest, ID: "OB-301-LNT-000300", Severity: LintError, Message: "parameter unknown: macro unknownMacro does not exist"},
+		{Manifest: manifest, ID: "OB-301-LNT-000300", Severity: LintError, Message: `parameter template: template "${formatDate(now())}", column 3: function formatDate does not exist`},
+		{Manifest: manifest, ID: "OB-301-LNT-000400", Severity: LintError, Message: "$unresolvedAccountId does not resolve to a parameter, reference or context value"},
+		{Manifest: manifest, ID: "OB-301-LNT-000400", Severity: LintError, Message: "$OB-301-LNT-000500-TransactionId is put in context by OB-301-LNT-000500 which runs later"},
+		{Manifest: manifest, ID: "OB-301-LNT-000500", Severity: LintError, Message: "keepContextOnSuccess: unknown key path, expected name and value"},
+		{Manifest: manifest, ID: "OB-301-LNT-000500", Severity: LintError, Message: "keepContextOnSuccess: missing value"},
+		{Manifest: manifest, ID: "OB-301-LNT-000500", Severity: LintWarning, Message: "GET /accounts/$OB-301-LNT-000100-AccountId/foobar is not defined by the Account and Transaction API Specification v3.1.6 specification"},
+	}
+	assert.Equal(t, expected, issues)
+	assert.Equal(t, 10, issues.Errors())
+}
+
+func TestLintChecksAssertionMatches(t *testing.T) {
+	refs := References{References: map[string]Reference{
+		"OB3LNTAssertBalances": {Expect: model.Expect{Matches: []model.Match{
+			{JSONPath: "$.Data.Balance[*].CreditDebitIndicator", In: []string{"Credit", "Debit"}},
+			{JSONPath: "$.Data.Balance[?@.Type = 'Expected']"},
+			{JSONPath: "$.Data.Balance[*]", Quantifier: "every"},
+		}}},
+	}}
+	l := linter{manifest: "file://testdata/lint_manifest.json", refs: refs, issues: LintIssues{}}
+
+	l.checkAsserts(Script{ID: "OB-301-LNT-000600", Asserts: []string{"OB3LNTAssertBalances"}})
+
+	assert.Equal(t, LintIssues{
+		{Manifest: l.manifest, ID: "OB-301-LNT-000600", Severity: LintError, Message: `asserts: assertion OB3LNTAssertBalances: jsonpath "$.Data.Balance[?@.Type = 'Expected']", column 24: unexpected '=', expected ',' or ']'`},
+		{Manifest: l.manifest, ID: "OB-301-LNT-000600", Severity: LintError, Message: "asserts: assertion OB3LNTAssertBalances: quantifier every is not one of all, any or none"},
+	}, l.issues)
+}
+
+func TestLintUnknownSpecificationSkipsURIs(t *testing.T) {
+	spec := lintSpec
+	spec.Name = "Unknown API Specification"
+	issues, err := Lint(spec)
+	require.NoError(t, err)
+
+	assert.Equal(t, LintWarning, issues[0].Severity)
+	assert.Contains(t, issues[0].Message, "uris not checked")
+	for _, issue := range issues {
+		assert.NotContains(t, issue.Message, "is not defined by")
+	}
+}
+
+func TestLintMissingManifest(t *testing.T) {
+	spec := lintSpec
+	spec.Manifest = "file://testdata/missing.json"
+	_, err := Lint(spec)
+	assert.Error(t, err)
+}
+
+func TestLintDiscoveryTemplate(t *testing.T) {
+	bytes, err := ioutil.ReadFile("../discovery/templates/ob-v3.1-ozone.json")
+	require.NoError(t, err)
+	disco := discovery.Model{}
+	require.NoError(t, json.Unmarshal(bytes, &disco))
+
+	issues := LintDiscovery(disco)
+
+	for _, issue := range issues {
+		assert.NotContains(t, issue.Message, "cannot load")
+		// negative tests read uris which are not in the specification
+		if issue.Severity == LintWarning {
+			assert.Contains(t, issue.Message, "is not defined by")
+		}
+	}
+}
+
+func TestLintDiscoveryTemplates(t *testing.T) {
+	templates, err := filepath.Glob("../discovery/templates/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, templates)
+
+	linted := map[string]bool{} // the templates share their manifests
+	for _, template := range templates {
+		discoveryJSON, err := ioutil.ReadFile(template)
+		require.NoError(t, err)
+		disco, err := discovery.UnmarshalDiscoveryJSON(string(discoveryJSON))
+		require.NoError(t, err)
+
+		for _, item := range disco.DiscoveryModel.DiscoveryItems {
+			spec := item.APISpecification
+			if linted[spec.Manifest+" "+spec.Version] {
+				continue
+			}
+			linted[spec.Manifest+" "+spec.Version] = true
+
+			issues, err := Lint(spec)
+			require.NoError(t, err, template)
+			for _, issue := range issues {
+				assert.NotEqual(t, LintError, issue.Severity, "%s: %s", template, issue)
+			}
+		}
+	}
+}
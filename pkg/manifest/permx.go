@@ -14,9 +14,10 @@ import (
 
 // TestCasePermission -
 type TestCasePermission struct {
-	ID     string   `json:"id,omitempty"`
-	Perms  []string `json:"perms,omitempty"`
-	Permsx []string `json:"permsx,omitempty"`
+	ID      string   `json:"id,omitempty"`
+	Perms   []string `json:"perms,omitempty"`
+	Permsx  []string `json:"permsx,omitempty"`
+	Consent string   `json:"consent,omitempty"`
 }
 
 // RequiredTokens -
@@ -26,6 +27,7 @@ type RequiredTokens struct {
 	IDs             []string `json:"ids,omitempty"`
 	Perms           []string `json:"perms,omitempty"`
 	Permsx          []string `json:"permsx,omitempty"`
+	Consent         string   `json:"consent,omitempty"` // Name of a dedicated consent not shared with other testcases
 	AccessToken     string
 	ConsentURL      string
 	ConsentID       string
@@ -226,7 +228,8 @@ func getTestCasePermissions(tcs []model.TestCase) ([]TestCasePermission, error)
 			continue
 		}
 		permsx, _ := ctx.GetStringSlice("permissions-excluded")
-		tcp := TestCasePermission{ID: tc.ID, Perms: perms, Permsx: permsx}
+		consent, _ := ctx.GetString("consent")
+		tcp := TestCasePermission{ID: tc.ID, Perms: perms, Permsx: permsx, Consent: consent}
 		tcps = append(tcps, tcp)
 	}
 	return tcps, nil
@@ -424,6 +427,10 @@ func (te *TokenStore) GetNextTokenName(s string) string {
 
 // create or update TokenGethereer
 func (te *TokenStore) createOrUpdate(tcp TestCasePermission) {
+	if tcp.Consent != "" {
+		te.createOrUpdateDedicated(tcp)
+		return
+	}
 
 	if len(te.store) == 0 { // First time - no permissions - just add
 		tpg := RequiredTokens{Name: te.GetNextTokenName("account"), IDs: []string{tcp.ID}, Perms: tcp.Perms, Permsx: tcp.Permsx}
@@ -433,7 +440,7 @@ func (te *TokenStore) createOrUpdate(tcp TestCasePermission) {
 
 	if len(tcp.Perms) == 0 && len(tcp.Permsx) == 0 {
 		for idx, tgItem := range te.store {
-			if len(tgItem.Perms) == 0 && len(tgItem.Permsx) == 0 {
+			if len(tgItem.Perms) == 0 && len(tgItem.Permsx) == 0 && tgItem.Consent == "" {
 				te.store[idx].IDs = append(te.store[idx].IDs, tcp.ID)
 				return
 			}
@@ -443,6 +450,9 @@ func (te *TokenStore) createOrUpdate(tcp TestCasePermission) {
 	}
 
 	for idx, tgItem := range te.store { // loop through each Gathered Item
+		if tgItem.Consent != "" { // dedicated consents are never shared
+			continue
+		}
 		tcPermxConflict := false
 		tcPermConflict := false
 
@@ -487,6 +497,19 @@ func (te *TokenStore) createOrUpdate(tcp TestCasePermission) {
 	return
 }
 
+// createOrUpdateDedicated groups testcases naming the same dedicated consent into a token of their own,
+// so that the consent can be revoked without affecting any other testcase
+func (te *TokenStore) createOrUpdateDedicated(tcp TestCasePermission) {
+	for idx, tgItem := range te.store {
+		if tgItem.Consent == tcp.Consent {
+			te.store[idx] = addPermToGathererItem(tcp, tgItem)
+			return
+		}
+	}
+	tpg := RequiredTokens{Name: te.GetNextTokenName("account"), IDs: []string{tcp.ID}, Perms: tcp.Perms, Permsx: tcp.Permsx, Consent: tcp.Consent}
+	te.store = append(te.store, tpg)
+}
+
 func addPermToGathererItem(tp TestCasePermission, tg RequiredTokens) RequiredTokens {
 	tg.IDs = append(tg.IDs, tp.ID)
 	permsToAdd := []string{}
@@ -85,6 +85,26 @@ func TestGetScriptConsentTokens(t *testing.T) {
 	populateTokens(t, requiredTokens)
 }
 
+func TestDedicatedConsentIsNotShared(t *testing.T) {
+	tcps := []TestCasePermission{
+		{ID: "1", Perms: []string{"ReadAccountsBasic"}},
+		{ID: "2", Perms: []string{"ReadAccountsBasic"}, Consent: "revocation"},
+		{ID: "3", Perms: []string{"ReadAccountsBasic", "ReadBalances"}},
+		{ID: "4", Perms: []string{"ReadAccountsBasic"}, Consent: "revocation"},
+	}
+
+	requiredTokens, err := getRequiredTokens(tcps)
+	assert.Nil(t, err)
+	assert.Len(t, requiredTokens, 2)
+
+	assert.Equal(t, []string{"1", "3"}, requiredTokens[0].IDs)
+	assert.Equal(t, "", requiredTokens[0].Consent)
+	assert.Equal(t, []string{"2", "4"}, requiredTokens[1].IDs)
+	assert.Equal(t, "revocation", requiredTokens[1].Consent)
+	assert.Equal(t, []string{"ReadAccountsBasic"}, requiredTokens[1].Perms)
+	assert.NotEqual(t, requiredTokens[0].Name, requiredTokens[1].Name)
+}
+
 func populateTokens(t *testing.T, gatherer []RequiredTokens) error {
 	t.Helper()
 
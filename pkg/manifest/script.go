@@ -17,6 +17,7 @@ import (
 	"github.com/tidwall/sjson"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 )
 
@@ -41,10 +42,13 @@ type Script struct {
 	Body                  string            `json:"body,omitempty"`
 	Permissions           []string          `json:"permissions,omitemtpy"`
 	PermissionsExcluded   []string          `json:"permissions-excluded,omitemtpy"`
+	Consent               string            `json:"consent,omitempty"`
+	Tags                  []string          `json:"tags,omitempty"`
 	Resource              string            `json:"resource,omitempty"`
 	Asserts               []string          `json:"asserts,omitempty"`
 	AssertsOneOf          []string          `json:"asserts_one_of,omitempty"`
 	AssertsLastIfAll      []string          `json:"asserts_last_if_all,omitempty"`
+	AdvisoryAsserts       []string          `json:"advisory_asserts,omitempty"` // Asserts reported as warnings when not met, they do not fail the test
 	Method                string            `json:"method,omitempty"`
 	URI                   string            `json:"uri,omitempty"`
 	URIImplemenation      string            `json:"uriImplementation,omitempty"`
@@ -53,6 +57,9 @@ type Script struct {
 	UseCCGToken           bool              `json:"useCCGToken,omitempty"`
 	ValidateSignature     bool              `json:"validateSignature,omitempty"`
 	ExpectArrayResults    bool              `json:"expect_array_results,omitempty"`
+	Poll                  *model.Poll       `json:"poll,omitempty"`
+	Replay                *model.Replay     `json:"replay,omitempty"`
+	Paging                *model.Paging     `json:"paging,omitempty"`
 }
 
 // References - reference collection
@@ -107,6 +114,7 @@ type GenerationParameters struct {
 	ManifestPath string
 	Validator    schema.Validator
 	Conditional  []discovery.ConditionalAPIProperties
+	Selection    Selection
 }
 
 // GenerateTestCases examines a manifest file, asserts file and resources definition, then builds the associated test cases
@@ -152,6 +160,9 @@ func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts,
 	} else {
 		filteredScripts = scripts // normal processing
 	}
+	notImplemented, optionalEndpoints := optionalNotImplemented(scripts, filteredScripts, params.Spec, params.Endpoints)
+	notImplemented = params.Selection.Apply(notImplemented, params.Spec.Name)
+	filteredScripts = params.Selection.Apply(filteredScripts, params.Spec.Name)
 
 	params.Ctx.DumpContext("Incoming Ctx")
 
@@ -183,9 +194,72 @@ func GenerateTestCases(params *GenerationParameters) ([]model.TestCase, Scripts,
 		tests = append(tests, tc)
 	}
 
+	for _, script := range notImplemented.Scripts {
+		tests = append(tests, notApplicableTestCase(script, optionalEndpoints[script.ID], params.Baseurl, params.Spec))
+	}
+
 	return tests, filteredScripts, nil
 }
 
+// optionalNotImplemented returns the scripts dropped by the discovery filtering which test an optional endpoint
+// of the specification not listed in `endpoints`, with the specification path of the endpoint keyed by script ID
+func optionalNotImplemented(scripts, filtered Scripts, spec discovery.ModelAPISpecification, endpoints []discovery.ModelEndpoint) (Scripts, map[string]string) {
+	specification, err := model.SpecificationFromSchemaVersion(spec.SchemaVersion)
+	if err != nil {
+		return Scripts{}, nil
+	}
+	implemented := map[string]bool{}
+	for _, ep := range endpoints {
+		implemented[ep.Method+" "+ep.Path] = true
+	}
+	optional := []model.Conditionality{}
+	regexes := []*regexp.Regexp{}
+	for _, cond := range model.GetEndpointConditionality(specification.Identifier) {
+		if cond.Condition == model.Optional && !implemented[cond.Method+" "+cond.Endpoint] {
+			optional = append(optional, cond)
+			regexes = append(regexes, regexp.MustCompile("^"+specPathParameter.ReplaceAllString(cond.Endpoint, subPathx)+"$"))
+		}
+	}
+
+	var notImplemented []Script
+	paths := map[string]string{}
+	for _, scr := range scripts.Scripts {
+		if contains(filtered.Scripts, scr) {
+			continue
+		}
+		stripped := strings.Replace(scr.URI, "$", "", -1)
+		for i, cond := range optional {
+			if cond.Method == scr.Method && regexes[i].MatchString(stripped) {
+				notImplemented = append(notImplemented, scr)
+				paths[scr.ID] = cond.Endpoint
+				break
+			}
+		}
+	}
+	return Scripts{Scripts: notImplemented}, paths
+}
+
+// specPathParameter matches the `{Id}` parameters in the paths of specification endpoints
+var specPathParameter = regexp.MustCompile(`\{[^}]+\}`)
+
+// notApplicableTestCase builds a test case which does not call its endpoint, to be reported as not applicable
+func notApplicableTestCase(s Script, endpoint, baseurl string, apiSpec discovery.ModelAPISpecification) model.TestCase {
+	tc := model.MakeTestCase()
+	tc.ID = s.ID
+	tc.Name = s.Description
+	tc.Detail = s.Detail
+	tc.RefURI = s.RefURI
+	tc.Purpose = s.Detail
+	tc.APIName = apiSpec.Name
+	tc.APIVersion = apiSpec.Version
+	tc.Input.Method = s.Method
+	tc.Input.Endpoint = endpoint
+	tc.Context = model.Context{}
+	tc.Context.PutString("baseurl", baseurl)
+	tc.DoNotCallEndpoint = true
+	return tc
+}
+
 func addQueryParametersToRequest(tc *model.TestCase, parameters map[string]string) {
 	for k, v := range parameters {
 		// FormData is encoded to URL query parameters on "GET" requests
@@ -258,6 +332,20 @@ func (s *Script) processParameters(refs *References, resources *model.Context) (
 			continue
 		}
 
+		if model.HasTemplates(value) {
+			// templates using values only put in context during the run are expanded when the test runs
+			templateCtx := model.Context{}
+			if resources != nil {
+				templateCtx.PutContext(resources)
+			}
+			templateCtx.PutContext(&localCtx)
+			if result, err := model.ExpandTemplates(value, &templateCtx); err == nil {
+				value = result
+			}
+			localCtx.PutString(k, value)
+			continue
+		}
+
 		if strings.Contains(value, "$") {
 			str := value[1:]
 			//lookup parameter in resources - accountids
@@ -289,6 +377,9 @@ func (s *Script) processParameters(refs *References, resources *model.Context) (
 	if len(s.PermissionsExcluded) > 0 {
 		localCtx.PutStringSlice("permissions-excluded", s.PermissionsExcluded)
 	}
+	if s.Consent != "" {
+		localCtx.PutString("consent", s.Consent)
+	}
 	return &localCtx, nil
 }
 
@@ -345,6 +436,7 @@ func buildTestCase(s Script, refs map[string]Reference, ctx *model.Context, base
 	tc.Validator = validator
 	tc.ValidateSignature = s.ValidateSignature
 	tc.ExpectArrayResults = s.ExpectArrayResults
+	tc.Resource = s.Resource
 
 	//TODO: make these more configurable - header also get set in buildInput Section
 	tc.Input.Headers["x-fapi-financial-id"] = "$x-fapi-financial-id"
@@ -376,6 +468,12 @@ func buildTestCase(s Script, refs map[string]Reference, ctx *model.Context, base
 			tc.Expect.StatusCode = clone.StatusCode
 		}
 		tc.Expect.Matches = append(tc.Expect.Matches, clone.Matches...)
+		if clone.Poll != nil {
+			tc.Expect.Poll = clone.Poll
+		}
+		if clone.Replay != nil {
+			tc.Expect.Replay = clone.Replay
+		}
 	}
 
 	for _, a := range s.AssertsOneOf {
@@ -398,7 +496,22 @@ func buildTestCase(s Script, refs map[string]Reference, ctx *model.Context, base
 		tc.ExpectLastIfAll = append(tc.ExpectLastIfAll, ref.Expect.Clone())
 	}
 
+	for _, a := range s.AdvisoryAsserts {
+		ref, exists := refs[a]
+		if !exists {
+			msg := fmt.Sprintf("assertion %s do not exist in reference data", a)
+			logrus.Error(msg)
+			return tc, errors.New(msg)
+		}
+		tc.ExpectAdvisory = append(tc.ExpectAdvisory, ref.Expect.Clone())
+	}
+
 	tc.Expect.SchemaValidation = s.SchemaCheck
+	if s.Poll != nil {
+		tc.Expect.Poll = s.Poll.Clone()
+	}
+	tc.Replay = s.Replay.Clone()
+	tc.Paging = s.Paging.Clone()
 
 	// Handled PutContext parameters
 	putMatches := processPutContext(&s)
@@ -543,19 +656,19 @@ func getSpecVersion(spectype string, apiVersions []string) (semver.Version, erro
 
 func loadAssertions() (References, error) {
 	refs, err := loadReferences("manifests/assertions.json")
-	if err != nil {
+	if os.IsNotExist(err) {
 		refs, err = loadReferences("../../manifests/assertions.json")
-		if err != nil {
-			return References{}, err
-		}
+	}
+	if err != nil {
+		return References{}, err
 	}
 
 	refs2, err := loadReferences("manifests/data.json")
-	if err != nil {
+	if os.IsNotExist(err) {
 		refs2, err = loadReferences("../../manifests/data.json")
-		if err != nil {
-			return References{}, err
-		}
+	}
+	if err != nil {
+		return References{}, err
 	}
 
 	for k, v := range refs2.References { // read in data references with body payloads
@@ -603,6 +716,9 @@ func loadScripts(filename string) (Scripts, error) {
 		return Scripts{}, errors.New("loadScripts - no scheme present: (file://)")
 	}
 
+	if err := jsonschema.Validate(jsonschema.Manifest, scriptBytes); err != nil {
+		return Scripts{}, errors.Wrap(err, filename)
+	}
 	var m Scripts
 	err = json.Unmarshal(scriptBytes, &m)
 	if err != nil {
@@ -616,6 +732,9 @@ func loadReferences(filename string) (References, error) {
 	if err != nil {
 		return References{}, err
 	}
+	if err := jsonschema.Validate(jsonschema.Assertions, plan); err != nil {
+		return References{}, errors.Wrap(err, filename)
+	}
 	var m References
 	err = json.Unmarshal(plan, &m)
 	if err != nil {
@@ -734,6 +853,10 @@ type PathRegex struct {
 }
 
 var accountsRegex = []PathRegex{
+	{
+		Regex: "^/account-access-consents/" + subPathx + "$",
+		Name:  "Account Access Consent",
+	},
 	{
 		Regex: "^/accounts$",
 		Name:  "Get Accounts",
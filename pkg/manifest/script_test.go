@@ -11,6 +11,7 @@ import (
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
@@ -583,6 +584,37 @@ func TestFilterTestsBasedOnDiscoveryEndpoints(t *testing.T) {
 // Given a collection of `Scripts` and a collection of `endpoints`, we want the tested function return
 // a subset of `Scripts`, where the URI of each returned script matches an endpoint (via regex) of at least one of
 // the paths in the collection of `endpoints`.
+func TestOptionalNotImplemented(t *testing.T) {
+	scripts := Scripts{Scripts: []Script{
+		{ID: "OB-301-ACC-100100", Method: "GET", URI: "/accounts"},
+		{ID: "OB-301-ACC-200100", Method: "GET", URI: "/standing-orders"},
+		{ID: "OB-301-ACC-200200", Method: "GET", URI: "/accounts/$consentedAccountId/standing-orders"},
+		{ID: "OB-301-ACC-200300", Method: "GET", URI: "/offers"},
+	}}
+	filtered := Scripts{Scripts: scripts.Scripts[:1]}
+	spec := discovery.ModelAPISpecification{
+		Name:          "Account and Transaction API Specification",
+		Version:       "v3.1.0",
+		SchemaVersion: "https://raw.githubusercontent.com/OpenBankingUK/read-write-api-specs/v3.1.0/dist/account-info-swagger.json",
+	}
+	endpoints := []discovery.ModelEndpoint{{Method: "GET", Path: "/accounts"}, {Method: "GET", Path: "/offers"}}
+
+	notImplemented, paths := optionalNotImplemented(scripts, filtered, spec, endpoints)
+
+	require.Len(t, notImplemented.Scripts, 1, "conditional and listed optional endpoints apply")
+	assert.Equal(t, "OB-301-ACC-200100", notImplemented.Scripts[0].ID)
+	assert.Equal(t, map[string]string{"OB-301-ACC-200100": "/standing-orders"}, paths)
+
+	tc := notApplicableTestCase(notImplemented.Scripts[0], paths["OB-301-ACC-200100"], "https://rs.aspsp.example", spec)
+	assert.True(t, tc.DoNotCallEndpoint)
+	req, err := tc.Prepare(&model.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://rs.aspsp.example/standing-orders", req.URL)
+
+	notImplemented, _ = optionalNotImplemented(scripts, filtered, discovery.ModelAPISpecification{SchemaVersion: "unknown"}, endpoints)
+	assert.Empty(t, notImplemented.Scripts)
+}
+
 func TestVrpFilterTestsBasedOnDiscoveryEndpoints(t *testing.T) {
 	scripts := Scripts{
 		Scripts: []Script{
@@ -648,3 +680,47 @@ func TestContains(t *testing.T) {
 	assert.True(t, contains(collection, subjectExists))
 	assert.False(t, contains(collection, subjectNotExists))
 }
+
+func TestLoadScriptsValidatesSchema(t *testing.T) {
+	_, err := loadScripts("file://testdata/invalid_manifest.json")
+
+	assert.EqualError(t, err, "file://testdata/invalid_manifest.json: json schema validation failed: line 7: scripts.0.asserts: Field must be set to array or not be present")
+}
+
+func TestBuildTestCaseAdvisoryAsserts(t *testing.T) {
+	refs := map[string]Reference{
+		"OB3GLOAssertOn200":                {Expect: model.Expect{StatusCode: 200}},
+		"OB3GLOAssertInteractionIdPresent": {Expect: model.Expect{Matches: []model.Match{{HeaderPresent: "x-fapi-interaction-id"}}}},
+	}
+	s := Script{ID: "OB-301-ACC-100000", Method: "get", URI: "/accounts", Asserts: []string{"OB3GLOAssertOn200"}, AdvisoryAsserts: []string{"OB3GLOAssertInteractionIdPresent"}}
+
+	tc, err := buildTestCase(s, refs, &model.Context{}, "http://mybaseurl", "accounts", schema.NewNullValidator(), discovery.ModelAPISpecification{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, tc.Expect.StatusCode)
+	assert.Empty(t, tc.Expect.Matches)
+	assert.Equal(t, []model.Expect{{Matches: []model.Match{{HeaderPresent: "x-fapi-interaction-id"}}}}, tc.ExpectAdvisory)
+
+	s.AdvisoryAsserts = []string{"OB3GLOAssertUnknown"}
+	_, err = buildTestCase(s, refs, &model.Context{}, "http://mybaseurl", "accounts", schema.NewNullValidator(), discovery.ModelAPISpecification{}, "")
+	assert.EqualError(t, err, "assertion OB3GLOAssertUnknown do not exist in reference data")
+}
+
+func TestProcessParametersExpandsTemplates(t *testing.T) {
+	resources := model.Context{}
+	resources.PutStringSlice("accountIds", []string{"500000000000000000000001", "500000000000000000000002"})
+	s := Script{Parameters: map[string]string{
+		"accountId": "${$accountIds[1]}",
+		"consent":   "${$OB-301-DOP-100100-ConsentId}",
+	}}
+
+	localCtx, err := s.processParameters(&References{}, &resources)
+	require.NoError(t, err)
+
+	accountID, err := localCtx.GetString("accountId")
+	require.NoError(t, err)
+	assert.Equal(t, "500000000000000000000002", accountID)
+	// only put in context during the run, expanded when the test runs
+	consent, err := localCtx.GetString("consent")
+	require.NoError(t, err)
+	assert.Equal(t, "${$OB-301-DOP-100100-ConsentId}", consent)
+}
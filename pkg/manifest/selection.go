@@ -0,0 +1,204 @@
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+)
+
+// uriImplementations are the values of the script `uriImplementation`
+var uriImplementations = []string{"mandatory", "optional", "conditional"}
+
+// Selection narrows the scripts of a run. A script is selected when it matches the include rules, or there
+// are none, and does not match the exclude rules. Scripts providing context values referenced by the selected
+// scripts, such as the consent ids used by payments, are selected along with them.
+//
+//	{
+//	    "include": { "ids": ["OB-301-DOP-*"], "uriImplementations": ["mandatory"] },
+//	    "exclude": { "tags": ["slow"] }
+//	}
+type Selection struct {
+	Include SelectionRules `json:"include,omitempty"`
+	Exclude SelectionRules `json:"exclude,omitempty"`
+}
+
+// SelectionRules match a script when any of their values matches it
+type SelectionRules struct {
+	IDs                []string `json:"ids,omitempty"`                // Globs matched against the script id, e.g. OB-301-ACC-*
+	Regexes            []string `json:"regexes,omitempty"`            // Regular expressions matched against the script id and description
+	Resources          []string `json:"resources,omitempty"`          // Script resources, e.g. Account
+	APINames           []string `json:"apiNames,omitempty"`           // API specification names, e.g. Account and Transaction API Specification
+	URIImplementations []string `json:"uriImplementations,omitempty"` // mandatory, optional or conditional
+	Tags               []string `json:"tags,omitempty"`               // Script tags
+}
+
+// globEscaper escapes the characters having a special meaning in a glob
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+
+// SelectIDs returns a selection of the scripts with exactly the ids given, along with their prerequisites
+func SelectIDs(ids []string) Selection {
+	globs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		globs = append(globs, globEscaper.Replace(id))
+	}
+	return Selection{Include: SelectionRules{IDs: globs}}
+}
+
+// IsEmpty is true when the selection selects every script
+func (s Selection) IsEmpty() bool {
+	return s.Include.IsEmpty() && s.Exclude.IsEmpty()
+}
+
+// Validate checks the globs, regular expressions and uriImplementation values of the selection
+func (s Selection) Validate() error {
+	if err := s.Include.validate(); err != nil {
+		return fmt.Errorf("selection include: %s", err.Error())
+	}
+	if err := s.Exclude.validate(); err != nil {
+		return fmt.Errorf("selection exclude: %s", err.Error())
+	}
+	return nil
+}
+
+// Apply returns the scripts selected amongst those of the API specification apiName, in their original order
+func (s Selection) Apply(scripts Scripts, apiName string) Scripts {
+	if s.IsEmpty() {
+		return scripts
+	}
+
+	include := s.Include.compile()
+	exclude := s.Exclude.compile()
+	selected := map[string]bool{}
+	for _, script := range scripts.Scripts {
+		if (s.Include.IsEmpty() || include.matches(script, apiName)) && !exclude.matches(script, apiName) {
+			selected[script.ID] = true
+		}
+	}
+	addPrerequisites(selected, scripts.Scripts)
+
+	result := Scripts{Scripts: []Script{}}
+	for _, script := range scripts.Scripts {
+		if selected[script.ID] {
+			result.Scripts = append(result.Scripts, script)
+		}
+	}
+	return result
+}
+
+// addPrerequisites selects the scripts putting in context the values referenced by the selected scripts
+func addPrerequisites(selected map[string]bool, scripts []Script) {
+	providers := map[string]string{}
+	for _, script := range scripts {
+		if name, exists := script.ContextPut["name"]; exists {
+			providers[name] = script.ID
+		}
+	}
+
+	for added := true; added; {
+		added = false
+		for _, script := range scripts {
+			if !selected[script.ID] {
+				continue
+			}
+			for _, name := range model.ContextReferences(script.referencingValues()...) {
+				if id, exists := providers[name]; exists && !selected[id] {
+					selected[id] = true
+					added = true
+				}
+			}
+		}
+	}
+}
+
+// referencingValues lists the values of the script which may reference context values: its URI, body, and
+// parameters, query parameters and headers, sorted by name, other than functions
+func (s Script) referencingValues() []string {
+	values := []string{s.URI, s.Body}
+	for _, params := range []map[string]string{s.Parameters, s.QueryParameters, s.Headers} {
+		for _, name := range sortedKeys(params) {
+			if !isFunction(params[name]) {
+				values = append(values, params[name])
+			}
+		}
+	}
+	return values
+}
+
+// IsEmpty is true when the rules have no values
+func (r SelectionRules) IsEmpty() bool {
+	return len(r.IDs) == 0 && len(r.Regexes) == 0 && len(r.Resources) == 0 && len(r.APINames) == 0 &&
+		len(r.URIImplementations) == 0 && len(r.Tags) == 0
+}
+
+func (r SelectionRules) validate() error {
+	for _, id := range r.IDs {
+		if _, err := path.Match(id, ""); err != nil {
+			return fmt.Errorf("invalid id glob %q: %s", id, err.Error())
+		}
+	}
+	for _, expr := range r.Regexes {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid regex %q: %s", expr, err.Error())
+		}
+	}
+	for _, value := range r.URIImplementations {
+		if !containsFold(uriImplementations, value) {
+			return fmt.Errorf("invalid uriImplementation %q, expected one of %s", value, strings.Join(uriImplementations, ", "))
+		}
+	}
+	return nil
+}
+
+type compiledSelectionRules struct {
+	SelectionRules
+	regexes []*regexp.Regexp
+}
+
+// compile prepares the rules for matching, invalid regular expressions are reported by Validate and ignored here
+func (r SelectionRules) compile() compiledSelectionRules {
+	c := compiledSelectionRules{SelectionRules: r}
+	for _, expr := range r.Regexes {
+		if re, err := regexp.Compile(expr); err == nil {
+			c.regexes = append(c.regexes, re)
+		}
+	}
+	return c
+}
+
+func (c compiledSelectionRules) matches(s Script, apiName string) bool {
+	for _, id := range c.IDs {
+		if matched, _ := path.Match(id, s.ID); matched {
+			return true
+		}
+	}
+	for _, re := range c.regexes {
+		if re.MatchString(s.ID) || re.MatchString(s.Description) {
+			return true
+		}
+	}
+	if containsFold(c.Resources, s.Resource) || containsFold(c.APINames, apiName) ||
+		containsFold(c.URIImplementations, s.URIImplemenation) {
+		return true
+	}
+	for _, tag := range s.Tags {
+		if containsFold(c.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func selectionScripts() Scripts {
+	return Scripts{Scripts: []Script{
+		{ID: "OB-301-ACC-100100", Description: "Accounts", Resource: "Account", URIImplemenation: "mandatory", Tags: []string{"smoke"}},
+		{ID: "OB-301-ACC-120100", Description: "Transactions", Resource: "Transaction", URIImplemenation: "optional"},
+		{ID: "OB-301-DOP-100300", Description: "Domestic payment consent", Resource: "DomesticPayment", URIImplemenation: "mandatory",
+			ContextPut: map[string]string{"name": "OB-301-DOP-100300-ConsentId", "value": "$.Data.ConsentId"}},
+		{ID: "OB-301-DOP-100400", Description: "Domestic payment", Resource: "DomesticPayment", URIImplemenation: "conditional",
+			Body: `{"Data": {"ConsentId": "$OB-301-DOP-100300-ConsentId"}}`},
+	}}
+}
+
+func selectedIDs(scripts Scripts) []string {
+	ids := []string{}
+	for _, s := range scripts.Scripts {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestSelectionApply(t *testing.T) {
+	cases := []struct {
+		name      string
+		selection Selection
+		expected  []string
+	}{
+		{
+			name:      "empty selection selects everything",
+			selection: Selection{},
+			expected:  []string{"OB-301-ACC-100100", "OB-301-ACC-120100", "OB-301-DOP-100300", "OB-301-DOP-100400"},
+		},
+		{
+			name:      "id glob",
+			selection: Selection{Include: SelectionRules{IDs: []string{"OB-301-ACC-*"}}},
+			expected:  []string{"OB-301-ACC-100100", "OB-301-ACC-120100"},
+		},
+		{
+			name:      "regex on description",
+			selection: Selection{Include: SelectionRules{Regexes: []string{"^Trans"}}},
+			expected:  []string{"OB-301-ACC-120100"},
+		},
+		{
+			name:      "resource ignores case",
+			selection: Selection{Include: SelectionRules{Resources: []string{"account"}}},
+			expected:  []string{"OB-301-ACC-100100"},
+		},
+		{
+			name:      "api name",
+			selection: Selection{Include: SelectionRules{APINames: []string{"Payment Initiation API"}}},
+			expected:  []string{"OB-301-ACC-100100", "OB-301-ACC-120100", "OB-301-DOP-100300", "OB-301-DOP-100400"},
+		},
+		{
+			name:      "uri implementation",
+			selection: Selection{Include: SelectionRules{URIImplementations: []string{"optional"}}},
+			expected:  []string{"OB-301-ACC-120100"},
+		},
+		{
+			name:      "tag",
+			selection: Selection{Include: SelectionRules{Tags: []string{"smoke"}}},
+			expected:  []string{"OB-301-ACC-100100"},
+		},
+		{
+			name:      "exclude only",
+			selection: Selection{Exclude: SelectionRules{IDs: []string{"OB-301-DOP-*"}}},
+			expected:  []string{"OB-301-ACC-100100", "OB-301-ACC-120100"},
+		},
+		{
+			name: "exclude wins over include",
+			selection: Selection{
+				Include: SelectionRules{IDs: []string{"OB-301-ACC-*"}},
+				Exclude: SelectionRules{Tags: []string{"smoke"}},
+			},
+			expected: []string{"OB-301-ACC-120100"},
+		},
+		{
+			name:      "prerequisite providing a context value is selected",
+			selection: Selection{Include: SelectionRules{IDs: []string{"OB-301-DOP-100400"}}},
+			expected:  []string{"OB-301-DOP-100300", "OB-301-DOP-100400"},
+		},
+		{
+			name:      "no match",
+			selection: Selection{Include: SelectionRules{IDs: []string{"OB-400-*"}}},
+			expected:  []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, selectedIDs(c.selection.Apply(selectionScripts(), "Payment Initiation API")))
+		})
+	}
+}
+
+func TestSelectionValidate(t *testing.T) {
+	assert.NoError(t, Selection{Include: SelectionRules{IDs: []string{"OB-301-*"}, Regexes: []string{"^OB"}, URIImplementations: []string{"Mandatory"}}}.Validate())
+
+	assert.EqualError(t, Selection{Include: SelectionRules{IDs: []string{"OB-[301"}}}.Validate(),
+		`selection include: invalid id glob "OB-[301": syntax error in pattern`)
+	assert.EqualError(t, Selection{Exclude: SelectionRules{Regexes: []string{"(OB"}}}.Validate(),
+		"selection exclude: invalid regex \"(OB\": error parsing regexp: missing closing ): `(OB`")
+	assert.EqualError(t, Selection{Exclude: SelectionRules{URIImplementations: []string{"sometimes"}}}.Validate(),
+		`selection exclude: invalid uriImplementation "sometimes", expected one of mandatory, optional, conditional`)
+}
+
+func TestSelectIDs(t *testing.T) {
+	scripts := selectionScripts()
+	scripts.Scripts = append(scripts.Scripts, Script{ID: "OB-301-ACC-[custom]*"})
+
+	selection := SelectIDs([]string{"OB-301-DOP-100400", "OB-301-ACC-[custom]*"})
+
+	assert.NoError(t, selection.Validate())
+	assert.Equal(t, []string{"OB-301-DOP-100300", "OB-301-DOP-100400", "OB-301-ACC-[custom]*"}, selectedIDs(selection.Apply(scripts, "")))
+}
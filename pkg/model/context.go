@@ -2,8 +2,11 @@ package model
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
 )
 
 // Context is intended to handle two types of object and make them available to various parts of the suite including
@@ -136,18 +139,31 @@ func (c *Context) DumpContext(text ...string) {
 		for i := 1; i < len(text); i++ {
 			key := text[i]
 			value, _ := c.Get(key)
-			logrus.StandardLogger().Tracef("[Context] %s : %v", key, value)
+			logrus.StandardLogger().Tracef("[Context] %s : %v", key, dumpedContextValue(key, value))
 		}
 	} else {
 		for k, v := range *c {
-			if k == "client_secret" || k == "basic_authentication" || k == "signingPublic" || k == "signingPrivate" { // skip potentially sensitive fields - likely need to be more robust
-				continue
-			}
-			logrus.StandardLogger().Tracef("[Context] %s:%v", k, v)
+			logrus.StandardLogger().Tracef("[Context] %s:%v", k, dumpedContextValue(k, v))
 		}
 	}
 }
 
+// dumpedContextValue redacts tokens and credentials, unless unsafe debug is enabled
+func dumpedContextValue(key string, value interface{}) interface{} {
+	if redact.UnsafeDebug() {
+		return value
+	}
+	if isSensitiveContextKey(key) {
+		return redact.Value
+	}
+	return redact.String(fmt.Sprint(value))
+}
+
+// isSensitiveContextKey returns true for the keys of credentials and tokens, never logged nor reported
+func isSensitiveContextKey(key string) bool {
+	return redact.IsSensitiveKey(key)
+}
+
 // IsSet returns true if the key exists and is not set to zero value (nil or empty string)
 func (c *Context) IsSet(key string) bool {
 	val, exists := c.Get(key)
@@ -0,0 +1,260 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"gopkg.in/resty.v1"
+)
+
+// CustomCheckInput is what a custom check is given to check a response
+type CustomCheckInput struct {
+	Match    *Match         // Match naming the check, its `args` parametrise the check
+	Request  *resty.Request // Request sent, nil when the test case was not prepared
+	TestCase *TestCase      // Test case, with the request Input and the response Header, Body and ResponseTime
+	Context  *Context       // Context the test case ran with, empty when the match is checked without one
+}
+
+// Arg returns the argument name of the match, def when it is not given
+func (c CustomCheckInput) Arg(name, def string) string {
+	if value, exists := c.Match.Args[name]; exists && value != "" {
+		return value
+	}
+	return def
+}
+
+// CustomCheckFunc is a check referenced by the `custom` field of a match. It returns an error describing why
+// the response does not pass the check.
+type CustomCheckFunc func(check CustomCheckInput) error
+
+var customChecks = map[string]CustomCheckFunc{
+	"jwsSignatureHeader": checkJWSSignatureHeader,
+	"consentLinkage":     checkConsentLinkage,
+	"accountLinkage":     checkAccountLinkage,
+	"dateWindow":         checkDateWindow,
+}
+
+// AddCustomCheck registers a custom check, replacing any check with the same name.
+// It is not expected to be called concurrently, nor once tests run.
+func AddCustomCheck(name string, check CustomCheckFunc) {
+	customChecks[name] = check
+}
+
+// CustomCheckExists returns true when a custom check called name is registered
+func CustomCheckExists(name string) bool {
+	_, exists := customChecks[name]
+	return exists
+}
+
+// CustomCheckNames returns the names of the custom checks registered, sorted
+func CustomCheckNames() []string {
+	names := make([]string, 0, len(customChecks))
+	for name := range customChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckContext checks a match with the context the test case ran with, which custom checks may read
+func (m *Match) CheckContext(tc *TestCase, ctx *Context) (bool, error) {
+	if m.GetType() == CustomCheck {
+		return checkCustomContext(m, tc, ctx)
+	}
+	return m.Check(tc)
+}
+
+func checkCustom(m *Match, tc *TestCase) (bool, error) {
+	return checkCustomContext(m, tc, nil)
+}
+
+func checkCustomContext(m *Match, tc *TestCase, ctx *Context) (bool, error) {
+	check, exists := customChecks[m.Custom]
+	if !exists {
+		return false, m.AppErr(fmt.Sprintf("Custom Check Failed - custom check %s does not exist", m.Custom))
+	}
+	if ctx == nil {
+		ctx = &Context{}
+	}
+	if err := check(CustomCheckInput{Match: m, Request: tc.Request, TestCase: tc, Context: ctx}); err != nil {
+		return false, m.AppErr(fmt.Sprintf("Custom Check Failed - %s: %s", m.Custom, err.Error()))
+	}
+	return true, nil
+}
+
+// obSignatureClaims are the critical claims of the protected header of an Open Banking JWS
+var obSignatureClaims = []string{
+	"http://openbanking.org.uk/iat",
+	"http://openbanking.org.uk/iss",
+	"http://openbanking.org.uk/tan",
+}
+
+// checkJWSSignatureHeader checks the form of the detached JWS of the response `x-jws-signature` header, without
+// verifying it: it has no payload, an `alg` other than none, one of `args.alg` when given, a `kid`, the Open
+// Banking critical claims and an `iat` which is not in the future. The `b64` claim is checked against
+// `args.b64` when given.
+func checkJWSSignatureHeader(check CustomCheckInput) error {
+	signature := check.TestCase.Header.Get("x-jws-signature")
+	if signature == "" {
+		return fmt.Errorf("x-jws-signature header not found")
+	}
+	parts := strings.Split(signature, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("x-jws-signature is not a detached JWS")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("x-jws-signature protected header is not base64url encoded")
+	}
+	header := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return fmt.Errorf("x-jws-signature protected header is not JSON")
+	}
+
+	alg, _ := header["alg"].(string)
+	if alg == "" || strings.EqualFold(alg, "none") {
+		return fmt.Errorf("alg (%s) is not a signing algorithm", alg)
+	}
+	if algs := check.Arg("alg", ""); algs != "" && !containsString(strings.Split(algs, ","), alg) {
+		return fmt.Errorf("alg (%s) is not one of (%s)", alg, algs)
+	}
+	if kid, _ := header["kid"].(string); kid == "" {
+		return fmt.Errorf("kid is missing")
+	}
+	crit, _ := header["crit"].([]interface{})
+	for _, claim := range obSignatureClaims {
+		if _, exists := header[claim]; !exists {
+			return fmt.Errorf("claim %s is missing", claim)
+		}
+		if !containsValue(crit, claim) {
+			return fmt.Errorf("claim %s is not critical", claim)
+		}
+	}
+	iat, ok := header["http://openbanking.org.uk/iat"].(float64)
+	if !ok {
+		return fmt.Errorf("claim http://openbanking.org.uk/iat is not a number")
+	}
+	if issued := time.Unix(int64(iat), 0); issued.After(time.Now().Add(time.Minute)) {
+		return fmt.Errorf("claim http://openbanking.org.uk/iat (%s) is in the future", issued.UTC().Format(time.RFC3339))
+	}
+	if b64 := check.Arg("b64", ""); b64 != "" {
+		actual := "true"
+		if value, exists := header["b64"]; exists {
+			actual = fmt.Sprint(value)
+		}
+		if actual != b64 {
+			return fmt.Errorf("b64 is %s, expected %s", actual, b64)
+		}
+	}
+	return nil
+}
+
+// checkConsentLinkage checks the `Data.ConsentId` of the response is the consent the request was made with: the
+// context value named by `args.context` when given, the `Data.ConsentId` of the request body otherwise
+func checkConsentLinkage(check CustomCheckInput) error {
+	actual := gjson.Get(check.TestCase.Body, "Data.ConsentId")
+	if !actual.Exists() {
+		return fmt.Errorf("response has no Data.ConsentId")
+	}
+
+	var expected string
+	if name := check.Arg("context", ""); name != "" {
+		value, err := check.Context.GetString(name)
+		if err != nil {
+			return fmt.Errorf("consent id %s not found in context", name)
+		}
+		expected = value
+	} else {
+		requested := gjson.Get(check.TestCase.Input.RequestBody, "Data.ConsentId")
+		if !requested.Exists() {
+			return fmt.Errorf("request has no Data.ConsentId")
+		}
+		expected = requested.String()
+	}
+	if actual.String() != expected {
+		return fmt.Errorf("response consent (%s) is not the consent requested (%s)", actual.String(), expected)
+	}
+	return nil
+}
+
+var accountEndpointRegex = regexp.MustCompile(`/accounts/([^/?]+)`)
+
+// checkAccountLinkage checks every `AccountId` of the response is an account the request may read: one of the
+// comma separated values, or the list, of the context value named by `args.context` when given, the account of the
+// `/accounts/{AccountId}` endpoint requested otherwise
+func checkAccountLinkage(check CustomCheckInput) error {
+	allowed := []string{}
+	if name := check.Arg("context", ""); name != "" {
+		if values, err := check.Context.GetStringSlice(name); err == nil {
+			allowed = values
+		} else if value, err := check.Context.GetString(name); err == nil {
+			for _, v := range strings.Split(value, ",") {
+				allowed = append(allowed, strings.TrimSpace(v))
+			}
+		} else {
+			return fmt.Errorf("accounts %s not found in context", name)
+		}
+	} else {
+		match := accountEndpointRegex.FindStringSubmatch(check.TestCase.Input.Endpoint)
+		if match == nil {
+			return fmt.Errorf("endpoint %s is not an account endpoint", check.TestCase.Input.Endpoint)
+		}
+		allowed = append(allowed, match[1])
+	}
+
+	var body interface{}
+	if err := json.Unmarshal([]byte(check.TestCase.Body), &body); err != nil {
+		return fmt.Errorf("body is not JSON: %s", err.Error())
+	}
+	path, err := CompileJSONPath("$..AccountId")
+	if err != nil {
+		return err
+	}
+	for _, node := range path.Select(body) {
+		accountID := renderMatchNode(node)
+		if !containsString(allowed, accountID) {
+			return fmt.Errorf("account (%s) is not one of the accounts requested (%s)", accountID, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// checkDateWindow checks the dates selected by the JSONPath `args.jsonpath` are within the window requested by
+// the query parameters `args.from` and `args.to`, by default the transactions booked between
+// `fromBookingDateTime` and `toBookingDateTime`. A bound which is not requested is not checked.
+func checkDateWindow(check CustomCheckInput) error {
+	path, err := CompileJSONPath(check.Arg("jsonpath", "$.Data.Transaction[*].BookingDateTime"))
+	if err != nil {
+		return err
+	}
+	window, err := newDateWindow(requestQuery(check.TestCase, check.Request), check.Arg("from", "fromBookingDateTime"), check.Arg("to", "toBookingDateTime"))
+	if err != nil {
+		return err
+	}
+
+	var body interface{}
+	if err := json.Unmarshal([]byte(check.TestCase.Body), &body); err != nil {
+		return fmt.Errorf("body is not JSON: %s", err.Error())
+	}
+	for _, node := range path.Select(body) {
+		if err := window.check(renderMatchNode(node)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
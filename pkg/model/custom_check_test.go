@@ -0,0 +1,158 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+)
+
+func TestAddCustomCheck(t *testing.T) {
+	AddCustomCheck("testStatusHeader", func(check CustomCheckInput) error {
+		expected, _ := check.Context.GetString("status")
+		if actual := check.TestCase.Header.Get(check.Arg("header", "x-status")); actual != expected {
+			return fmt.Errorf("status (%s) is not (%s)", actual, expected)
+		}
+		return nil
+	})
+	defer delete(customChecks, "testStatusHeader")
+
+	assert.True(t, CustomCheckExists("testStatusHeader"))
+	assert.Contains(t, CustomCheckNames(), "testStatusHeader")
+	m := Match{Custom: "testStatusHeader", Args: map[string]string{"header": "x-consent-status"}}
+	tc := &TestCase{Header: http.Header{"X-Consent-Status": []string{"Authorised"}}}
+
+	success, err := m.CheckContext(tc, &Context{"status": "Authorised"})
+	require.NoError(t, err)
+	assert.True(t, success)
+
+	success, err = m.Check(tc)
+	assert.False(t, success)
+	assert.EqualError(t, err, "Custom Check Failed - testStatusHeader: status (Authorised) is not ()")
+}
+
+func TestCustomCheckDoesNotExist(t *testing.T) {
+	m := Match{Custom: "unknownCheck"}
+
+	success, err := m.Check(&TestCase{})
+
+	assert.False(t, success)
+	assert.EqualError(t, err, "Custom Check Failed - custom check unknownCheck does not exist")
+	assert.EqualError(t, m.Validate(), "custom check unknownCheck does not exist")
+}
+
+func TestApplyExpectsPassesContextToCustomChecks(t *testing.T) {
+	AddCustomCheck("testContext", func(check CustomCheckInput) error {
+		if _, exists := check.Context.Get("consentId"); !exists {
+			return errors.New("no consent id")
+		}
+		return nil
+	})
+	defer delete(customChecks, "testContext")
+	tc := &TestCase{Expect: Expect{StatusCode: http.StatusOK, Matches: []Match{{Custom: "testContext"}}}}
+	resp := &resty.Response{RawResponse: &http.Response{StatusCode: http.StatusOK}}
+
+	pass, errs := tc.ApplyExpects(resp, &Context{"consentId": "sdp-1"})
+
+	assert.True(t, pass)
+	assert.Empty(t, errs)
+}
+
+func jwsSignature(header string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "..c2lnbmF0dXJl"
+}
+
+func TestCheckJWSSignatureHeader(t *testing.T) {
+	iat := time.Now().Unix()
+	valid := fmt.Sprintf(`{"alg":"PS256","kid":"DKePOLAOiXLwYhMfLS8aS6YU-d0","b64":false,"http://openbanking.org.uk/iat":%d,"http://openbanking.org.uk/iss":"0015800001041RHAAY","http://openbanking.org.uk/tan":"openbanking.org.uk","crit":["b64","http://openbanking.org.uk/iat","http://openbanking.org.uk/iss","http://openbanking.org.uk/tan"]}`, iat)
+	tests := []struct {
+		name      string
+		signature string
+		args      map[string]string
+		err       string
+	}{
+		{"valid", jwsSignature(valid), map[string]string{"alg": "PS256,ES256", "b64": "false"}, ""},
+		{"missing", "", nil, "x-jws-signature header not found"},
+		{"not detached", "a.b.c", nil, "x-jws-signature is not a detached JWS"},
+		{"alg none", jwsSignature(`{"alg":"none"}`), nil, "alg (none) is not a signing algorithm"},
+		{"alg not allowed", jwsSignature(valid), map[string]string{"alg": "ES256"}, "alg (PS256) is not one of (ES256)"},
+		{"no kid", jwsSignature(`{"alg":"PS256"}`), nil, "kid is missing"},
+		{"claim not critical", jwsSignature(`{"alg":"PS256","kid":"k","http://openbanking.org.uk/iat":1,"http://openbanking.org.uk/iss":"i","http://openbanking.org.uk/tan":"t","crit":["http://openbanking.org.uk/iat"]}`), nil, "claim http://openbanking.org.uk/iss is not critical"},
+		{"iat in the future", jwsSignature(fmt.Sprintf(`{"alg":"PS256","kid":"k","http://openbanking.org.uk/iat":%d,"http://openbanking.org.uk/iss":"i","http://openbanking.org.uk/tan":"t","crit":["http://openbanking.org.uk/iat","http://openbanking.org.uk/iss","http://openbanking.org.uk/tan"]}`, iat+3600)), nil, "claim http://openbanking.org.uk/iat (" + time.Unix(iat+3600, 0).UTC().Format(time.RFC3339) + ") is in the future"},
+		{"b64", jwsSignature(valid), map[string]string{"b64": "true"}, "b64 is false, expected true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := &TestCase{Header: http.Header{}}
+			if tt.signature != "" {
+				tc.Header.Set("x-jws-signature", tt.signature)
+			}
+			err := checkJWSSignatureHeader(CustomCheckInput{Match: &Match{Args: tt.args}, TestCase: tc, Context: &Context{}})
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCheckConsentLinkage(t *testing.T) {
+	tc := &TestCase{
+		Input: Input{RequestBody: `{"Data":{"ConsentId":"sdp-1-b5bbdb18"}}`},
+		Body:  `{"Data":{"ConsentId":"sdp-1-b5bbdb18","DomesticPaymentId":"pv3-1"}}`,
+	}
+	check := CustomCheckInput{Match: &Match{}, TestCase: tc, Context: &Context{"consentId": "sdp-1-other"}}
+	assert.NoError(t, checkConsentLinkage(check))
+
+	check.Match.Args = map[string]string{"context": "consentId"}
+	assert.EqualError(t, checkConsentLinkage(check), "response consent (sdp-1-b5bbdb18) is not the consent requested (sdp-1-other)")
+
+	check.Match.Args = map[string]string{"context": "missing"}
+	assert.EqualError(t, checkConsentLinkage(check), "consent id missing not found in context")
+
+	tc.Body = `{"Data":{}}`
+	assert.EqualError(t, checkConsentLinkage(check), "response has no Data.ConsentId")
+}
+
+func TestCheckAccountLinkage(t *testing.T) {
+	tc := &TestCase{
+		Input: Input{Endpoint: "/accounts/500000000000000000000001/balances"},
+		Body:  `{"Data":{"Balance":[{"AccountId":"500000000000000000000001"},{"AccountId":"500000000000000000000002"}]}}`,
+	}
+	check := CustomCheckInput{Match: &Match{}, TestCase: tc, Context: &Context{"accounts": "500000000000000000000001, 500000000000000000000002"}}
+	assert.EqualError(t, checkAccountLinkage(check), "account (500000000000000000000002) is not one of the accounts requested (500000000000000000000001)")
+
+	check.Match.Args = map[string]string{"context": "accounts"}
+	assert.NoError(t, checkAccountLinkage(check))
+
+	check.Context.PutStringSlice("accounts", []string{"500000000000000000000001"})
+	assert.Error(t, checkAccountLinkage(check))
+
+	check.Match.Args = nil
+	tc.Input.Endpoint = "/balances"
+	assert.EqualError(t, checkAccountLinkage(check), "endpoint /balances is not an account endpoint")
+}
+
+func TestCheckDateWindow(t *testing.T) {
+	tc := &TestCase{
+		Input: Input{Endpoint: "/accounts/1/transactions?fromBookingDateTime=2020-03-01T00:00:00", QueryParameters: map[string]string{"toBookingDateTime": "2020-03-31T23:59:59"}},
+		Body:  `{"Data":{"Transaction":[{"BookingDateTime":"2020-03-01T10:00:00+00:00"},{"BookingDateTime":"2020-03-31T10:00:00+00:00"}]}}`,
+	}
+	check := CustomCheckInput{Match: &Match{}, TestCase: tc, Context: &Context{}}
+	assert.NoError(t, checkDateWindow(check))
+
+	tc.Body = `{"Data":{"Transaction":[{"BookingDateTime":"2020-04-01T10:00:00+00:00"}]}}`
+	assert.EqualError(t, checkDateWindow(check), "(2020-04-01T10:00:00+00:00) is after toBookingDateTime (2020-03-31T23:59:59Z)")
+
+	tc.Body = `{"Data":{"Statement":[{"StartDateTime":"2020-02-01"}]}}`
+	check.Match.Args = map[string]string{"jsonpath": "$.Data.Statement[*].StartDateTime", "from": "fromStatementDateTime"}
+	check.Request = resty.R().SetQueryParam("fromStatementDateTime", "2020-02-02")
+	assert.EqualError(t, checkDateWindow(check), "(2020-02-01) is before fromStatementDateTime (2020-02-02T00:00:00Z)")
+}
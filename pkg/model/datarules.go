@@ -0,0 +1,324 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// DataRuleViolation describes a value of a response which breaks one of the OB data rules.
+// Schema validation only checks the structure of a response, data rules check that its values make sense.
+type DataRuleViolation struct {
+	Rule    string `json:"rule"`    // Name of the rule violated
+	Path    string `json:"path"`    // Json path of the offending value
+	Message string `json:"message"` // What is wrong with the value
+}
+
+func (v DataRuleViolation) Error() string {
+	return fmt.Sprintf("data rule %s violated at %s: %s", v.Rule, v.Path, v.Message)
+}
+
+// dataRule checks a single record of a resource, found at path within the response body
+type dataRule func(t *TestCase, record gjson.Result, path string) []DataRuleViolation
+
+// resourceRules lists the rules applied to each of the records of a resource
+type resourceRules struct {
+	records string // Json path of the records, either an array or a single object
+	rules   []dataRule
+}
+
+// resourceDataRules holds the built-in data rules for each manifest resource
+var resourceDataRules = map[string]resourceRules{
+	"Balance": {
+		records: "Data.Balance",
+		rules: []dataRule{
+			accountIDRule,
+			currencyRule("Amount.Currency"),
+			amountRule("Amount.Amount"),
+			enumRule("CreditDebitIndicator", "Credit", "Debit"),
+			currencyRule("CreditLine.#.Amount.Currency"),
+			amountRule("CreditLine.#.Amount.Amount"),
+		},
+	},
+	"Transaction": {
+		records: "Data.Transaction",
+		rules: []dataRule{
+			accountIDRule,
+			currencyRule("Amount.Currency"),
+			amountRule("Amount.Amount"),
+			enumRule("CreditDebitIndicator", "Credit", "Debit"),
+			currencyRule("ChargeAmount.Currency"),
+			amountRule("ChargeAmount.Amount"),
+			currencyRule("Balance.Amount.Currency"),
+			currencyRule("CurrencyExchange.SourceCurrency"),
+			currencyRule("CurrencyExchange.TargetCurrency"),
+			bookingWindowRule("BookingDateTime"),
+		},
+	},
+	"StandingOrder": {
+		records: "Data.StandingOrder",
+		rules: []dataRule{
+			accountIDRule,
+			currencyRule("FirstPaymentAmount.Currency"),
+			amountRule("FirstPaymentAmount.Amount"),
+			currencyRule("NextPaymentAmount.Currency"),
+			amountRule("NextPaymentAmount.Amount"),
+			currencyRule("LastPaymentAmount.Currency"),
+			amountRule("LastPaymentAmount.Amount"),
+			currencyRule("FinalPaymentAmount.Currency"),
+			amountRule("FinalPaymentAmount.Amount"),
+			dateOrderRule("FirstPaymentDateTime", "NextPaymentDateTime"),
+			dateOrderRule("FirstPaymentDateTime", "FinalPaymentDateTime"),
+		},
+	},
+	"ScheduledPayment": {
+		records: "Data.ScheduledPayment",
+		rules: []dataRule{
+			accountIDRule,
+			currencyRule("InstructedAmount.Currency"),
+			amountRule("InstructedAmount.Amount"),
+			enumRule("ScheduledType", "Arrival", "Execution"),
+			dateRule("ScheduledPaymentDateTime"),
+		},
+	},
+	"Party": {
+		records: "Data.Party",
+		rules: []dataRule{
+			requiredRule("PartyId"),
+			emailRule("EmailAddress"),
+			countryCodeRule("Address.#.CountryCode"),
+		},
+	},
+}
+
+// iso4217Currencies lists the active ISO 4217 currency codes
+var iso4217Currencies = toSet(strings.Fields(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN BZD
+	CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP
+	GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW
+	KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN
+	NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL
+	SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES
+	VND VUV WST XAF XAG XAU XBA XBB XBC XBD XCD XDR XOF XPD XPF XPT XSU XTS XUA XXX YER ZAR ZMW ZWL`))
+
+// iso3166Countries lists the officially assigned ISO 3166-1 alpha-2 country codes
+var iso3166Countries = toSet(strings.Fields(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL
+	BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV
+	CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD
+	GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM
+	IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK
+	LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW
+	MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR
+	PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS
+	ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY
+	UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`))
+
+var (
+	obAmountRegex      = regexp.MustCompile(`^\d{1,13}(\.\d{1,5})?$`)
+	accountIDPathRegex = regexp.MustCompile(`/accounts/([^/?]+)/`)
+)
+
+// ValidateDataRules applies the built-in data rules of the testcase resource to a response body.
+// Each violation is returned as a DataRuleViolation.
+func (t *TestCase) ValidateDataRules(body string) []error {
+	resource, exists := resourceDataRules[t.Resource]
+	if !exists {
+		return nil
+	}
+
+	errs := []error{}
+	records := gjson.Get(body, resource.records)
+	check := func(record gjson.Result, path string) {
+		for _, rule := range resource.rules {
+			for _, violation := range rule(t, record, path) {
+				errs = append(errs, violation)
+			}
+		}
+	}
+
+	if records.IsArray() {
+		for i, record := range records.Array() {
+			check(record, fmt.Sprintf("%s.%d", resource.records, i))
+		}
+	} else if records.IsObject() {
+		check(records, resource.records)
+	}
+	return errs
+}
+
+// fieldValue is a value found in a record along with its full json path
+type fieldValue struct {
+	path  string
+	value gjson.Result
+}
+
+// fieldValues returns the values of field within record. A field may contain a single `#`
+// to select the values of every element of an array.
+func fieldValues(record gjson.Result, path, field string) []fieldValue {
+	parts := strings.SplitN(field, ".#.", 2)
+	if len(parts) == 1 {
+		value := record.Get(field)
+		if !value.Exists() {
+			return nil
+		}
+		return []fieldValue{{path: path + "." + field, value: value}}
+	}
+
+	values := []fieldValue{}
+	for i, element := range record.Get(parts[0]).Array() {
+		value := element.Get(parts[1])
+		if value.Exists() {
+			values = append(values, fieldValue{path: fmt.Sprintf("%s.%s.%d.%s", path, parts[0], i, parts[1]), value: value})
+		}
+	}
+	return values
+}
+
+func currencyRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			if !iso4217Currencies[fv.value.String()] {
+				violations = append(violations, DataRuleViolation{Rule: "Currency", Path: fv.path, Message: fmt.Sprintf("%q is not an ISO 4217 currency code", fv.value.String())})
+			}
+		}
+		return violations
+	}
+}
+
+// amountRule checks amounts are unsigned, as their direction is given by a CreditDebitIndicator
+func amountRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			amount := fv.value.String()
+			if strings.HasPrefix(amount, "-") {
+				indicator := record.Get("CreditDebitIndicator").String()
+				violations = append(violations, DataRuleViolation{Rule: "Amount", Path: fv.path, Message: fmt.Sprintf("negative amount %s, the sign must be given by CreditDebitIndicator (%s)", amount, indicator)})
+				continue
+			}
+			if !obAmountRegex.MatchString(amount) {
+				violations = append(violations, DataRuleViolation{Rule: "Amount", Path: fv.path, Message: fmt.Sprintf("%q is not a valid amount", amount)})
+			}
+		}
+		return violations
+	}
+}
+
+func enumRule(field string, allowed ...string) dataRule {
+	values := toSet(allowed)
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			if !values[fv.value.String()] {
+				violations = append(violations, DataRuleViolation{Rule: "Code", Path: fv.path, Message: fmt.Sprintf("%q is not one of %s", fv.value.String(), strings.Join(allowed, ", "))})
+			}
+		}
+		return violations
+	}
+}
+
+func requiredRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		if strings.TrimSpace(record.Get(field).String()) == "" {
+			return []DataRuleViolation{{Rule: "Required", Path: path + "." + field, Message: "value is missing or empty"}}
+		}
+		return nil
+	}
+}
+
+func emailRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			email := fv.value.String()
+			at := strings.Index(email, "@")
+			if at < 1 || at == len(email)-1 {
+				violations = append(violations, DataRuleViolation{Rule: "EmailAddress", Path: fv.path, Message: fmt.Sprintf("%q is not an email address", email)})
+			}
+		}
+		return violations
+	}
+}
+
+func countryCodeRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			if !iso3166Countries[fv.value.String()] {
+				violations = append(violations, DataRuleViolation{Rule: "CountryCode", Path: fv.path, Message: fmt.Sprintf("%q is not an ISO 3166-1 alpha-2 country code", fv.value.String())})
+			}
+		}
+		return violations
+	}
+}
+
+func dateRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			if _, err := parseDate(fv.value.String()); err != nil {
+				violations = append(violations, DataRuleViolation{Rule: "DateTime", Path: fv.path, Message: fmt.Sprintf("%q is not an ISO 8601 date time", fv.value.String())})
+			}
+		}
+		return violations
+	}
+}
+
+// dateOrderRule checks that the date in earlier is not after the date in later, when both are present
+func dateOrderRule(earlier, later string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		first, errFirst := parseDate(record.Get(earlier).String())
+		second, errSecond := parseDate(record.Get(later).String())
+		if errFirst != nil || errSecond != nil {
+			return nil
+		}
+		if first.After(second) {
+			return []DataRuleViolation{{Rule: "DateOrder", Path: path + "." + later, Message: fmt.Sprintf("%s (%s) is before %s (%s)", later, record.Get(later).String(), earlier, record.Get(earlier).String())}}
+		}
+		return nil
+	}
+}
+
+// bookingWindowRule checks a date falls within the fromBookingDateTime and toBookingDateTime query
+// parameters of the request
+func bookingWindowRule(field string) dataRule {
+	return func(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+		window, err := bookingWindow(requestQuery(t, t.Request))
+		if err != nil || window.unbounded() {
+			return nil // the window requested is the suite's, not the implementation's
+		}
+
+		violations := []DataRuleViolation{}
+		for _, fv := range fieldValues(record, path, field) {
+			if err := window.check(fv.value.String()); err != nil {
+				violations = append(violations, DataRuleViolation{Rule: "BookingWindow", Path: fv.path, Message: err.Error()})
+			}
+		}
+		return violations
+	}
+}
+
+// accountIDRule checks that records returned for /accounts/{AccountId}/... belong to that account
+func accountIDRule(t *TestCase, record gjson.Result, path string) []DataRuleViolation {
+	match := accountIDPathRegex.FindStringSubmatch(t.Input.Endpoint)
+	if match == nil {
+		return nil
+	}
+	accountID := record.Get("AccountId")
+	if accountID.Exists() && accountID.String() != match[1] {
+		return []DataRuleViolation{{Rule: "AccountId", Path: path + ".AccountId", Message: fmt.Sprintf("%q does not match the requested account %q", accountID.String(), match[1])}}
+	}
+	return nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+)
+
+func violations(t *testing.T, errs []error) []DataRuleViolation {
+	result := []DataRuleViolation{}
+	for _, err := range errs {
+		violation, ok := err.(DataRuleViolation)
+		require.True(t, ok, "%v is not a DataRuleViolation", err)
+		result = append(result, violation)
+	}
+	return result
+}
+
+func TestValidateDataRulesUnknownResource(t *testing.T) {
+	tc := TestCase{Resource: "DomesticPayment"}
+	assert.Empty(t, tc.ValidateDataRules(`{"Data":{"Balance":[{"Amount":{"Currency":"XYZ"}}]}}`))
+}
+
+func TestValidateDataRulesBalance(t *testing.T) {
+	tc := TestCase{Resource: "Balance", Input: Input{Endpoint: "https://aspsp.example.com/accounts/acc-1/balances"}}
+
+	good := `{"Data":{"Balance":[{"AccountId":"acc-1","Amount":{"Amount":"10.50","Currency":"GBP"},"CreditDebitIndicator":"Credit","CreditLine":[{"Amount":{"Amount":"100.00","Currency":"GBP"}}]}]}}`
+	assert.Empty(t, tc.ValidateDataRules(good))
+
+	bad := `{"Data":{"Balance":[
+		{"AccountId":"acc-1","Amount":{"Amount":"10.50","Currency":"GBP"},"CreditDebitIndicator":"Credit"},
+		{"AccountId":"acc-2","Amount":{"Amount":"-10.50","Currency":"GBX"},"CreditDebitIndicator":"Credit","CreditLine":[{"Amount":{"Amount":"1","Currency":"gbp"}}]}]}}`
+	got := violations(t, tc.ValidateDataRules(bad))
+	require.Len(t, got, 4)
+	assert.Equal(t, DataRuleViolation{Rule: "AccountId", Path: "Data.Balance.1.AccountId", Message: `"acc-2" does not match the requested account "acc-1"`}, got[0])
+	assert.Equal(t, "Data.Balance.1.Amount.Currency", got[1].Path)
+	assert.Equal(t, "Data.Balance.1.Amount.Amount", got[2].Path)
+	assert.Equal(t, "negative amount -10.50, the sign must be given by CreditDebitIndicator (Credit)", got[2].Message)
+	assert.Equal(t, "Data.Balance.1.CreditLine.0.Amount.Currency", got[3].Path)
+	assert.EqualError(t, got[1], `data rule Currency violated at Data.Balance.1.Amount.Currency: "GBX" is not an ISO 4217 currency code`)
+}
+
+func TestValidateDataRulesTransactionBookingWindow(t *testing.T) {
+	tc := TestCase{Resource: "Transaction", Input: Input{Endpoint: "https://aspsp.example.com/accounts/acc-1/transactions"}}
+	tc.Request = resty.R().
+		SetQueryParam("fromBookingDateTime", "2020-01-01T00:00:00").
+		SetQueryParam("toBookingDateTime", "2020-01-31T00:00:00")
+
+	body := `{"Data":{"Transaction":[
+		{"AccountId":"acc-1","Amount":{"Amount":"1.00","Currency":"GBP"},"CreditDebitIndicator":"Debit","BookingDateTime":"2020-01-10T10:00:00+00:00"},
+		{"AccountId":"acc-1","Amount":{"Amount":"1.00","Currency":"GBP"},"CreditDebitIndicator":"Sideways","BookingDateTime":"2020-02-10T10:00:00+00:00"}]}}`
+	got := violations(t, tc.ValidateDataRules(body))
+	require.Len(t, got, 2)
+	assert.Equal(t, "Data.Transaction.1.CreditDebitIndicator", got[0].Path)
+	assert.Equal(t, "BookingWindow", got[1].Rule)
+	assert.Equal(t, "Data.Transaction.1.BookingDateTime", got[1].Path)
+}
+
+func TestValidateDataRulesStandingOrderAndScheduledPayment(t *testing.T) {
+	tc := TestCase{Resource: "StandingOrder"}
+	body := `{"Data":{"StandingOrder":[{"FirstPaymentDateTime":"2021-01-10T00:00:00Z","FinalPaymentDateTime":"2020-01-10T00:00:00Z","FirstPaymentAmount":{"Amount":"5.00","Currency":"EUR"}}]}}`
+	got := violations(t, tc.ValidateDataRules(body))
+	require.Len(t, got, 1)
+	assert.Equal(t, DataRuleViolation{Rule: "DateOrder", Path: "Data.StandingOrder.0.FinalPaymentDateTime", Message: "FinalPaymentDateTime (2020-01-10T00:00:00Z) is before FirstPaymentDateTime (2021-01-10T00:00:00Z)"}, got[0])
+
+	tc = TestCase{Resource: "ScheduledPayment"}
+	body = `{"Data":{"ScheduledPayment":[{"ScheduledType":"Later","ScheduledPaymentDateTime":"tomorrow","InstructedAmount":{"Amount":"5.00","Currency":"GBP"}}]}}`
+	got = violations(t, tc.ValidateDataRules(body))
+	require.Len(t, got, 2)
+	assert.Equal(t, "Data.ScheduledPayment.0.ScheduledType", got[0].Path)
+	assert.Equal(t, "Data.ScheduledPayment.0.ScheduledPaymentDateTime", got[1].Path)
+}
+
+func TestValidateDataRulesParty(t *testing.T) {
+	tc := TestCase{Resource: "Party"}
+	single := `{"Data":{"Party":{"PartyId":"p-1","EmailAddress":"someone@example.com","Address":[{"CountryCode":"GB"}]}}}`
+	assert.Empty(t, tc.ValidateDataRules(single))
+
+	many := `{"Data":{"Party":[{"PartyId":"","EmailAddress":"nobody","Address":[{"CountryCode":"GB"},{"CountryCode":"GBR"}]}]}}`
+	got := violations(t, tc.ValidateDataRules(many))
+	require.Len(t, got, 3)
+	assert.Equal(t, "Data.Party.0.PartyId", got[0].Path)
+	assert.Equal(t, "Data.Party.0.EmailAddress", got[1].Path)
+	assert.Equal(t, "Data.Party.0.Address.1.CountryCode", got[2].Path)
+
+	unassigned := `{"Data":{"Party":{"PartyId":"p-1","Address":[{"CountryCode":"FR"},{"CountryCode":"UK"},{"CountryCode":"XX"}]}}}`
+	got = violations(t, tc.ValidateDataRules(unassigned))
+	require.Len(t, got, 2, "two upper-case letters which are not an assigned country code")
+	assert.Equal(t, "Data.Party.Address.1.CountryCode", got[0].Path)
+	assert.Equal(t, `"XX" is not an ISO 3166-1 alpha-2 country code`, got[1].Message)
+}
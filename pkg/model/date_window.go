@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"gopkg.in/resty.v1"
+)
+
+// dateLayouts lists the formats of the dates compared by matches, custom checks, data rules and paging,
+// in responses and in query parameters. Dates without a zone are taken as UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses a date-time, with or without offset, or a date
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("(%s) is not a date", value)
+}
+
+// dateWindow is the period requested by a pair of query parameters, by default `fromBookingDateTime` and
+// `toBookingDateTime`. A bound which is not requested is not checked.
+type dateWindow struct {
+	fromParam, toParam string
+	from, to           *time.Time
+}
+
+// newDateWindow returns the window requested by the query parameters fromParam and toParam
+func newDateWindow(query url.Values, fromParam, toParam string) (dateWindow, error) {
+	w := dateWindow{fromParam: fromParam, toParam: toParam}
+	for _, bound := range []struct {
+		param string
+		date  **time.Time
+	}{{fromParam, &w.from}, {toParam, &w.to}} {
+		value := query.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		date, err := parseDate(value)
+		if err != nil {
+			return dateWindow{}, fmt.Errorf("query parameter %s: %s", bound.param, err.Error())
+		}
+		*bound.date = &date
+	}
+	return w, nil
+}
+
+// bookingWindow returns the window requested by the `fromBookingDateTime` and `toBookingDateTime` query parameters
+func bookingWindow(query url.Values) (dateWindow, error) {
+	return newDateWindow(query, "fromBookingDateTime", "toBookingDateTime")
+}
+
+// unbounded is true when neither bound of the window is requested
+func (w dateWindow) unbounded() bool {
+	return w.from == nil && w.to == nil
+}
+
+// check returns why value is not a date within the window, nil when it is
+func (w dateWindow) check(value string) error {
+	date, err := parseDate(value)
+	if err != nil {
+		return err
+	}
+	if w.from != nil && date.Before(*w.from) {
+		return fmt.Errorf("(%s) is before %s (%s)", value, w.fromParam, w.from.Format(time.RFC3339))
+	}
+	if w.to != nil && date.After(*w.to) {
+		return fmt.Errorf("(%s) is after %s (%s)", value, w.toParam, w.to.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// requestQuery returns the query parameters of req, and of the test case input when they are not in req,
+// for example before the request is created
+func requestQuery(tc *TestCase, req *resty.Request) url.Values {
+	query := url.Values{}
+	if u, err := url.Parse(tc.Input.Endpoint); err == nil {
+		query = u.Query()
+	}
+	for k, v := range tc.Input.QueryParameters {
+		query.Set(k, v)
+	}
+	if req != nil {
+		for k, v := range req.QueryParam {
+			query[k] = v
+		}
+	}
+	return query
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/resty.v1"
+)
+
+func TestParseDate(t *testing.T) {
+	for _, value := range []string{"2020-01-31T10:00:00.123+01:00", "2020-01-31T10:00:00.123", "2020-01-31T10:00:00", "2020-01-31"} {
+		_, err := parseDate(value)
+		assert.NoError(t, err, value)
+	}
+	_, err := parseDate("31/01/2020")
+	assert.EqualError(t, err, "(31/01/2020) is not a date")
+}
+
+func TestBookingWindow(t *testing.T) {
+	window, err := bookingWindow(url.Values{"fromBookingDateTime": {"2020-01-01T00:00:00"}})
+	require.NoError(t, err)
+	assert.False(t, window.unbounded())
+	assert.NoError(t, window.check("2030-01-01"), "the end of the window is not requested")
+	assert.EqualError(t, window.check("2019-12-31T23:59:59Z"), "(2019-12-31T23:59:59Z) is before fromBookingDateTime (2020-01-01T00:00:00Z)")
+	assert.EqualError(t, window.check("soon"), "(soon) is not a date")
+
+	window, err = bookingWindow(url.Values{})
+	require.NoError(t, err)
+	assert.True(t, window.unbounded())
+
+	_, err = newDateWindow(url.Values{"toStatementDateTime": {"later"}}, "fromStatementDateTime", "toStatementDateTime")
+	assert.EqualError(t, err, "query parameter toStatementDateTime: (later) is not a date")
+}
+
+func TestRequestQuery(t *testing.T) {
+	tc := &TestCase{Input: Input{Endpoint: "/transactions?fromBookingDateTime=2020-01-01&page=2", QueryParameters: map[string]string{"toBookingDateTime": "2020-01-31"}}}
+	assert.Equal(t, url.Values{"fromBookingDateTime": {"2020-01-01"}, "page": {"2"}, "toBookingDateTime": {"2020-01-31"}}, requestQuery(tc, nil))
+
+	query := requestQuery(tc, resty.R().SetQueryParam("toBookingDateTime", "2020-02-29"))
+	assert.Equal(t, "2020-02-29", query.Get("toBookingDateTime"), "the request sent wins over the input")
+}
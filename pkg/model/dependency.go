@@ -0,0 +1,71 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+)
+
+// contextReferenceRegex matches the context values referenced by a test case or a script, e.g.
+// `$OB-301-DOP-100100-ConsentId`
+var contextReferenceRegex = regexp.MustCompile(`\$([a-zA-Z0-9_-]+)`)
+
+// ContextPuts lists the names of the context values the test case puts on success, with `keepContextOnSuccess`
+func (t *TestCase) ContextPuts() []string {
+	names := []string{}
+	expects := append([]Expect{t.Expect}, t.ExpectOneOf...)
+	expects = append(expects, t.ExpectLastIfAll...)
+	for _, expect := range expects {
+		for _, match := range expect.ContextPut.Matches {
+			if match.ContextName != "" && !containsString(names, match.ContextName) {
+				names = append(names, match.ContextName)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// ContextReferences lists, sorted, the names of the context values the test case uses in its input, bearer
+// token and local context
+func (t *TestCase) ContextReferences() []string {
+	values := []string{t.Input.Endpoint, t.Input.RequestBody, t.Bearer}
+	for _, params := range []map[string]string{t.Input.Headers, t.Input.QueryParameters, t.Input.FormData, t.Input.Claims} {
+		for _, value := range params {
+			values = append(values, value)
+		}
+	}
+	for _, value := range t.Context {
+		if value, ok := value.(string); ok {
+			values = append(values, value)
+		}
+	}
+
+	names := ContextReferences(values...)
+	sort.Strings(names)
+	return names
+}
+
+// ContextReferences lists, in the order they first appear, the names of the context values referenced in values,
+// e.g. `ConsentId` for `/domestic-payment-consents/$ConsentId`
+func ContextReferences(values ...string) []string {
+	names := []string{}
+	for _, value := range values {
+		for _, match := range contextReferenceRegex.FindAllStringSubmatch(value, -1) {
+			if !containsString(names, match[1]) {
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestCaseContextPuts(t *testing.T) {
+	tc := TestCase{
+		Expect:          Expect{ContextPut: ContextAccessor{Matches: []Match{{ContextName: "OB-301-DOP-100100-ConsentId"}}}},
+		ExpectOneOf:     []Expect{{ContextPut: ContextAccessor{Matches: []Match{{ContextName: "OB-301-DOP-100100-ConsentId"}, {ContextName: "status"}}}}},
+		ExpectLastIfAll: []Expect{{ContextPut: ContextAccessor{Matches: []Match{{ContextName: "last"}}}}},
+	}
+
+	assert.Equal(t, []string{"OB-301-DOP-100100-ConsentId", "status", "last"}, tc.ContextPuts())
+	assert.Nil(t, (&TestCase{}).ContextPuts())
+}
+
+func TestTestCaseContextReferences(t *testing.T) {
+	tc := TestCase{
+		Input: Input{
+			Endpoint:        "/domestic-payments/$OB-301-DOP-100600-DomesticPaymentId",
+			RequestBody:     `{"ConsentId": "$OB-301-DOP-100100-ConsentId"}`,
+			Headers:         map[string]string{"x-fapi-financial-id": "$x-fapi-financial-id"},
+			QueryParameters: map[string]string{"fromBookingDateTime": "$transactionFromDate"},
+			FormData:        map[string]string{"client_id": "$client_id"},
+			Claims:          map[string]string{"aud": "$issuer"},
+		},
+		Bearer:  "$paymentToken0001",
+		Context: Context{"consentId": "$OB-301-DOP-100100-ConsentId", "permissions": []string{"$ignored"}},
+	}
+
+	assert.Equal(t, []string{
+		"OB-301-DOP-100100-ConsentId",
+		"OB-301-DOP-100600-DomesticPaymentId",
+		"client_id",
+		"issuer",
+		"paymentToken0001",
+		"transactionFromDate",
+		"x-fapi-financial-id",
+	}, tc.ContextReferences())
+}
+
+func TestContextReferences(t *testing.T) {
+	names := ContextReferences("/domestic-payment-consents/$OB-301-DOP-100100-ConsentId", `{"Amount": "$amount", "ConsentId": "$OB-301-DOP-100100-ConsentId"}`, "$fn:nextDayDateTime()")
+
+	assert.Equal(t, []string{"OB-301-DOP-100100-ConsentId", "amount", "fn"}, names)
+	assert.Empty(t, ContextReferences("/accounts"))
+}
@@ -86,8 +86,8 @@ func (i *Input) CreateRequest(tc *TestCase, ctx *Context) (*resty.Request, error
 		req.SetBody(body)
 	}
 
-	for k, v := range i.QueryParameters {
-		req.QueryParam.Add(k, v)
+	if err = i.setQueryParameters(req, ctx); err != nil {
+		return nil, err
 	}
 
 	if i.JwsSig {
@@ -194,7 +194,6 @@ func consentURL(authEndpoint string, claims map[string]string, token string) str
 	logrus.WithFields(logrus.Fields{
 		"claims":       claims,
 		"authEndpoint": authEndpoint,
-		"token":        token,
 		"consentURL":   consentURL,
 	}).Trace("Generating consentURL")
 
@@ -261,6 +260,17 @@ func (i *Input) setHeaders(req *resty.Request, ctx *Context) error {
 	return nil
 }
 
+func (i *Input) setQueryParameters(req *resty.Request, ctx *Context) error {
+	for k, v := range i.QueryParameters { // set any input query parameters ("queryParameters")
+		value, err := replaceContextField(v, ctx)
+		if err != nil {
+			return i.AppErr(fmt.Sprintf("setQueryParameters Replaced Context value %s :%s", v, err.Error()))
+		}
+		req.QueryParam.Add(k, value)
+	}
+	return nil
+}
+
 func (i *Input) createJWSDetachedSignature(ctx authentication.ContextInterface) error {
 	if len(i.RequestBody) == 0 {
 		return i.AppErr("cannot create x-jws-signature, as request body is empty")
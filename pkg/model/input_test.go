@@ -166,6 +166,16 @@ func TestQueryParam(t *testing.T) {
 	assert.Equal(t, 2, len(req.QueryParam))
 }
 
+func TestQueryParamContextReplacement(t *testing.T) {
+	i := Input{Endpoint: "/accounts", Method: "GET", QueryParameters: map[string]string{
+		"fromBookingDateTime": "$transactionFromDate"}}
+	ctx := Context{"baseurl": "http://mybaseurl", "transactionFromDate": "2016-01-01T10:40:00+02:00"}
+	tc := TestCase{Input: i, Context: ctx}
+	req, err := tc.Prepare(&Context{"phase": "run"})
+	require.NoError(t, err)
+	assert.Equal(t, "2016-01-01T10:40:00+02:00", req.QueryParam.Get("fromBookingDateTime"))
+}
+
 func TestFormDataMissingContextVariable(t *testing.T) {
 	ctx1 := Context{"phase": "run"}
 	i := Input{Endpoint: "/accounts", Method: "POST", FormData: map[string]string{
@@ -0,0 +1,846 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxJSONPathDepth bounds the nesting of filter expressions
+const maxJSONPathDepth = 32
+
+// JSONPathError is an error in a JSONPath, at the byte offset Pos
+type JSONPathError struct {
+	Path string
+	Pos  int
+	Msg  string
+}
+
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("jsonpath %q, column %d: %s", e.Path, e.Pos+1, e.Msg)
+}
+
+// JSONPath is a compiled JSONPath query, as defined by RFC 9535:
+//
+//	$.Data.Account[0].AccountId              child members and array indexes, negative from the end
+//	$.Data.Balance[*].Amount                 all the items of an array, or members of an object
+//	$..AccountId                             descendants
+//	$.Data.Transaction[0:10:2]               array slices
+//	$.Data.Account['AccountId','Currency']   several selectors
+//	$.Data.Balance[?@.Type == 'Expected']    filters, the Goessner ?(...) form is accepted too
+//
+// Filters compare with == != < <= > >=, combine with && || ! and call the functions length, count, match,
+// search and value. `@` is the node filtered and `$` the document.
+type JSONPath struct {
+	source   string
+	segments []jsonPathSegment
+}
+
+// CompileJSONPath parses a JSONPath query
+func CompileJSONPath(path string) (*JSONPath, error) {
+	p := &jsonPathParser{source: path}
+	p.skipSpaces()
+	if p.peek() != '$' {
+		return nil, p.unexpected("$")
+	}
+	p.pos++
+	segments, err := p.parseSegments(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.source) {
+		return nil, p.errorf(p.pos, "unexpected %q", p.source[p.pos])
+	}
+	return &JSONPath{source: path, segments: segments}, nil
+}
+
+func (j *JSONPath) String() string {
+	return j.source
+}
+
+// Select returns the nodes of document, as unmarshalled by encoding/json, selected by the query, in document
+// order. Object members are visited sorted by name.
+func (j *JSONPath) Select(document interface{}) []interface{} {
+	return selectJSONPath(j.segments, document, document)
+}
+
+func selectJSONPath(segments []jsonPathSegment, node, root interface{}) []interface{} {
+	nodes := []interface{}{node}
+	for _, segment := range segments {
+		selected := []interface{}{}
+		for _, n := range nodes {
+			selected = append(selected, segment.apply(n, root)...)
+		}
+		nodes = selected
+	}
+	return nodes
+}
+
+type jsonPathSegment struct {
+	descendant bool
+	selectors  []jsonPathSelector
+}
+
+func (s jsonPathSegment) apply(node, root interface{}) []interface{} {
+	selected := []interface{}{}
+	visit := []interface{}{node}
+	if s.descendant {
+		visit = descendants(node, visit[:0])
+	}
+	for _, n := range visit {
+		for _, selector := range s.selectors {
+			selected = append(selected, selector.apply(n, root)...)
+		}
+	}
+	return selected
+}
+
+// descendants appends node and its descendants, depth first
+func descendants(node interface{}, nodes []interface{}) []interface{} {
+	nodes = append(nodes, node)
+	for _, child := range children(node) {
+		nodes = descendants(child, nodes)
+	}
+	return nodes
+}
+
+// children are the items of an array or the members of an object, sorted by name
+func children(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case []interface{}:
+		return n
+	case map[string]interface{}:
+		names := make([]string, 0, len(n))
+		for name := range n {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		values := make([]interface{}, len(names))
+		for i, name := range names {
+			values[i] = n[name]
+		}
+		return values
+	}
+	return nil
+}
+
+type jsonPathSelector interface {
+	apply(node, root interface{}) []interface{}
+}
+
+type nameSelector struct {
+	name string
+}
+
+func (s nameSelector) apply(node, _ interface{}) []interface{} {
+	if object, ok := node.(map[string]interface{}); ok {
+		if value, exists := object[s.name]; exists {
+			return []interface{}{value}
+		}
+	}
+	return nil
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(node, _ interface{}) []interface{} {
+	return children(node)
+}
+
+type indexSelector struct {
+	index int
+}
+
+func (s indexSelector) apply(node, _ interface{}) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+	index := s.index
+	if index < 0 {
+		index += len(array)
+	}
+	if index < 0 || index >= len(array) {
+		return nil
+	}
+	return []interface{}{array[index]}
+}
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(node, _ interface{}) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok || s.step == 0 {
+		return nil
+	}
+	bound := func(index *int, def int) int {
+		if index == nil {
+			return def
+		}
+		if *index < 0 {
+			return *index + len(array)
+		}
+		return *index
+	}
+	clamp := func(i, lower, upper int) int {
+		if i < lower {
+			return lower
+		}
+		if i > upper {
+			return upper
+		}
+		return i
+	}
+
+	selected := []interface{}{}
+	if s.step > 0 {
+		start := clamp(bound(s.start, 0), 0, len(array))
+		end := clamp(bound(s.end, len(array)), 0, len(array))
+		for i := start; i < end; i += s.step {
+			selected = append(selected, array[i])
+		}
+		return selected
+	}
+	start := clamp(bound(s.start, len(array)-1), -1, len(array)-1)
+	end := clamp(bound(s.end, -len(array)-1), -1, len(array)-1)
+	for i := start; i > end; i += s.step {
+		selected = append(selected, array[i])
+	}
+	return selected
+}
+
+type filterSelector struct {
+	expr filterExpr
+}
+
+func (s filterSelector) apply(node, root interface{}) []interface{} {
+	selected := []interface{}{}
+	for _, child := range children(node) {
+		if s.expr.test(child, root) {
+			selected = append(selected, child)
+		}
+	}
+	return selected
+}
+
+// filterExpr is a logical expression of a filter
+type filterExpr interface {
+	test(current, root interface{}) bool
+}
+
+type orExpr struct {
+	left, right filterExpr
+}
+
+func (x orExpr) test(current, root interface{}) bool {
+	return x.left.test(current, root) || x.right.test(current, root)
+}
+
+type andExpr struct {
+	left, right filterExpr
+}
+
+func (x andExpr) test(current, root interface{}) bool {
+	return x.left.test(current, root) && x.right.test(current, root)
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (x notExpr) test(current, root interface{}) bool {
+	return !x.expr.test(current, root)
+}
+
+// existsExpr is true when the query selects at least one node
+type existsExpr struct {
+	query queryOperand
+}
+
+func (x existsExpr) test(current, root interface{}) bool {
+	return len(x.query.nodes(current, root)) > 0
+}
+
+// logicalCallExpr is a call of match or search
+type logicalCallExpr struct {
+	call callOperand
+}
+
+func (x logicalCallExpr) test(current, root interface{}) bool {
+	value, _ := x.call.value(current, root)
+	result, _ := value.(bool)
+	return result
+}
+
+type comparisonExpr struct {
+	op          string
+	left, right filterOperand
+}
+
+func (x comparisonExpr) test(current, root interface{}) bool {
+	left, leftExists := x.left.value(current, root)
+	right, rightExists := x.right.value(current, root)
+	switch x.op {
+	case "==":
+		return jsonPathEqual(left, leftExists, right, rightExists)
+	case "!=":
+		return !jsonPathEqual(left, leftExists, right, rightExists)
+	case "<":
+		return leftExists && rightExists && jsonPathLess(left, right)
+	case ">":
+		return leftExists && rightExists && jsonPathLess(right, left)
+	case "<=":
+		return leftExists && rightExists && (jsonPathLess(left, right) || jsonPathEqual(left, true, right, true))
+	case ">=":
+		return leftExists && rightExists && (jsonPathLess(right, left) || jsonPathEqual(left, true, right, true))
+	}
+	return false
+}
+
+// jsonPathEqual compares values, a missing value only equals another missing value
+func jsonPathEqual(left interface{}, leftExists bool, right interface{}, rightExists bool) bool {
+	if !leftExists || !rightExists {
+		return leftExists == rightExists
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// jsonPathLess orders numbers and strings, other values are not ordered
+func jsonPathLess(left, right interface{}) bool {
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		return ok && l < r
+	case string:
+		r, ok := right.(string)
+		return ok && l < r
+	}
+	return false
+}
+
+// filterOperand is a value of a comparison or of a function argument, missing when a query selects no
+// node or more than one
+type filterOperand interface {
+	value(current, root interface{}) (interface{}, bool)
+}
+
+type literalOperand struct {
+	literal interface{}
+}
+
+func (o literalOperand) value(_, _ interface{}) (interface{}, bool) {
+	return o.literal, true
+}
+
+type queryOperand struct {
+	relative bool
+	segments []jsonPathSegment
+}
+
+func (o queryOperand) nodes(current, root interface{}) []interface{} {
+	if o.relative {
+		return selectJSONPath(o.segments, current, root)
+	}
+	return selectJSONPath(o.segments, root, root)
+}
+
+func (o queryOperand) value(current, root interface{}) (interface{}, bool) {
+	nodes := o.nodes(current, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+type callOperand struct {
+	name string
+	args []interface{} // filterOperand or queryOperand for count
+}
+
+func (o callOperand) value(current, root interface{}) (interface{}, bool) {
+	switch o.name {
+	case "length":
+		value, exists := o.args[0].(filterOperand).value(current, root)
+		if !exists {
+			return nil, false
+		}
+		switch v := value.(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), true
+		case []interface{}:
+			return float64(len(v)), true
+		case map[string]interface{}:
+			return float64(len(v)), true
+		}
+		return nil, false
+	case "count":
+		return float64(len(o.args[0].(queryOperand).nodes(current, root))), true
+	case "value":
+		return o.args[0].(queryOperand).value(current, root)
+	case "match", "search":
+		value, exists := o.args[0].(filterOperand).value(current, root)
+		pattern, patternExists := o.args[1].(filterOperand).value(current, root)
+		s, ok := value.(string)
+		p, patternOk := pattern.(string)
+		if !exists || !patternExists || !ok || !patternOk {
+			return false, true
+		}
+		if o.name == "match" {
+			p = "^(?:" + p + ")$"
+		}
+		regex, err := regexp.Compile(p)
+		if err != nil {
+			return false, true
+		}
+		return regex.MatchString(s), true
+	}
+	return nil, false
+}
+
+// jsonPathParser is a recursive descent parser of a query:
+//
+//	query      = "$" segments
+//	segments   = { "." name | "." "*" | ".." name | ".." "*" | [".."] "[" selector { "," selector } "]" }
+//	selector   = string | integer | [integer] ":" [integer] [":" [integer]] | "*" | "?" or
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | operand [ ("==" | "!=" | "<" | "<=" | ">" | ">=") operand ]
+//	operand    = string | number | "true" | "false" | "null" | ("@" | "$") segments | name "(" [ operand { "," operand } ] ")"
+type jsonPathParser struct {
+	source string
+	pos    int
+}
+
+func (p *jsonPathParser) errorf(pos int, format string, args ...interface{}) error {
+	return &JSONPathError{Path: p.source, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.pos < len(p.source) && strings.IndexByte(" \t\r\n", p.source[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+// peek returns the next character, 0 at the end of the source. Spaces are not skipped as they are not allowed
+// between the segments of a query.
+func (p *jsonPathParser) peek() byte {
+	if p.pos >= len(p.source) {
+		return 0
+	}
+	return p.source[p.pos]
+}
+
+func (p *jsonPathParser) unexpected(expected string) error {
+	if p.pos >= len(p.source) {
+		return p.errorf(p.pos, "unexpected end, expected %s", expected)
+	}
+	return p.errorf(p.pos, "unexpected %q, expected %s", p.source[p.pos], expected)
+}
+
+func (p *jsonPathParser) expect(c byte) error {
+	p.skipSpaces()
+	if p.peek() != c {
+		return p.unexpected(fmt.Sprintf("%q", c))
+	}
+	p.pos++
+	return nil
+}
+
+func (p *jsonPathParser) parseSegments(depth int) ([]jsonPathSegment, error) {
+	segments := []jsonPathSegment{}
+	for {
+		switch {
+		case strings.HasPrefix(p.source[p.pos:], ".."):
+			p.pos += 2
+			segment, err := p.parseDotted(depth)
+			if err != nil {
+				return nil, err
+			}
+			segment.descendant = true
+			segments = append(segments, segment)
+		case p.peek() == '.':
+			p.pos++
+			segment, err := p.parseDotted(depth)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		case p.peek() == '[':
+			segment, err := p.parseBracketed(depth)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		default:
+			return segments, nil
+		}
+	}
+}
+
+// parseDotted parses the name, wildcard or bracketed selectors after a dot
+func (p *jsonPathParser) parseDotted(depth int) (jsonPathSegment, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return jsonPathSegment{selectors: []jsonPathSelector{wildcardSelector{}}}, nil
+	case c == '[':
+		return p.parseBracketed(depth)
+	case isJSONPathNameChar(c):
+		return jsonPathSegment{selectors: []jsonPathSelector{nameSelector{p.name()}}}, nil
+	}
+	return jsonPathSegment{}, p.unexpected("a member name")
+}
+
+func (p *jsonPathParser) parseBracketed(depth int) (jsonPathSegment, error) {
+	p.pos++ // [
+	segment := jsonPathSegment{}
+	for {
+		selector, err := p.parseSelector(depth)
+		if err != nil {
+			return segment, err
+		}
+		segment.selectors = append(segment.selectors, selector)
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return segment, nil
+		default:
+			return segment, p.unexpected("',' or ']'")
+		}
+	}
+}
+
+func (p *jsonPathParser) parseSelector(depth int) (jsonPathSelector, error) {
+	p.skipSpaces()
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.quoted(c)
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector{name}, nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr}, nil
+	case c == '-' || c == ':' || isTemplateDigit(c):
+		return p.parseIndexOrSlice()
+	}
+	return nil, p.unexpected("a selector")
+}
+
+func (p *jsonPathParser) parseIndexOrSlice() (jsonPathSelector, error) {
+	var bounds [3]*int
+	colons := 0
+	for {
+		p.skipSpaces()
+		if c := p.peek(); c == '-' || isTemplateDigit(c) {
+			start := p.pos
+			p.pos++
+			for isTemplateDigit(p.peek()) {
+				p.pos++
+			}
+			i, err := strconv.Atoi(p.source[start:p.pos])
+			if err != nil {
+				return nil, p.errorf(start, "invalid integer %s", p.source[start:p.pos])
+			}
+			bounds[colons] = &i
+			p.skipSpaces()
+		}
+		if p.peek() != ':' || colons == 2 {
+			break
+		}
+		p.pos++
+		colons++
+	}
+	if colons == 0 {
+		if bounds[0] == nil {
+			return nil, p.unexpected("an index")
+		}
+		return indexSelector{*bounds[0]}, nil
+	}
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	return sliceSelector{start: bounds[0], end: bounds[1], step: step}, nil
+}
+
+func (p *jsonPathParser) parseOr(depth int) (filterExpr, error) {
+	if depth > maxJSONPathDepth {
+		return nil, p.errorf(p.pos, "filter nested too deeply")
+	}
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !strings.HasPrefix(p.source[p.pos:], "||") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+}
+
+func (p *jsonPathParser) parseAnd(depth int) (filterExpr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !strings.HasPrefix(p.source[p.pos:], "&&") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+}
+
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *jsonPathParser) parseUnary(depth int) (filterExpr, error) {
+	if depth > maxJSONPathDepth {
+		return nil, p.errorf(p.pos, "filter nested too deeply")
+	}
+	p.skipSpaces()
+	switch p.peek() {
+	case '!':
+		p.pos++
+		expr, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	case '(':
+		p.pos++
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(')')
+	}
+
+	start := p.pos
+	left, err := p.parseOperand(depth)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, op := range comparisonOperators {
+		if !strings.HasPrefix(p.source[p.pos:], op) {
+			continue
+		}
+		if err := p.checkComparable(start, left); err != nil {
+			return nil, err
+		}
+		p.pos += len(op)
+		p.skipSpaces()
+		rightStart := p.pos
+		right, err := p.parseOperand(depth)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkComparable(rightStart, right); err != nil {
+			return nil, err
+		}
+		return comparisonExpr{op: op, left: left.(filterOperand), right: right.(filterOperand)}, nil
+	}
+
+	switch operand := left.(type) {
+	case queryOperand:
+		return existsExpr{operand}, nil
+	case callOperand:
+		if operand.name == "match" || operand.name == "search" {
+			return logicalCallExpr{operand}, nil
+		}
+	}
+	return nil, p.errorf(start, "expected a comparison, a query or a call of match or search")
+}
+
+// checkComparable checks the operand of a comparison is a value: a literal, a query selecting at most one node or
+// a call of a function returning a value
+func (p *jsonPathParser) checkComparable(pos int, operand interface{}) error {
+	switch o := operand.(type) {
+	case queryOperand:
+		if !o.singular() {
+			return p.errorf(pos, "query compared must select at most one node")
+		}
+	case callOperand:
+		if o.name == "match" || o.name == "search" {
+			return p.errorf(pos, "%s cannot be compared", o.name)
+		}
+	}
+	return nil
+}
+
+// singular is true for queries made of single names and indexes, which select at most one node
+func (o queryOperand) singular() bool {
+	for _, segment := range o.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		switch segment.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// jsonPathFuncs are the functions of filters and their number of parameters
+var jsonPathFuncs = map[string]int{
+	"length": 1,
+	"count":  1,
+	"match":  2,
+	"search": 2,
+	"value":  1,
+}
+
+// parseOperand returns a filterOperand, or a queryOperand which is one when singular
+func (p *jsonPathParser) parseOperand(depth int) (interface{}, error) {
+	p.skipSpaces()
+	start := p.pos
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments(depth)
+		if err != nil {
+			return nil, err
+		}
+		return queryOperand{relative: c == '@', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.quoted(c)
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{s}, nil
+	case c == '-' || isTemplateDigit(c):
+		p.pos++
+		for isTemplateDigit(p.peek()) || strings.IndexByte(".eE+-", p.peek()) != -1 {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.source[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf(start, "invalid number %s", p.source[start:p.pos])
+		}
+		return literalOperand{f}, nil
+	case isJSONPathNameChar(c):
+		name := p.name()
+		switch name {
+		case "true":
+			return literalOperand{true}, nil
+		case "false":
+			return literalOperand{false}, nil
+		case "null":
+			return literalOperand{nil}, nil
+		}
+		return p.parseCall(start, name, depth)
+	}
+	return nil, p.unexpected("a value")
+}
+
+func (p *jsonPathParser) parseCall(start int, name string, depth int) (interface{}, error) {
+	arity, exists := jsonPathFuncs[name]
+	if !exists {
+		return nil, p.errorf(start, "function %s does not exist", name)
+	}
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	call := callOperand{name: name}
+	p.skipSpaces()
+	for p.peek() != ')' {
+		if len(call.args) > 0 {
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		argStart := p.pos
+		arg, err := p.parseOperand(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		query, isQuery := arg.(queryOperand)
+		nested, isCall := arg.(callOperand)
+		switch {
+		case (name == "count" || name == "value") && !isQuery:
+			return nil, p.errorf(argStart, "%s takes a query", name)
+		case isCall && (nested.name == "match" || nested.name == "search"):
+			return nil, p.errorf(argStart, "%s cannot be a parameter", nested.name)
+		case isQuery && name != "count" && name != "value" && !query.singular():
+			return nil, p.errorf(argStart, "query parameter of %s must select at most one node", name)
+		}
+		call.args = append(call.args, arg)
+		p.skipSpaces()
+	}
+	p.pos++
+	if len(call.args) != arity {
+		return nil, p.errorf(start, "function %s takes %d parameters, %d given", name, arity, len(call.args))
+	}
+	return call, nil
+}
+
+func (p *jsonPathParser) name() string {
+	start := p.pos
+	for p.pos < len(p.source) && isJSONPathNameChar(p.source[p.pos]) {
+		p.pos++
+	}
+	return p.source[start:p.pos]
+}
+
+// quoted parses a string in single or double quotes, with backslash escapes
+func (p *jsonPathParser) quoted(quote byte) (string, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.source):
+			p.pos++
+			b.WriteByte(p.source[p.pos])
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorf(start, "unterminated string")
+}
+
+// isJSONPathNameChar accepts the characters of member names used with dots, which in Open Banking
+// payloads are letters, digits, `_` and `-`, and of any non ASCII name
+func isJSONPathNameChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || isTemplateDigit(c)
+}
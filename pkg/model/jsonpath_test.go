@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonPathDocument = `{
+	"Data": {
+		"Balance": [
+			{"AccountId": "22289", "Amount": {"Amount": "1230.00", "Currency": "GBP"}, "CreditDebitIndicator": "Credit", "Type": "InterimAvailable"},
+			{"AccountId": "22289", "Amount": {"Amount": "57.36", "Currency": "GBP"}, "CreditDebitIndicator": "Debit", "Type": "Expected"},
+			{"AccountId": "31820", "Amount": {"Amount": "0.00", "Currency": "EUR"}, "CreditDebitIndicator": "Credit", "Type": "Expected", "CreditLine": [{"Included": true}]}
+		]
+	},
+	"Meta": {"TotalPages": 1}
+}`
+
+func jsonPathSelect(t *testing.T, path string) []interface{} {
+	var document interface{}
+	require.NoError(t, json.Unmarshal([]byte(jsonPathDocument), &document))
+	query, err := CompileJSONPath(path)
+	require.NoError(t, err)
+	return query.Select(document)
+}
+
+func TestJSONPathSelect(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$.Data.Balance[0].AccountId", []interface{}{"22289"}},
+		{"$['Data']['Balance'][-1].Amount.Currency", []interface{}{"EUR"}},
+		{"$.Data.Balance[*].CreditDebitIndicator", []interface{}{"Credit", "Debit", "Credit"}},
+		{"$.Data.Balance[0:2].Type", []interface{}{"InterimAvailable", "Expected"}},
+		{"$.Data.Balance[::-2].Type", []interface{}{"Expected", "InterimAvailable"}},
+		{"$.Data.Balance[0,2].AccountId", []interface{}{"22289", "31820"}},
+		{"$..Currency", []interface{}{"GBP", "GBP", "EUR"}},
+		{"$.Meta.*", []interface{}{float64(1)}},
+		{"$.Data.Balance[?@.Type == 'Expected'].Amount.Amount", []interface{}{"57.36", "0.00"}},
+		{"$.Data.Balance[?(@.Type == 'Expected' && @.Amount.Currency != 'GBP')].AccountId", []interface{}{"31820"}},
+		{"$.Data.Balance[?@.CreditLine].AccountId", []interface{}{"31820"}},
+		{"$.Data.Balance[?!@.CreditLine].AccountId", []interface{}{"22289", "22289"}},
+		{"$.Data.Balance[?@.AccountId == $.Data.Balance[2].AccountId].Type", []interface{}{"Expected"}},
+		{"$.Data.Balance[?match(@.Type, 'Interim.*')].Type", []interface{}{"InterimAvailable"}},
+		{"$.Data.Balance[?search(@.Type, 'ect')].Type", []interface{}{"Expected", "Expected"}},
+		{"$.Data.Balance[?length(@.AccountId) > 4 && count(@.*) < 5].Type", []interface{}{"InterimAvailable", "Expected"}},
+		{"$.Data.Balance[?value(@..Included) == true].AccountId", []interface{}{"31820"}},
+		{"$.Meta[?@ >= 1]", []interface{}{float64(1)}},
+		{"$.Data.Missing[*]", []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, jsonPathSelect(t, tt.path))
+		})
+	}
+}
+
+func TestCompileJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{"Data.Balance", `jsonpath "Data.Balance", column 1: unexpected 'D', expected $`},
+		{"$.Data.", `jsonpath "$.Data.", column 8: unexpected end, expected a member name`},
+		{"$.Data[0", `jsonpath "$.Data[0", column 9: unexpected end, expected ',' or ']'`},
+		{"$.Data['Balance]", `jsonpath "$.Data['Balance]", column 8: unterminated string`},
+		{"$.Data[?@.Type = 'a']", `jsonpath "$.Data[?@.Type = 'a']", column 16: unexpected '=', expected ',' or ']'`},
+		{"$.Data[?@..Type == 'a']", `jsonpath "$.Data[?@..Type == 'a']", column 9: query compared must select at most one node`},
+		{"$.Data[?size(@) > 1]", `jsonpath "$.Data[?size(@) > 1]", column 9: function size does not exist`},
+		{"$.Data[?match(@.Type)]", `jsonpath "$.Data[?match(@.Type)]", column 9: function match takes 2 parameters, 1 given`},
+		{"$.Data[?count('a') > 1]", `jsonpath "$.Data[?count('a') > 1]", column 15: count takes a query`},
+		{"$.Data[?'a']", `jsonpath "$.Data[?'a']", column 9: expected a comparison, a query or a call of match or search`},
+		{"$.Data x", `jsonpath "$.Data x", column 8: unexpected 'x'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := CompileJSONPath(tt.path)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCompileJSONPathNestingIsBounded(t *testing.T) {
+	path := "$[?"
+	for i := 0; i < 100; i++ {
+		path += "("
+	}
+	_, err := CompileJSONPath(path + "@)]")
+	assert.Contains(t, err.Error(), "filter nested too deeply")
+}
@@ -23,6 +23,15 @@ func AddMacro(name string, macro interface{}) {
 	macroMap[name] = macro
 }
 
+// MacroArity returns the number of parameters taken by the macro `name`, found is false when there is no such macro.
+func MacroArity(name string) (arity int, found bool) {
+	macro, found := macroMap[name]
+	if !found {
+		return 0, false
+	}
+	return reflect.ValueOf(macro).Type().NumIn(), true
+}
+
 // ExecuteMacro calls a macro by `name`, with parameters to be passed using `params`. `params` is a collection of strings
 // that get passed as is. Type assertions will need be performed in the macro implementation.
 func ExecuteMacro(name string, params []string) (string, error) {
@@ -68,3 +68,16 @@ func TestExecuteMacro(t *testing.T) {
 		})
 	}
 }
+
+func TestMacroArity(t *testing.T) {
+	arity, found := MacroArity("nextDayDateTime")
+	assert.True(t, found)
+	assert.Equal(t, 1, arity)
+
+	arity, found = MacroArity("instructionIdentificationID")
+	assert.True(t, found)
+	assert.Equal(t, 0, arity)
+
+	_, found = MacroArity("missingFunction")
+	assert.False(t, found)
+}
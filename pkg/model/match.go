@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/tracer"
 	"github.com/tidwall/gjson"
@@ -32,6 +33,9 @@ const (
 	BodyJSONNotPresent
 	BodyJSONPresences
 	BodyJSONNotPresences
+	ResponseTimeMax
+	BodyJSONPath
+	BodyJSONSchema
 	arrayJSONSeparator = ".#."
 )
 
@@ -50,29 +54,45 @@ const (
 // - check that a response body has a specific value of a specified json field
 // - check that a response body has a specific json field and that the specific json field matches a regular expression
 // - check that a response body is a specified length
+// - check that the response was received within a maximum response time
+// - check the nodes selected by a JSONPath, with a quantifier, against values, numeric and date bounds or a JSON Schema
+// - check that a response body, or part of it, validates against an inline JSON Schema
+// - run a custom check registered with AddCustomCheck
 // - allow for replacement of endpoint text ... e.g. {AccountId}
 // - Authorization: allow for manipulation of Bearer tokens in http headers
 // - Result: allow for capturing of match values for further processing - like putting into a context
 type Match struct {
-	MatchType           MatchType `json:"match_type,omitempty"`         // Type of Match we're doing
-	Description         string    `json:"description,omitempty"`        // Description of the purpose of the match
-	ContextName         string    `json:"name,omitempty"`               // Context variable name
-	Header              string    `json:"header,omitempty"`             // Header value to examine
-	HeaderPresent       string    `json:"header-present,omitempty"`     // Header existence check
-	Regex               string    `json:"regex,omitempty"`              // Regular expression to be used
-	JSON                string    `json:"json,omitempty"`               // Json expression to be used
-	JSONNotPresent      string    `json:"json-not-present,omitempty"`   // Json expression to be checked if
-	Value               string    `json:"value,omitempty"`              // Value to match against (string)
-	Numeric             int64     `json:"numeric,omitempty"`            // Value to match against - numeric
-	Count               int64     `json:"count,omitempty"`              // Cont for JSON array match purposes
-	BodyLength          *int64    `json:"body-length,omitempty"`        // Body payload length for matching
-	ReplaceEndpoint     string    `json:"replaceInEndpoint,omitempty"`  // allows substitution of resourceIds
-	Authorisation       string    `json:"authorisation,omitempty"`      // allows capturing of bearer tokens
-	Result              string    `json:"result,omitempty"`             // capturing match values
-	Custom              string    `json:"custom,omitempty"`             // specifies custom matching routine
-	ExpectResults       bool      `json:"check-result-count,omitempty"` // specifies if to collect Results (useful for expecting arrays of Results)
-	ResultArray         []string  `json:"-"`                            // represents Result's array
-	ResultPresenceArray []bool    `json:"-"`                            // represents Result's bool array with information if the fields were found based on JSON query in the Result's array
+	MatchType           MatchType              `json:"match_type,omitempty"`         // Type of Match we're doing
+	Description         string                 `json:"description,omitempty"`        // Description of the purpose of the match
+	ContextName         string                 `json:"name,omitempty"`               // Context variable name
+	Header              string                 `json:"header,omitempty"`             // Header value to examine
+	HeaderPresent       string                 `json:"header-present,omitempty"`     // Header existence check
+	Regex               string                 `json:"regex,omitempty"`              // Regular expression to be used
+	JSON                string                 `json:"json,omitempty"`               // Json expression to be used
+	JSONNotPresent      string                 `json:"json-not-present,omitempty"`   // Json expression to be checked if
+	Value               string                 `json:"value,omitempty"`              // Value to match against (string)
+	Numeric             int64                  `json:"numeric,omitempty"`            // Value to match against - numeric
+	Count               int64                  `json:"count,omitempty"`              // Cont for JSON array match purposes
+	BodyLength          *int64                 `json:"body-length,omitempty"`        // Body payload length for matching
+	ResponseTimeMax     string                 `json:"response-time-max,omitempty"`  // Maximum response time, a duration such as 500ms or 2s
+	JSONPath            string                 `json:"jsonpath,omitempty"`           // JSONPath (RFC 9535) selecting the nodes checked
+	Quantifier          string                 `json:"quantifier,omitempty"`         // all (default), any or none of the nodes selected meet the conditions
+	In                  []string               `json:"in,omitempty"`                 // Values allowed
+	GreaterThan         *float64               `json:"gt,omitempty"`                 // Exclusive numeric lower bound, numbers in strings are compared too
+	LessThan            *float64               `json:"lt,omitempty"`                 // Exclusive numeric upper bound
+	Between             []float64              `json:"between,omitempty"`            // Inclusive numeric lower and upper bounds
+	Before              string                 `json:"before,omitempty"`             // Exclusive date or date-time upper bound
+	After               string                 `json:"after,omitempty"`              // Exclusive date or date-time lower bound
+	Schema              map[string]interface{} `json:"schema,omitempty"`             // Inline JSON Schema, as an OpenAPI 3 schema object
+	Args                map[string]string      `json:"args,omitempty"`               // Arguments of the custom check
+	ReplaceEndpoint     string                 `json:"replaceInEndpoint,omitempty"`  // allows substitution of resourceIds
+	Authorisation       string                 `json:"authorisation,omitempty"`      // allows capturing of bearer tokens
+	Result              string                 `json:"result,omitempty"`             // capturing match values
+	Custom              string                 `json:"custom,omitempty"`             // specifies custom matching routine
+	Detail              string                 `json:"detail,omitempty"`             // what is checked, documents the match only
+	ExpectResults       bool                   `json:"check-result-count,omitempty"` // specifies if to collect Results (useful for expecting arrays of Results)
+	ResultArray         []string               `json:"-"`                            // represents Result's array
+	ResultPresenceArray []bool                 `json:"-"`                            // represents Result's bool array with information if the fields were found based on JSON query in the Result's array
 }
 
 // ContextAccessor - Manages access to matches for Put and Get value operations on a context
@@ -163,6 +183,13 @@ func (m *Match) PutValue(tc *TestCase, ctx *Context) bool {
 		}
 	case BodyRegex:
 		return handleBodyRegex(tc, m, ctx)
+	case BodyJSONPath:
+		success, err := checkBodyJSONPath(m, tc)
+		if err != nil || !success || len(m.ContextName) == 0 {
+			return false
+		}
+		ctx.Put(m.ContextName, m.Result)
+		return true
 	}
 	return false
 }
@@ -222,11 +249,26 @@ func (m *Match) GetType() MatchType {
 		return CustomCheck
 	}
 
+	if fieldsPresent(m.ResponseTimeMax) {
+		m.MatchType = ResponseTimeMax
+		return ResponseTimeMax
+	}
+
 	if fieldsPresent(m.Authorisation) {
 		m.MatchType = Authorisation
 		return Authorisation
 	}
 
+	if fieldsPresent(m.JSONPath) {
+		m.MatchType = BodyJSONPath
+		return BodyJSONPath
+	}
+
+	if len(m.Schema) > 0 {
+		m.MatchType = BodyJSONSchema
+		return BodyJSONSchema
+	}
+
 	if fieldsPresent(m.Header) {
 		return m.getHeaderType()
 	}
@@ -342,6 +384,9 @@ var matchFuncs = map[MatchType]func(*Match, *TestCase) (bool, error){
 	BodyLength:           checkBodyLength,
 	Authorisation:        checkAuthorisation,
 	CustomCheck:          checkCustom,
+	ResponseTimeMax:      checkResponseTimeMax,
+	BodyJSONPath:         checkBodyJSONPath,
+	BodyJSONSchema:       checkBodyJSONSchema,
 }
 
 var matchTypeString = map[MatchType]string{
@@ -358,6 +403,9 @@ var matchTypeString = map[MatchType]string{
 	BodyLength:         "BodyLength",
 	Authorisation:      "Authorisation",
 	CustomCheck:        "Custom",
+	ResponseTimeMax:    "ResponseTimeMax",
+	BodyJSONPath:       "BodyJSONPath",
+	BodyJSONSchema:     "BodyJSONSchema",
 }
 
 func defaultMatch(m *Match, _ *TestCase) (bool, error) {
@@ -587,6 +635,17 @@ func checkBodyLength(m *Match, tc *TestCase) (bool, error) {
 	return success, nil
 }
 
+func checkResponseTimeMax(m *Match, tc *TestCase) (bool, error) {
+	max, err := time.ParseDuration(m.ResponseTimeMax)
+	if err != nil {
+		return false, m.AppErr(fmt.Sprintf("Check Response Time - invalid maximum response time (%s): %s", m.ResponseTimeMax, err.Error()))
+	}
+	if tc.ResponseTime > max {
+		return false, m.AppErr(fmt.Sprintf("Check Response Time - response time (%s) is over the maximum (%s)", tc.ResponseTime, max))
+	}
+	return true, nil
+}
+
 func checkAuthorisation(m *Match, tc *TestCase) (bool, error) {
 	var success bool
 	var actualHeader string
@@ -619,10 +678,6 @@ func checkAuthorisation(m *Match, tc *TestCase) (bool, error) {
 	return true, nil
 }
 
-func checkCustom(m *Match, tc *TestCase) (bool, error) {
-	return true, nil //TODO: implement custom checks
-}
-
 func getJSONPaths(body, pattern string) ([]string, error) {
 	rootPattern, err := getRootPattern(pattern)
 	if err != nil {
@@ -677,11 +732,18 @@ func (m *Match) ProcessReplacementFields(ctx *Context) {
 	m.JSON, _ = replaceContextField(m.JSON, ctx)
 	m.Value, _ = replaceContextField(m.Value, ctx)
 	m.ContextName, _ = replaceContextField(m.ContextName, ctx)
+	m.JSONPath, _ = replaceJSONPathContextFields(m.JSONPath, ctx)
+	m.Before, _ = replaceContextField(m.Before, ctx)
+	m.After, _ = replaceContextField(m.After, ctx)
+	for i := range m.In {
+		m.In[i], _ = replaceContextField(m.In[i], ctx)
+	}
 }
 
 // Clone duplicates a Match into a separate independent object
 // TODO: consider cloning the contextPut
 // Omit bodylength for now...
+// The schema and the args are shared, they are not modified.
 func (m *Match) Clone() Match {
 	ma := Match{Authorisation: m.Authorisation,
 		ContextName:     m.ContextName,
@@ -697,6 +759,17 @@ func (m *Match) Clone() Match {
 		Result:          m.Result,
 		ReplaceEndpoint: m.ReplaceEndpoint,
 		Value:           m.Value,
+		ResponseTimeMax: m.ResponseTimeMax,
+		JSONPath:        m.JSONPath,
+		Quantifier:      m.Quantifier,
+		In:              append([]string(nil), m.In...),
+		GreaterThan:     m.GreaterThan,
+		LessThan:        m.LessThan,
+		Between:         append([]float64(nil), m.Between...),
+		Before:          m.Before,
+		After:           m.After,
+		Schema:          m.Schema,
+		Args:            m.Args,
 	}
 	return ma
 }
@@ -0,0 +1,320 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
+)
+
+// Quantifiers of a JSONPath match
+const (
+	QuantifierAll  = "all"
+	QuantifierAny  = "any"
+	QuantifierNone = "none"
+)
+
+// Validate checks the JSONPath, quantifier, bounds, schema and custom check of a match, which are otherwise only found to be
+// wrong when the match is checked. JSONPaths and bounds using templates, and bounds using context values, are not checked.
+func (m *Match) Validate() error {
+	if m.JSONPath != "" && !HasTemplates(m.JSONPath) {
+		if _, err := CompileJSONPath(m.JSONPath); err != nil {
+			return err
+		}
+	}
+	switch m.Quantifier {
+	case "", QuantifierAll, QuantifierAny, QuantifierNone:
+	default:
+		return fmt.Errorf("quantifier %s is not one of all, any or none", m.Quantifier)
+	}
+	if m.Between != nil && (len(m.Between) != 2 || m.Between[0] > m.Between[1]) {
+		return fmt.Errorf("between takes a lower and an upper bound, got %v", m.Between)
+	}
+	for _, bound := range []string{m.Before, m.After} {
+		if bound == "" || strings.Contains(bound, "$") {
+			continue
+		}
+		if _, err := parseDate(bound); err != nil {
+			return err
+		}
+	}
+	if len(m.Schema) > 0 {
+		if _, err := m.compileSchema(); err != nil {
+			return err
+		}
+	}
+	if m.Custom != "" && !CustomCheckExists(m.Custom) {
+		return fmt.Errorf("custom check %s does not exist", m.Custom)
+	}
+	return nil
+}
+
+// replaceJSONPathContextFields expands the `${ expression }` templates of a JSONPath, then replaces all its `$name`
+// fields with the context values. The root `$` of the path is not a field.
+func replaceJSONPathContextFields(path string, ctx *Context) (string, error) {
+	if HasTemplates(path) {
+		expanded, err := ExpandTemplates(path, ctx)
+		if err != nil {
+			return path, err
+		}
+		path = expanded
+	}
+	var err error
+	replaced := contextReferenceRegex.ReplaceAllStringFunc(path, func(field string) string {
+		value, ok := ctx.Get(field[1:])
+		replacement, isString := value.(string)
+		if !ok || !isString {
+			if err == nil {
+				err = errors.New("replacement not found in context: " + field)
+			}
+			return field
+		}
+		return replacement
+	})
+	return replaced, err
+}
+
+// checkBodyJSONPath checks the nodes selected by a JSONPath. Without conditions at least one node is selected,
+// exactly `count` when given. The value of the first node selected is kept as result.
+func checkBodyJSONPath(m *Match, tc *TestCase) (bool, error) {
+	path, err := CompileJSONPath(m.JSONPath)
+	if err != nil {
+		return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - %s", err.Error()))
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(tc.Body), &body); err != nil {
+		return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - body is not JSON: %s", err.Error()))
+	}
+
+	nodes := path.Select(body)
+	if len(nodes) > 0 {
+		m.Result = renderMatchNode(nodes[0])
+	}
+	if m.Count > 0 && int64(len(nodes)) != m.Count {
+		return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - found (%d) not (%d) nodes for (%s)", len(nodes), m.Count, m.JSONPath))
+	}
+
+	conditions, err := m.nodeConditions()
+	if err != nil {
+		return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - %s", err.Error()))
+	}
+	if len(conditions) == 0 {
+		if m.Quantifier == QuantifierNone {
+			if len(nodes) > 0 {
+				return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - found (%d) nodes for (%s), expected none", len(nodes), m.JSONPath))
+			}
+			return true, nil
+		}
+		if len(nodes) == 0 {
+			return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - no node found for (%s)", m.JSONPath))
+		}
+		return true, nil
+	}
+
+	switch m.Quantifier {
+	case QuantifierAny:
+		failures := []string{}
+		for _, node := range nodes {
+			err := checkNode(node, conditions)
+			if err == nil {
+				return true, nil
+			}
+			failures = append(failures, err.Error())
+		}
+		if len(nodes) == 0 {
+			return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - no node found for (%s)", m.JSONPath))
+		}
+		return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - no node of (%s) meets the conditions: %s", m.JSONPath, strings.Join(failures, ", ")))
+	case QuantifierNone:
+		for i, node := range nodes {
+			if checkNode(node, conditions) == nil {
+				return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - node %d (%s) of (%s) meets the conditions", i, renderMatchNode(node), m.JSONPath))
+			}
+		}
+		return true, nil
+	default:
+		if len(nodes) == 0 {
+			return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - no node found for (%s)", m.JSONPath))
+		}
+		for i, node := range nodes {
+			if err := checkNode(node, conditions); err != nil {
+				return false, m.AppErr(fmt.Sprintf("JSONPath Match Failed - node %d of (%s): %s", i, m.JSONPath, err.Error()))
+			}
+		}
+		return true, nil
+	}
+}
+
+// checkBodyJSONSchema validates the body, or the field selected by `json`, against the inline schema
+func checkBodyJSONSchema(m *Match, tc *TestCase) (bool, error) {
+	schema, err := m.compileSchema()
+	if err != nil {
+		return false, m.AppErr(fmt.Sprintf("JSON Schema Match Failed - %s", err.Error()))
+	}
+	var node interface{}
+	if m.JSON != "" {
+		result := gjson.Get(tc.Body, m.JSON)
+		if !result.Exists() {
+			return false, m.AppErr(fmt.Sprintf("JSON Schema Match Failed - no field present for pattern (%s)", m.JSON))
+		}
+		node = result.Value()
+	} else if err := json.Unmarshal([]byte(tc.Body), &node); err != nil {
+		return false, m.AppErr(fmt.Sprintf("JSON Schema Match Failed - body is not JSON: %s", err.Error()))
+	}
+	if err := schema.Validate(node); err != nil {
+		return false, m.AppErr(fmt.Sprintf("JSON Schema Match Failed - %s", err.Error()))
+	}
+	return true, nil
+}
+
+// nodeCondition returns an error describing why a node does not meet it
+type nodeCondition func(node interface{}) error
+
+func checkNode(node interface{}, conditions []nodeCondition) error {
+	for _, condition := range conditions {
+		if err := condition(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeConditions are the conditions of a JSONPath match: value, regex, in, gt, lt, between, before, after and schema
+func (m *Match) nodeConditions() ([]nodeCondition, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	conditions := []nodeCondition{}
+	if m.Value != "" {
+		conditions = append(conditions, func(node interface{}) error {
+			if value := renderMatchNode(node); value != m.Value {
+				return fmt.Errorf("expected (%s) got (%s)", m.Value, value)
+			}
+			return nil
+		})
+	}
+	if m.Regex != "" {
+		regex, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, func(node interface{}) error {
+			if value := renderMatchNode(node); !regex.MatchString(value) {
+				return fmt.Errorf("(%s) does not match regex (%s)", value, m.Regex)
+			}
+			return nil
+		})
+	}
+	if len(m.In) > 0 {
+		conditions = append(conditions, func(node interface{}) error {
+			value := renderMatchNode(node)
+			for _, allowed := range m.In {
+				if value == allowed {
+					return nil
+				}
+			}
+			return fmt.Errorf("(%s) is not one of (%s)", value, strings.Join(m.In, ", "))
+		})
+	}
+	if m.GreaterThan != nil || m.LessThan != nil || m.Between != nil {
+		conditions = append(conditions, m.numericCondition)
+	}
+	if m.Before != "" || m.After != "" {
+		condition, err := m.dateCondition()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(m.Schema) > 0 {
+		schema, err := m.compileSchema()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, func(node interface{}) error {
+			return schema.Validate(node)
+		})
+	}
+	return conditions, nil
+}
+
+func (m *Match) numericCondition(node interface{}) error {
+	var number float64
+	switch n := node.(type) {
+	case float64:
+		number = n
+	case string:
+		var err error
+		if number, err = strconv.ParseFloat(n, 64); err != nil {
+			return fmt.Errorf("(%s) is not a number", n)
+		}
+	default:
+		return fmt.Errorf("(%s) is not a number", renderMatchNode(node))
+	}
+	value := strconv.FormatFloat(number, 'f', -1, 64)
+	if m.GreaterThan != nil && !(number > *m.GreaterThan) {
+		return fmt.Errorf("(%s) is not greater than (%g)", value, *m.GreaterThan)
+	}
+	if m.LessThan != nil && !(number < *m.LessThan) {
+		return fmt.Errorf("(%s) is not less than (%g)", value, *m.LessThan)
+	}
+	if m.Between != nil && (number < m.Between[0] || number > m.Between[1]) {
+		return fmt.Errorf("(%s) is not between (%g) and (%g)", value, m.Between[0], m.Between[1])
+	}
+	return nil
+}
+
+func (m *Match) dateCondition() (nodeCondition, error) {
+	var before, after time.Time
+	var err error
+	if m.Before != "" {
+		if before, err = parseDate(m.Before); err != nil {
+			return nil, err
+		}
+	}
+	if m.After != "" {
+		if after, err = parseDate(m.After); err != nil {
+			return nil, err
+		}
+	}
+	return func(node interface{}) error {
+		value, ok := node.(string)
+		if !ok {
+			return fmt.Errorf("(%s) is not a date", renderMatchNode(node))
+		}
+		date, err := parseDate(value)
+		if err != nil {
+			return err
+		}
+		if m.Before != "" && !date.Before(before) {
+			return fmt.Errorf("(%s) is not before (%s)", value, m.Before)
+		}
+		if m.After != "" && !date.After(after) {
+			return fmt.Errorf("(%s) is not after (%s)", value, m.After)
+		}
+		return nil
+	}, nil
+}
+
+func (m *Match) compileSchema() (*jsonschema.Inline, error) {
+	return jsonschema.CompileInline(m.Schema)
+}
+
+// renderMatchNode renders strings as is and other values as JSON
+func renderMatchNode(node interface{}) string {
+	if s, ok := node.(string); ok {
+		return s
+	}
+	raw, err := json.Marshal(node)
+	if err != nil {
+		return fmt.Sprint(node)
+	}
+	return string(raw)
+}
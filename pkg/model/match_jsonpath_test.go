@@ -0,0 +1,143 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonPathMatch(t *testing.T, raw string) Match {
+	m := Match{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &m))
+	return m
+}
+
+func TestMatchGetTypeJSONPathAndSchema(t *testing.T) {
+	m := jsonPathMatch(t, `{"jsonpath": "$.Data.Balance[*].Type", "regex": "^[A-Za-z]+$"}`)
+	assert.Equal(t, BodyJSONPath, m.GetType())
+	m = jsonPathMatch(t, `{"json": "Data.Balance.0", "schema": {"type": "object"}}`)
+	assert.Equal(t, BodyJSONSchema, m.GetType())
+}
+
+func TestCheckBodyJSONPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		match string
+		err   string
+	}{
+		{"present", `{"jsonpath": "$.Data.Balance[?@.Type == 'Expected']"}`, ""},
+		{"not present", `{"jsonpath": "$.Data.Balance[?@.Type == 'ClosingBooked']"}`, "JSONPath Match Failed - no node found for ($.Data.Balance[?@.Type == 'ClosingBooked'])"},
+		{"none present", `{"jsonpath": "$.Data.Balance[?@.Type == 'ClosingBooked']", "quantifier": "none"}`, ""},
+		{"count", `{"jsonpath": "$.Data.Balance[*]", "count": 2}`, "JSONPath Match Failed - found (3) not (2) nodes for ($.Data.Balance[*])"},
+		{"all in", `{"jsonpath": "$.Data.Balance[*].CreditDebitIndicator", "in": ["Credit", "Debit"]}`, ""},
+		{"all in failing", `{"jsonpath": "$.Data.Balance[*].CreditDebitIndicator", "in": ["Credit"]}`, "JSONPath Match Failed - node 1 of ($.Data.Balance[*].CreditDebitIndicator): (Debit) is not one of (Credit)"},
+		{"any value", `{"jsonpath": "$.Data.Balance[*].Amount.Currency", "quantifier": "any", "value": "EUR"}`, ""},
+		{"any value failing", `{"jsonpath": "$.Data.Balance[0:2].Amount.Currency", "quantifier": "any", "value": "EUR"}`, "JSONPath Match Failed - no node of ($.Data.Balance[0:2].Amount.Currency) meets the conditions: expected (EUR) got (GBP), expected (EUR) got (GBP)"},
+		{"none regex", `{"jsonpath": "$..AccountId", "quantifier": "none", "regex": "^0"}`, ""},
+		{"none regex failing", `{"jsonpath": "$..AccountId", "quantifier": "none", "regex": "^3"}`, "JSONPath Match Failed - node 2 (31820) of ($..AccountId) meets the conditions"},
+		{"gt on strings", `{"jsonpath": "$.Data.Balance[*].Amount.Amount", "gt": -1, "lt": 5000}`, ""},
+		{"gt failing", `{"jsonpath": "$.Data.Balance[*].Amount.Amount", "gt": 0}`, "JSONPath Match Failed - node 2 of ($.Data.Balance[*].Amount.Amount): (0) is not greater than (0)"},
+		{"between", `{"jsonpath": "$.Meta.TotalPages", "between": [1, 1]}`, ""},
+		{"between failing", `{"jsonpath": "$.Data.Balance[0].Amount.Amount", "between": [0, 1000]}`, "JSONPath Match Failed - node 0 of ($.Data.Balance[0].Amount.Amount): (1230) is not between (0) and (1000)"},
+		{"not a number", `{"jsonpath": "$.Data.Balance[0].Type", "lt": 1}`, "JSONPath Match Failed - node 0 of ($.Data.Balance[0].Type): (InterimAvailable) is not a number"},
+		{"dates", `{"jsonpath": "$.Data.Balance[*].DateTime", "after": "2017-04-05", "before": "2017-04-05T12:00:00+01:00"}`, ""},
+		{"dates failing", `{"jsonpath": "$.Data.Balance[*].DateTime", "before": "2017-04-05T10:43:08Z"}`, "JSONPath Match Failed - node 1 of ($.Data.Balance[*].DateTime): (2017-04-05T10:43:08+00:00) is not before (2017-04-05T10:43:08Z)"},
+		{"schema", `{"jsonpath": "$.Data.Balance[*].Amount", "schema": {"type": "object", "required": ["Amount", "Currency"], "properties": {"Currency": {"type": "string", "pattern": "^[A-Z]{3}$"}}}}`, ""},
+		{"schema failing", `{"jsonpath": "$.Data.Balance[*]", "schema": {"required": ["CreditLine"]}}`, "JSONPath Match Failed - node 0 of ($.Data.Balance[*]): CreditLine: property \"CreditLine\" is missing"},
+		{"invalid quantifier", `{"jsonpath": "$.Data", "quantifier": "most", "value": "a"}`, "JSONPath Match Failed - quantifier most is not one of all, any or none"},
+		{"invalid jsonpath", `{"jsonpath": "$.Data[", "value": "a"}`, `JSONPath Match Failed - jsonpath "$.Data[", column 8: unexpected end, expected a selector`},
+	}
+	tc := &TestCase{Body: `{
+		"Data": {"Balance": [
+			{"AccountId": "22289", "Amount": {"Amount": "1230.00", "Currency": "GBP"}, "CreditDebitIndicator": "Credit", "Type": "InterimAvailable", "DateTime": "2017-04-05T10:43:07+00:00"},
+			{"AccountId": "22289", "Amount": {"Amount": "57.36", "Currency": "GBP"}, "CreditDebitIndicator": "Debit", "Type": "Expected", "DateTime": "2017-04-05T10:43:08+00:00"},
+			{"AccountId": "31820", "Amount": {"Amount": "0.00", "Currency": "EUR"}, "CreditDebitIndicator": "Credit", "Type": "Expected"}
+		]},
+		"Meta": {"TotalPages": 1}
+	}`}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := jsonPathMatch(t, tt.match)
+			success, err := m.Check(tc)
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.True(t, success)
+				return
+			}
+			assert.False(t, success)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCheckBodyJSONSchema(t *testing.T) {
+	tc := &TestCase{Body: `{"Data": {"ConsentId": "sdp-1-b5bbdb18", "Status": "AwaitingAuthorisation"}}`}
+
+	m := jsonPathMatch(t, `{"json": "Data", "schema": {"type": "object", "properties": {"Status": {"enum": ["AwaitingAuthorisation", "Authorised"]}}}}`)
+	success, err := m.Check(tc)
+	require.NoError(t, err)
+	assert.True(t, success)
+
+	m = jsonPathMatch(t, `{"schema": {"properties": {"Data": {"required": ["CreationDateTime"]}}}}`)
+	success, err = m.Check(tc)
+	assert.False(t, success)
+	assert.EqualError(t, err, `JSON Schema Match Failed - Data.CreationDateTime: property "CreationDateTime" is missing`)
+}
+
+func TestPutValueJSONPath(t *testing.T) {
+	tc := &TestCase{Body: `{"Data": {"Account": [{"AccountId": "1", "Currency": "EUR"}, {"AccountId": "2", "Currency": "GBP"}]}}`}
+	m := jsonPathMatch(t, `{"name": "AccountId", "jsonpath": "$.Data.Account[?@.Currency == 'GBP'].AccountId"}`)
+	ctx := Context{}
+
+	assert.True(t, m.PutValue(tc, &ctx))
+	assert.Equal(t, "2", ctx["AccountId"])
+}
+
+func TestMatchJSONPathContextFields(t *testing.T) {
+	tc := &TestCase{Body: `{"Data": {"Balance": [{"Currency": "EUR", "Amount": "1.00"}, {"Currency": "GBP", "Amount": "2.00"}]}}`}
+	m := jsonPathMatch(t, `{"jsonpath": "$.Data.Balance[?@.Currency == '$ccy'].Amount", "value": "2.00"}`)
+
+	m.ProcessReplacementFields(&Context{"ccy": "GBP"})
+
+	assert.Equal(t, "$.Data.Balance[?@.Currency == 'GBP'].Amount", m.JSONPath)
+	success, err := m.Check(tc)
+	assert.True(t, success)
+	assert.NoError(t, err)
+
+	path, err := replaceJSONPathContextFields("$..Balance[?@.Currency == '$ccy' && @.Type == '$type']", &Context{"ccy": "GBP"})
+	assert.Equal(t, "$..Balance[?@.Currency == 'GBP' && @.Type == '$type']", path)
+	assert.EqualError(t, err, "replacement not found in context: $type")
+}
+
+func TestMatchValidate(t *testing.T) {
+	tests := []struct {
+		match string
+		err   string
+	}{
+		{`{"jsonpath": "$.Data.Account[?@.AccountId == '$accountId']", "before": "$fromDate"}`, ""},
+		{`{"jsonpath": "$.Data.Account[?@.AccountId = '$accountId']"}`, "jsonpath \"$.Data.Account[?@.AccountId = '$accountId']\", column 29: unexpected '=', expected ',' or ']'"},
+		{`{"jsonpath": "$.Data", "between": [2, 1]}`, "between takes a lower and an upper bound, got [2 1]"},
+		{`{"jsonpath": "$.Data", "after": "yesterday"}`, "(yesterday) is not a date"},
+		{`{"schema": {"type": 1}}`, `schema: failed to unmarshal property "type" (*string): json: cannot unmarshal number into Go value of type string`},
+	}
+	for _, tt := range tests {
+		m := jsonPathMatch(t, tt.match)
+		err := m.Validate()
+		if tt.err == "" {
+			assert.NoError(t, err, tt.match)
+			continue
+		}
+		assert.EqualError(t, err, tt.err, tt.match)
+	}
+}
+
+func TestMatchCloneCopiesConditions(t *testing.T) {
+	m := jsonPathMatch(t, `{"jsonpath": "$.Data", "quantifier": "any", "in": ["a"], "gt": 1, "between": [1, 2], "after": "2020-01-01", "response-time-max": "2s", "schema": {"type": "object"}}`)
+	clone := m.Clone()
+	assert.Equal(t, m, clone)
+	clone.In[0] = "b"
+	assert.Equal(t, "a", m.In[0])
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
 	"github.com/stretchr/testify/require"
@@ -512,6 +513,29 @@ func TestCheckBodyLengthMismatch2(t *testing.T) {
 	assert.False(t, result)
 }
 
+func TestCheckResponseTimeMax(t *testing.T) {
+	m := Match{Description: "test", ResponseTimeMax: "1m"}
+	tc := TestCase{Expect: Expect{Matches: []Match{m}, StatusCode: 200}, Validator: schema.NewNullValidator()}
+	resp := test.CreateHTTPResponse(200, "OK", "TheRainInSpainFallsMainlyOnThePlain")
+	result, err := tc.Validate(resp, emptyContext)
+	assert.Nil(t, err)
+	assert.True(t, result)
+	assert.Equal(t, ResponseTimeMax, m.GetType())
+}
+
+func TestCheckResponseTimeMaxExceeded(t *testing.T) {
+	m := Match{Description: "test", ResponseTimeMax: "500ms"}
+	tc := TestCase{ResponseTime: 600 * time.Millisecond}
+	result, err := m.Check(&tc)
+	assert.EqualError(t, err, "Check Response Time - response time (600ms) is over the maximum (500ms)")
+	assert.False(t, result)
+
+	m = Match{Description: "test", ResponseTimeMax: "fast"}
+	result, err = m.Check(&tc)
+	assert.EqualError(t, err, `Check Response Time - invalid maximum response time (fast): time: invalid duration "fast"`)
+	assert.False(t, result)
+}
+
 func TestMatchStringOutput(t *testing.T) {
 	var len int64 = 77
 	var num int64 = 88
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
@@ -71,10 +72,12 @@ type TestCase struct {
 	Expect              Expect           `json:"expect,omitempty"`               // Expected object
 	ExpectOneOf         []Expect         `json:"expect_one_of,omitempty"`        // Slice of possible expected objects
 	ExpectLastIfAll     []Expect         `json:"expect_last_if_all,omitempty"`   // Slice of expected objects if all before last one passed the last one needs too
+	ExpectAdvisory      []Expect         `json:"expect_advisory,omitempty"`      // Slice of expected objects which are reported as warnings, not failures, when they are not met
 	ParentRule          *Rule            `json:"-"`                              // Allows accessing parent Rule
 	Request             *resty.Request   `json:"-"`                              // The request that's been generated in order to call the endpoint
 	Header              http.Header      `json:"-"`                              // ResponseHeader
 	Body                string           `json:"-"`                              // ResponseBody
+	ResponseTime        time.Duration    `json:"-"`                              // Time taken to receive the response, checked by response time matches
 	Bearer              string           `json:"bearer,omitempty"`               // Bear token if presented
 	DoNotCallEndpoint   bool             `json:"do_not_call_endpoint,omitempty"` // If we should not call the endpoint, see `components/PSUConsentProviderComponent.json`
 	ExpectArrayResults  bool             `json:"expect_array_results,omitempty"` // Compare response body lengths between each expect (currently used by ExpectLastIfAll)
@@ -83,8 +86,11 @@ type TestCase struct {
 	Validator           schema.Validator `json:"-"` // Swagger schema validator
 	ValidateSignature   bool             `json:"validateSignature,omitempty"`
 	StatusCode          string           `json:"statusCode,omitempty"`
-	ResultArray         []string         `json:"-"` // represents Result array
-	ResultPresenceArray []bool           `json:"-"` // represents Result bool array with information if the fields were found based on JSON query in the Results
+	ResultArray         []string         `json:"-"`                  // represents Result array
+	ResultPresenceArray []bool           `json:"-"`                  // represents Result bool array with information if the fields were found based on JSON query in the Results
+	Replay              *Replay          `json:"replay,omitempty"`   // Send the prepared request a second time with the same x-idempotency-key
+	Paging              *Paging          `json:"paging,omitempty"`   // Follow and check every page of a paged response
+	Resource            string           `json:"resource,omitempty"` // Manifest resource, selects the data rules checked after schema validation
 }
 
 // MakeTestCase builds an empty testcase
@@ -129,27 +135,19 @@ func (t *TestCase) Validate(resp *resty.Response, ctx *Context) (bool, []error)
 		logrus.WithField("testcase", t.String()).Debug("Validate: resty.body is empty")
 	}
 	t.Header = resp.Header()
+	if resp.Request != nil {
+		t.ResponseTime = resp.Time()
+	}
 	pass, errs := t.ApplyExpects(resp, ctx)
 
-	var failures []schema.Failure
 	if t.Expect.SchemaValidation {
-		if t.Validator == nil {
-			return false, []error{t.AppErr("Validate: schema validator is nil")}
-		}
-
-		var err error
-		failures, err = t.Validator.Validate(schema.HTTPResponse{
-			Method:     t.Input.Method,
-			Path:       t.Input.Endpoint,
-			Header:     resp.Header(),
-			Body:       strings.NewReader(t.Body),
-			StatusCode: resp.StatusCode(),
-		})
+		schemaErrs, err := t.validateSchema(resp, t.Body)
 		if err != nil {
-			return false, []error{t.AppErr("Validate: " + err.Error())}
+			return false, []error{err}
 		}
-		for _, failure := range failures {
-			errs = append(errs, errors.New(failure.Message))
+		errs = append(errs, schemaErrs...)
+		if resp.IsSuccess() {
+			errs = append(errs, t.ValidateDataRules(t.Body)...)
 		}
 	} else {
 		logSchemaValidationOffWarning(t)
@@ -178,6 +176,29 @@ func (t *TestCase) Validate(resp *resty.Response, ctx *Context) (bool, []error)
 	return pass, errs
 }
 
+// validateSchema checks a response body against the OpenAPI schema of the testcase endpoint
+func (t *TestCase) validateSchema(resp *resty.Response, body string) ([]error, error) {
+	if t.Validator == nil {
+		return nil, t.AppErr("Validate: schema validator is nil")
+	}
+
+	failures, err := t.Validator.Validate(schema.HTTPResponse{
+		Method:     t.Input.Method,
+		Path:       t.Input.Endpoint,
+		Header:     resp.Header(),
+		Body:       strings.NewReader(body),
+		StatusCode: resp.StatusCode(),
+	})
+	if err != nil {
+		return nil, t.AppErr("Validate: " + err.Error())
+	}
+	errs := []error{}
+	for _, failure := range failures {
+		errs = append(errs, errors.New(failure.Message))
+	}
+	return errs, nil
+}
+
 func validateSignature(signature, body string, ctx *Context) (bool, error) {
 	var pass bool
 	if signature != "" {
@@ -270,6 +291,9 @@ type Expect struct {
 	// provides the ability to switch off schema validation
 	Matches    []Match         `json:"matches,omitempty"`    // An array of zero or more match items which must be 'passed' for the testcase to succeed
 	ContextPut ContextAccessor `json:"contextPut,omitempty"` // allows storing of test response fragments in context variables
+	Poll       *Poll           `json:"poll,omitempty"`       // re-issue the request until a terminal state is reached
+	Replay     *ReplayExpect   `json:"replay,omitempty"`     // compares the response to a replayed request with the original one
+	Detail     string          `json:"detail,omitempty"`     // what is expected, documents the assertion only
 }
 
 // ApplyInput - creates an HTTP request for this test case
@@ -335,16 +359,16 @@ func (t *TestCase) ApplyExpects(res *resty.Response, rulectx *Context) (bool, []
 	if res == nil { // if we've not got a response object to check, always return false
 		return false, []error{t.AppErr("nil http.Response - cannot process ApplyExpects")}
 	}
-	ok, err := t.validateExpect(t.Expect, res)
+	ok, err := t.validateExpect(t.Expect, res, rulectx)
 	if !ok {
 		return ok, []error{err}
 	}
 
-	if result, failedExpects := t.validateExpectsOneOf(res); !result && failedExpects != nil {
+	if result, failedExpects := t.validateExpectsOneOf(res, rulectx); !result && failedExpects != nil {
 		return result, failedExpects
 	}
 
-	if result, failedExpects := t.validateExpectsLastIfAll(res); !result && failedExpects != nil {
+	if result, failedExpects := t.validateExpectsLastIfAll(res, rulectx); !result && failedExpects != nil {
 		return result, failedExpects
 	}
 
@@ -354,7 +378,7 @@ func (t *TestCase) ApplyExpects(res *resty.Response, rulectx *Context) (bool, []
 	return true, nil
 }
 
-func (t *TestCase) validateExpect(expect Expect, res *resty.Response) (bool, error) {
+func (t *TestCase) validateExpect(expect Expect, res *resty.Response, ctx *Context) (bool, error) {
 	// Status code `-1` is specified in test cases if we want to ignore the HTTP status code.
 	if expect.StatusCode > 0 && expect.StatusCode != res.StatusCode() {
 		return false, t.AppErr(fmt.Sprintf("(%s):%s: HTTP Status code does not match: expected %d got %d", t.ID, t.Name, expect.StatusCode, res.StatusCode()))
@@ -363,7 +387,7 @@ func (t *TestCase) validateExpect(expect Expect, res *resty.Response) (bool, err
 	t.AppMsg(fmt.Sprintf("Status check isReplacement: expected [%d] got [%d]", expect.StatusCode, res.StatusCode()))
 	for k, match := range expect.Matches {
 		match.ExpectResults = t.ExpectArrayResults
-		checkResult, got := match.Check(t)
+		checkResult, got := match.CheckContext(t, ctx)
 		if !checkResult {
 			return false, t.AppErr(fmt.Sprintf("ApplyExpects Returns False on match %s : %s", match.String(), got.Error()))
 		}
@@ -378,11 +402,26 @@ func (t *TestCase) validateExpect(expect Expect, res *resty.Response) (bool, err
 	return true, nil
 }
 
+// ValidateAdvisory checks the advisory expects against the response, once the test case has passed.
+// Each expect which is not met is returned as a warning, it does not fail the test case.
+func (t *TestCase) ValidateAdvisory(res *resty.Response, ctx *Context) []error {
+	if res == nil {
+		return nil
+	}
+	warnings := []error{}
+	for _, expect := range t.ExpectAdvisory {
+		if ok, err := t.validateExpect(expect, res, ctx); !ok {
+			warnings = append(warnings, err)
+		}
+	}
+	return warnings
+}
+
 // validateExpectsOneOf - validates the slice of expects. It is OK when at least one of has passed.
-func (t *TestCase) validateExpectsOneOf(res *resty.Response) (bool, []error) {
+func (t *TestCase) validateExpectsOneOf(res *resty.Response, ctx *Context) (bool, []error) {
 	failedExpects := make([]error, 0, len(t.ExpectOneOf))
 	for _, expect := range t.ExpectOneOf {
-		ok, err := t.validateExpect(expect, res)
+		ok, err := t.validateExpect(expect, res, ctx)
 		if !ok {
 			failedExpects = append(failedExpects, err)
 		}
@@ -400,15 +439,15 @@ func (t *TestCase) validateExpectsOneOf(res *resty.Response) (bool, []error) {
 }
 
 // validateExpectsLastIfAll - validates the last expect when all beofre have passed.
-func (t *TestCase) validateExpectsLastIfAll(res *resty.Response) (bool, []error) {
+func (t *TestCase) validateExpectsLastIfAll(res *resty.Response, ctx *Context) (bool, []error) {
 	if t.ExpectArrayResults {
-		return t.validateExpectsLastIfAllArrayResults(res)
+		return t.validateExpectsLastIfAllArrayResults(res, ctx)
 	}
 
 	failedExpects := make([]error, 0, len(t.ExpectLastIfAll))
 
 	for i, expect := range t.ExpectLastIfAll {
-		ok, err := t.validateExpect(expect, res)
+		ok, err := t.validateExpect(expect, res, ctx)
 
 		switch i {
 		case len(t.ExpectLastIfAll) - 1:
@@ -431,7 +470,7 @@ func (t *TestCase) validateExpectsLastIfAll(res *resty.Response) (bool, []error)
 	return true, nil
 }
 
-func (t *TestCase) validateExpectsLastIfAllArrayResults(res *resty.Response) (bool, []error) {
+func (t *TestCase) validateExpectsLastIfAllArrayResults(res *resty.Response, ctx *Context) (bool, []error) {
 	failedExpects := make([]error, 0, len(t.ExpectLastIfAll))
 
 	var previoustMatch Match
@@ -445,7 +484,7 @@ func (t *TestCase) validateExpectsLastIfAllArrayResults(res *resty.Response) (bo
 			return false, failedExpects
 		}
 
-		ok, err := t.validateExpect(expect, res)
+		ok, err := t.validateExpect(expect, res, ctx)
 
 		if len(expect.Matches) == 1 {
 			currentMatch = expect.Matches[0]
@@ -546,6 +585,9 @@ func (t *TestCase) Clone() TestCase {
 	tc.Context = Context{}
 	tc.Context.PutContext(&t.Context)
 	tc.Expect = t.Expect.Clone()
+	tc.Replay = t.Replay.Clone()
+	tc.Paging = t.Paging.Clone()
+	tc.Resource = t.Resource
 
 	logrus.Debugf("cloned test -\n before: %#v\nafter : %#v\n ", t, tc)
 	return tc
@@ -562,7 +604,8 @@ func (r *Rule) String() string {
 		r.Name, r.Purpose, r.Specref, r.Speclocation, len(r.Tests))
 }
 
-// replaceContextField
+// replaceContextField expands the `${ expression }` templates of source, then replaces its first `$name` field
+// with the context value. Errors are ignored during generation, when the context is not complete yet.
 func replaceContextField(source string, ctx *Context) (string, error) {
 	var ignoreErrors bool
 	phase, exist := ctx.Get("phase")
@@ -570,6 +613,17 @@ func replaceContextField(source string, ctx *Context) (string, error) {
 		ignoreErrors = true
 	}
 
+	if HasTemplates(source) {
+		expanded, err := ExpandTemplates(source, ctx)
+		if err != nil {
+			if ignoreErrors {
+				return source, nil
+			}
+			return source, err
+		}
+		source = expanded
+	}
+
 	field, isReplacement := getReplacementField(source)
 	if !isReplacement {
 		return source, nil
@@ -715,6 +769,8 @@ func (e *Expect) Clone() Expect {
 	ex := Expect{}
 	ex.StatusCode = e.StatusCode
 	ex.SchemaValidation = e.SchemaValidation
+	ex.Poll = e.Poll.Clone()
+	ex.Replay = e.Replay.Clone()
 	for _, match := range e.Matches {
 		m := match.Clone()
 		ex.Matches = append(ex.Matches, m)
@@ -394,3 +394,25 @@ func TestFailingExpectsLastIfAll(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAdvisory(t *testing.T) {
+	tc := TestCase{
+		ID:     "OB-301-ACC-100000",
+		Expect: Expect{StatusCode: 200},
+		ExpectAdvisory: []Expect{
+			{Matches: []Match{{HeaderPresent: "x-fapi-interaction-id"}}},
+			{Matches: []Match{{JSON: "Data.Account.0.AccountId", Value: "500000000000000000000001"}}},
+		},
+	}
+	resp := test.CreateHTTPResponse(200, "OK", `{"Data":{"Account":[{"AccountId":"500000000000000000000001"}]}}`)
+
+	result, errs := tc.Validate(resp, emptyContext)
+	assert.True(t, result)
+	assert.Empty(t, errs)
+
+	warnings := tc.ValidateAdvisory(resp, emptyContext)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Error(), "x-fapi-interaction-id")
+
+	assert.Nil(t, tc.ValidateAdvisory(nil, emptyContext))
+}
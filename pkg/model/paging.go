@@ -0,0 +1,217 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/tidwall/gjson"
+	"gopkg.in/resty.v1"
+)
+
+const defaultMaxPages = 10
+
+// Paging follows the `Links.Next` of a paged response until the last page, or `max-pages`, is reached.
+// Every page is checked against the OpenAPI schema and the data rules of the testcase resource when the
+// testcase has schema checks, along with the coherence of its `Links` and `Meta.TotalPages`. Identifiers
+// selected by `unique-json` must not be repeated across pages, and dates selected by `booking-date-json`
+// must fall within the `fromBookingDateTime` and `toBookingDateTime` query parameters of the request.
+//
+//	"paging": {
+//	    "max-pages": 10,
+//	    "unique-json": "Data.Transaction.#.TransactionId",
+//	    "booking-date-json": "Data.Transaction.#.BookingDateTime"
+//	}
+type Paging struct {
+	MaxPages        int    `json:"max-pages,omitempty"`         // Maximum number of pages followed, defaults to 10
+	UniqueJSON      string `json:"unique-json,omitempty"`       // Json expression selecting identifiers which must not repeat across pages
+	BookingDateJSON string `json:"booking-date-json,omitempty"` // Json expression selecting the booking dates checked against the request filters
+}
+
+// Limit returns the maximum number of pages to follow
+func (p *Paging) Limit() int {
+	if p.MaxPages <= 0 {
+		return defaultMaxPages
+	}
+	return p.MaxPages
+}
+
+// Clone duplicates a Paging into a separate independent object
+func (p *Paging) Clone() *Paging {
+	if p == nil {
+		return nil
+	}
+	pa := *p
+	return &pa
+}
+
+// PageTraversal holds the state checked across the pages of a paged response
+type PageTraversal struct {
+	paging     *Paging
+	tc         *TestCase
+	window     dateWindow
+	pages      int
+	totalPages int64
+	first      string
+	last       string
+	previous   string
+	visited    map[string]bool
+	seen       map[string]int
+}
+
+// NewTraversal starts the traversal of the pages returned for requestURL, the URL of the first page
+// including its query parameters.
+func (p *Paging) NewTraversal(tc *TestCase, requestURL string) (*PageTraversal, error) {
+	pt := &PageTraversal{
+		paging:  p,
+		tc:      tc,
+		visited: map[string]bool{},
+		seen:    map[string]int{},
+	}
+
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("paging: parsing request url %s: %s", requestURL, err.Error())
+	}
+	if pt.window, err = bookingWindow(u.Query()); err != nil {
+		return nil, fmt.Errorf("paging: %s", err.Error())
+	}
+	return pt, nil
+}
+
+// Pages returns the number of pages checked so far
+func (pt *PageTraversal) Pages() int {
+	return pt.pages
+}
+
+// Check validates the next page of the traversal and returns the link to the following page,
+// which is empty on the last page.
+func (pt *PageTraversal) Check(resp *resty.Response) (string, []error) {
+	pt.pages++
+	page := pt.pages
+	body := resp.String()
+	errs := []error{}
+
+	if page > 1 {
+		if resp.StatusCode() != 200 {
+			return "", []error{fmt.Errorf("paging: page %d: HTTP Status code does not match: expected 200 got %d", page, resp.StatusCode())}
+		}
+		if pt.tc.Expect.SchemaValidation {
+			schemaErrs, err := pt.tc.validateSchema(resp, body)
+			if err != nil {
+				return "", []error{fmt.Errorf("paging: page %d: %s", page, err.Error())}
+			}
+			for _, schemaErr := range schemaErrs {
+				errs = append(errs, fmt.Errorf("paging: page %d: %s", page, schemaErr.Error()))
+			}
+			for _, ruleErr := range pt.tc.ValidateDataRules(body) {
+				errs = append(errs, fmt.Errorf("paging: page %d: %s", page, ruleErr.Error()))
+			}
+		}
+	}
+
+	self := gjson.Get(body, "Links.Self").String()
+	next := gjson.Get(body, "Links.Next").String()
+	prev := gjson.Get(body, "Links.Prev").String()
+	first := gjson.Get(body, "Links.First").String()
+	last := gjson.Get(body, "Links.Last").String()
+
+	if self == "" {
+		errs = append(errs, fmt.Errorf("paging: page %d: Links.Self is missing", page))
+	} else {
+		pt.visited[normaliseLink(self)] = true
+	}
+
+	if page == 1 {
+		pt.first, pt.last = first, last
+		if first != "" && self != "" && !sameLink(first, self) {
+			errs = append(errs, fmt.Errorf("paging: page 1: Links.First (%s) is not Links.Self (%s)", first, self))
+		}
+	} else {
+		if first != "" && pt.first != "" && !sameLink(first, pt.first) {
+			errs = append(errs, fmt.Errorf("paging: page %d: Links.First (%s) differs from page 1 (%s)", page, first, pt.first))
+		}
+		if last != "" && pt.last != "" && !sameLink(last, pt.last) {
+			errs = append(errs, fmt.Errorf("paging: page %d: Links.Last (%s) differs from page 1 (%s)", page, last, pt.last))
+		}
+		if prev != "" && pt.previous != "" && !sameLink(prev, pt.previous) {
+			errs = append(errs, fmt.Errorf("paging: page %d: Links.Prev (%s) is not the previous page (%s)", page, prev, pt.previous))
+		}
+	}
+	pt.previous = self
+
+	if next == "" && last != "" && self != "" && !sameLink(last, self) {
+		errs = append(errs, fmt.Errorf("paging: page %d: last page Links.Self (%s) is not Links.Last (%s)", page, self, last))
+	}
+	if next != "" && pt.visited[normaliseLink(next)] {
+		errs = append(errs, fmt.Errorf("paging: page %d: Links.Next (%s) points to a page already visited", page, next))
+		next = ""
+	}
+
+	if totalPages := gjson.Get(body, "Meta.TotalPages"); totalPages.Exists() {
+		if page == 1 {
+			pt.totalPages = totalPages.Int()
+			if pt.totalPages < 1 {
+				errs = append(errs, fmt.Errorf("paging: page 1: Meta.TotalPages (%d) is less than 1", pt.totalPages))
+			}
+		} else if totalPages.Int() != pt.totalPages {
+			errs = append(errs, fmt.Errorf("paging: page %d: Meta.TotalPages (%d) differs from page 1 (%d)", page, totalPages.Int(), pt.totalPages))
+		}
+	}
+
+	errs = append(errs, pt.checkUnique(page, body)...)
+	errs = append(errs, pt.checkBookingDates(page, body)...)
+
+	return next, errs
+}
+
+// Finish completes the traversal. Meta.TotalPages can only be compared with the number of
+// pages received when the last page was reached.
+func (pt *PageTraversal) Finish(lastPageReached bool) []error {
+	if lastPageReached && pt.totalPages > 0 && int64(pt.pages) != pt.totalPages {
+		return []error{fmt.Errorf("paging: Meta.TotalPages (%d) does not match the %d pages received", pt.totalPages, pt.pages)}
+	}
+	return nil
+}
+
+func (pt *PageTraversal) checkUnique(page int, body string) []error {
+	if pt.paging.UniqueJSON == "" {
+		return nil
+	}
+	errs := []error{}
+	for _, id := range gjson.Get(body, pt.paging.UniqueJSON).Array() {
+		if seenOn, seen := pt.seen[id.String()]; seen {
+			errs = append(errs, fmt.Errorf("paging: duplicate %s (%s) on pages %d and %d", pt.paging.UniqueJSON, id.String(), seenOn, page))
+			continue
+		}
+		pt.seen[id.String()] = page
+	}
+	return errs
+}
+
+func (pt *PageTraversal) checkBookingDates(page int, body string) []error {
+	if pt.paging.BookingDateJSON == "" || pt.window.unbounded() {
+		return nil
+	}
+	errs := []error{}
+	for _, value := range gjson.Get(body, pt.paging.BookingDateJSON).Array() {
+		if err := pt.window.check(value.String()); err != nil {
+			errs = append(errs, fmt.Errorf("paging: page %d: booking date %s", page, err.Error()))
+		}
+	}
+	return errs
+}
+
+// normaliseLink makes links comparable regardless of the order of their query parameters
+func normaliseLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	u.RawQuery = u.Query().Encode()
+	u.Fragment = ""
+	return u.String()
+}
+
+func sameLink(a, b string) bool {
+	return normaliseLink(a) == normaliseLink(b)
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/schema"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pagingBase = "https://aspsp.example.com/accounts/1/transactions"
+
+func transactionsPage(page, total int, next bool, transactions ...string) string {
+	links := fmt.Sprintf(`"Self":"%s?page=%d","First":"%s?page=1","Last":"%s?page=%d"`, pagingBase, page, pagingBase, pagingBase, total)
+	if page > 1 {
+		links += fmt.Sprintf(`,"Prev":"%s?page=%d"`, pagingBase, page-1)
+	}
+	if next {
+		links += fmt.Sprintf(`,"Next":"%s?page=%d"`, pagingBase, page+1)
+	}
+	txns := ""
+	for i, txn := range transactions {
+		if i > 0 {
+			txns += ","
+		}
+		txns += txn
+	}
+	return fmt.Sprintf(`{"Data":{"Transaction":[%s]},"Links":{%s},"Meta":{"TotalPages":%d}}`, txns, links, total)
+}
+
+func transaction(id, booked string) string {
+	return fmt.Sprintf(`{"TransactionId":"%s","BookingDateTime":"%s"}`, id, booked)
+}
+
+func newTestTraversal(t *testing.T, requestURL string) *PageTraversal {
+	paging := &Paging{UniqueJSON: "Data.Transaction.#.TransactionId", BookingDateJSON: "Data.Transaction.#.BookingDateTime"}
+	traversal, err := paging.NewTraversal(&TestCase{}, requestURL)
+	require.NoError(t, err)
+	return traversal
+}
+
+func TestPagingLimit(t *testing.T) {
+	assert.Equal(t, defaultMaxPages, (&Paging{}).Limit())
+	assert.Equal(t, 3, (&Paging{MaxPages: 3}).Limit())
+}
+
+func TestPageTraversalCoherentPages(t *testing.T) {
+	traversal := newTestTraversal(t, pagingBase+"?fromBookingDateTime=2020-01-01T00:00:00&toBookingDateTime=2020-12-31T00:00:00")
+
+	next, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(1, 2, true, transaction("t1", "2020-02-01T10:00:00+00:00"))))
+	assert.Empty(t, errs)
+	assert.Equal(t, pagingBase+"?page=2", next)
+
+	next, errs = traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(2, 2, false, transaction("t2", "2020-03-01T10:00:00Z"))))
+	assert.Empty(t, errs)
+	assert.Empty(t, next)
+	assert.Equal(t, 2, traversal.Pages())
+	assert.Empty(t, traversal.Finish(true))
+}
+
+func TestPageTraversalDuplicateTransaction(t *testing.T) {
+	traversal := newTestTraversal(t, pagingBase)
+
+	_, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(1, 2, true, transaction("t1", "2020-02-01T10:00:00Z"))))
+	require.Empty(t, errs)
+	_, errs = traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(2, 2, false, transaction("t1", "2020-02-01T10:00:00Z"))))
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "paging: duplicate Data.Transaction.#.TransactionId (t1) on pages 1 and 2")
+}
+
+func TestPageTraversalBookingDatesOutsideFilter(t *testing.T) {
+	traversal := newTestTraversal(t, pagingBase+"?fromBookingDateTime=2020-01-01T00:00:00&toBookingDateTime=2020-01-31T00:00:00")
+
+	_, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(1, 1, false,
+		transaction("t1", "2019-12-31T10:00:00Z"),
+		transaction("t2", "2020-01-15T10:00:00Z"),
+		transaction("t3", "2020-02-01T10:00:00Z"))))
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "booking date (2019-12-31T10:00:00Z) is before fromBookingDateTime")
+	assert.Contains(t, errs[1].Error(), "booking date (2020-02-01T10:00:00Z) is after toBookingDateTime")
+}
+
+func TestPageTraversalIncoherentLinks(t *testing.T) {
+	traversal := newTestTraversal(t, pagingBase)
+
+	_, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(1, 3, true)))
+	require.Empty(t, errs)
+
+	body := `{"Data":{},"Links":{"Self":"` + pagingBase + `?page=2","First":"` + pagingBase + `?page=0","Prev":"` + pagingBase + `?page=5","Last":"` + pagingBase + `?page=3"},"Meta":{"TotalPages":4}}`
+	next, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", body))
+	assert.Empty(t, next)
+	require.Len(t, errs, 4)
+	assert.Contains(t, errs[0].Error(), "Links.First")
+	assert.Contains(t, errs[1].Error(), "Links.Prev")
+	assert.Contains(t, errs[2].Error(), "is not Links.Last")
+	assert.Contains(t, errs[3].Error(), "Meta.TotalPages (4) differs from page 1 (3)")
+}
+
+func TestPageTraversalLoopAndTotalPages(t *testing.T) {
+	traversal := newTestTraversal(t, pagingBase)
+
+	body := `{"Data":{},"Links":{"Self":"` + pagingBase + `?page=1&a=b","Next":"` + pagingBase + `?a=b&page=1"},"Meta":{"TotalPages":2}}`
+	next, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", body))
+	assert.Empty(t, next)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "points to a page already visited")
+
+	errs = traversal.Finish(true)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "paging: Meta.TotalPages (2) does not match the 1 pages received")
+	assert.Empty(t, traversal.Finish(false))
+}
+
+func TestPageTraversalDataRulesOnLaterPages(t *testing.T) {
+	tc := &TestCase{Resource: "Transaction", Expect: Expect{SchemaValidation: true}, Validator: schema.NewNullValidator()}
+	traversal, err := (&Paging{}).NewTraversal(tc, pagingBase)
+	require.NoError(t, err)
+
+	_, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(1, 2, true, `{"TransactionId":"t1","CreditDebitIndicator":"Sideways"}`)))
+	require.Empty(t, errs, "the first page is checked with the response")
+	_, errs = traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(2, 2, false, `{"TransactionId":"t2","CreditDebitIndicator":"Sideways"}`)))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "paging: page 2: data rule Code violated at Data.Transaction.0.CreditDebitIndicator")
+}
+
+func TestPageTraversalLaterPageStatus(t *testing.T) {
+	traversal := newTestTraversal(t, pagingBase)
+	_, errs := traversal.Check(test.CreateHTTPResponse(200, "OK", transactionsPage(1, 2, true)))
+	require.Empty(t, errs)
+	_, errs = traversal.Check(test.CreateHTTPResponse(500, "Internal Server Error", `{}`))
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "paging: page 2: HTTP Status code does not match: expected 200 got 500")
+}
+
+func TestNewTraversalInvalidBookingDate(t *testing.T) {
+	_, err := (&Paging{}).NewTraversal(&TestCase{}, pagingBase+"?fromBookingDateTime=yesterday")
+	assert.EqualError(t, err, "paging: query parameter fromBookingDateTime: (yesterday) is not a date")
+}
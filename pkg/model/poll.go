@@ -0,0 +1,141 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultPollTimeout  = 60 * time.Second
+)
+
+// Poll describes an expectation which cannot be met by a single response, typically a payment
+// whose status moves asynchronously through its lifecycle. The request is re-issued every
+// `interval-ms` until the value selected by `json` is one of `terminal-states` or `failure-states`, or
+// `timeout-ms` elapses. Reaching one of `failure-states` fails the test. Each observed value is checked
+// against the allowed `transitions`, so an ASPSP that moves a payment from a terminal state back to a
+// pending one fails the test.
+//
+//	"poll": {
+//	    "json": "Data.Status",
+//	    "terminal-states": ["AcceptedSettlementCompleted"],
+//	    "failure-states": ["Rejected"],
+//	    "interval-ms": 2000,
+//	    "timeout-ms": 60000
+//	}
+type Poll struct {
+	JSON           string              `json:"json"`                     // Json expression selecting the status value
+	TerminalStates []string            `json:"terminal-states"`          // Values which stop the polling
+	FailureStates  []string            `json:"failure-states,omitempty"` // Values which stop the polling and fail the test
+	Transitions    map[string][]string `json:"transitions,omitempty"`    // Allowed transitions, defaults to the OB payment and consent lifecycles
+	IntervalMs     int                 `json:"interval-ms,omitempty"`    // Time between requests, defaults to 2 seconds
+	TimeoutMs      int                 `json:"timeout-ms,omitempty"`     // Maximum time to wait for a terminal state, defaults to 60 seconds
+}
+
+// defaultStatusTransitions lists the legal status transitions of the OB payment order and
+// payment consent resources. A status may always be observed more than once in a row.
+var defaultStatusTransitions = map[string][]string{
+	"AwaitingAuthorisation":             {"Authorised", "Rejected"},
+	"Authorised":                        {"Consumed", "Rejected"},
+	"Pending":                           {"AcceptedSettlementInProcess", "AcceptedSettlementCompleted", "AcceptedCreditSettlementCompleted", "AcceptedWithoutPosting", "Rejected"},
+	"AcceptedSettlementInProcess":       {"AcceptedSettlementCompleted", "AcceptedCreditSettlementCompleted", "AcceptedWithoutPosting", "Rejected"},
+	"AcceptedWithoutPosting":            {"AcceptedCreditSettlementCompleted"},
+	"AcceptedSettlementCompleted":       {"AcceptedCreditSettlementCompleted"},
+	"AcceptedCreditSettlementCompleted": {},
+	"Consumed":                          {},
+	"Rejected":                          {},
+	"InitiationPending":                 {"InitiationCompleted", "InitiationFailed"},
+	"InitiationCompleted":               {},
+	"InitiationFailed":                  {},
+}
+
+// Interval returns the time to wait between two requests
+func (p *Poll) Interval() time.Duration {
+	if p.IntervalMs <= 0 {
+		return defaultPollInterval
+	}
+	return time.Duration(p.IntervalMs) * time.Millisecond
+}
+
+// Timeout returns the maximum time to wait for a terminal state
+func (p *Poll) Timeout() time.Duration {
+	if p.TimeoutMs <= 0 {
+		return defaultPollTimeout
+	}
+	return time.Duration(p.TimeoutMs) * time.Millisecond
+}
+
+// State selects the polled value from a response body
+func (p *Poll) State(body string) (string, error) {
+	result := gjson.Get(body, p.JSON)
+	if !result.Exists() {
+		return "", fmt.Errorf("poll: no field present for pattern (%s)", p.JSON)
+	}
+	return result.String(), nil
+}
+
+// IsTerminal returns true if state is one of the terminal or failure states
+func (p *Poll) IsTerminal(state string) bool {
+	for _, terminal := range p.TerminalStates {
+		if state == terminal {
+			return true
+		}
+	}
+	return p.IsFailure(state)
+}
+
+// IsFailure returns true if state is one of the failure states
+func (p *Poll) IsFailure(state string) bool {
+	for _, failure := range p.FailureStates {
+		if state == failure {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition returns an error if moving from one state to another is not allowed.
+// States which are not part of the transition table cannot be judged and are accepted.
+func (p *Poll) CheckTransition(from, to string) error {
+	if from == to {
+		return nil
+	}
+	transitions := p.Transitions
+	if transitions == nil {
+		transitions = defaultStatusTransitions
+	}
+	allowed, known := transitions[from]
+	if !known {
+		return nil
+	}
+	for _, state := range allowed {
+		if state == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("poll: illegal status transition from %s to %s", from, to)
+}
+
+// Clone duplicates a Poll into a separate independent object
+func (p *Poll) Clone() *Poll {
+	if p == nil {
+		return nil
+	}
+	po := &Poll{
+		JSON:           p.JSON,
+		TerminalStates: append([]string{}, p.TerminalStates...),
+		FailureStates:  append([]string{}, p.FailureStates...),
+		IntervalMs:     p.IntervalMs,
+		TimeoutMs:      p.TimeoutMs,
+	}
+	if p.Transitions != nil {
+		po.Transitions = make(map[string][]string, len(p.Transitions))
+		for k, v := range p.Transitions {
+			po.Transitions[k] = append([]string{}, v...)
+		}
+	}
+	return po
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollDefaults(t *testing.T) {
+	p := &Poll{JSON: "Data.Status"}
+	assert.Equal(t, defaultPollInterval, p.Interval())
+	assert.Equal(t, defaultPollTimeout, p.Timeout())
+
+	p = &Poll{JSON: "Data.Status", IntervalMs: 10, TimeoutMs: 500}
+	assert.Equal(t, 10*time.Millisecond, p.Interval())
+	assert.Equal(t, 500*time.Millisecond, p.Timeout())
+}
+
+func TestPollState(t *testing.T) {
+	p := &Poll{JSON: "Data.Status"}
+
+	state, err := p.State(`{"Data":{"Status":"Pending"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Pending", state)
+
+	_, err = p.State(`{"Data":{}}`)
+	assert.EqualError(t, err, "poll: no field present for pattern (Data.Status)")
+}
+
+func TestPollIsTerminal(t *testing.T) {
+	p := &Poll{JSON: "Data.Status", TerminalStates: []string{"AcceptedSettlementCompleted", "Rejected"}}
+	assert.True(t, p.IsTerminal("Rejected"))
+	assert.False(t, p.IsTerminal("Pending"))
+	assert.False(t, p.IsFailure("Rejected"))
+}
+
+func TestPollIsFailure(t *testing.T) {
+	p := &Poll{JSON: "Data.Status", TerminalStates: []string{"AcceptedSettlementCompleted"}, FailureStates: []string{"Rejected"}}
+	assert.True(t, p.IsFailure("Rejected"))
+	assert.True(t, p.IsTerminal("Rejected"), "failure states stop the polling")
+	assert.False(t, p.IsFailure("AcceptedSettlementCompleted"))
+}
+
+func TestPollCheckTransitionDefaults(t *testing.T) {
+	p := &Poll{JSON: "Data.Status"}
+	assert.NoError(t, p.CheckTransition("Pending", "Pending"))
+	assert.NoError(t, p.CheckTransition("Pending", "AcceptedSettlementInProcess"))
+	assert.NoError(t, p.CheckTransition("AcceptedSettlementInProcess", "AcceptedSettlementCompleted"))
+	assert.NoError(t, p.CheckTransition("SomethingUnknown", "Pending"))
+	assert.EqualError(t, p.CheckTransition("AcceptedSettlementCompleted", "Pending"),
+		"poll: illegal status transition from AcceptedSettlementCompleted to Pending")
+	assert.Error(t, p.CheckTransition("Rejected", "Authorised"))
+}
+
+func TestPollCheckTransitionCustom(t *testing.T) {
+	p := &Poll{JSON: "Data.Status", Transitions: map[string][]string{"A": {"B"}}}
+	assert.NoError(t, p.CheckTransition("A", "B"))
+	assert.Error(t, p.CheckTransition("A", "C"))
+	// default table is not consulted when transitions are given
+	assert.NoError(t, p.CheckTransition("AcceptedSettlementCompleted", "Pending"))
+}
+
+func TestPollClone(t *testing.T) {
+	var nilPoll *Poll
+	assert.Nil(t, nilPoll.Clone())
+
+	p := &Poll{JSON: "Data.Status", TerminalStates: []string{"AcceptedSettlementCompleted"}, FailureStates: []string{"Rejected"}, Transitions: map[string][]string{"A": {"B"}}, IntervalMs: 1, TimeoutMs: 2}
+	c := p.Clone()
+	assert.Equal(t, p, c)
+
+	c.TerminalStates[0] = "Other"
+	c.FailureStates[0] = "Other"
+	c.Transitions["A"][0] = "C"
+	assert.Equal(t, "AcceptedSettlementCompleted", p.TerminalStates[0])
+	assert.Equal(t, "Rejected", p.FailureStates[0])
+	assert.Equal(t, "B", p.Transitions["A"][0])
+}
+
+func TestExpectCloneCopiesPoll(t *testing.T) {
+	e := Expect{Poll: &Poll{JSON: "Data.Status", TerminalStates: []string{"AcceptedSettlementCompleted"}, FailureStates: []string{"Rejected"}}}
+	c := e.Clone()
+	require.NotNil(t, c.Poll)
+	assert.Equal(t, e.Poll, c.Poll)
+	assert.NotSame(t, e.Poll, c.Poll)
+}
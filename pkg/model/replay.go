@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"gopkg.in/resty.v1"
+)
+
+// Replay sends the prepared request of a testcase a second time, keeping its x-idempotency-key.
+// ASPSPs must treat a request repeated with the same key and body as the original one, and reject
+// a key reused with a different body, which is exercised by changing the replayed `body`.
+//
+//	"replay": {
+//	    "body": {"Data.Initiation.InstructedAmount.Amount": "1.99"}
+//	}
+type Replay struct {
+	Body map[string]string `json:"body,omitempty"` // Json path/value pairs changed in the replayed request body
+}
+
+// ReplayExpect compares the response to a replayed request with the response to the original one.
+// When a testcase is replayed without a replay expectation, the status codes of both responses must match.
+//
+//	"replay": {
+//	    "status-code": 201,
+//	    "same-json": ["Data.ConsentId"]
+//	}
+type ReplayExpect struct {
+	StatusCode int      `json:"status-code,omitempty"` // Expected status code of the replay, defaults to the original status code
+	SameJSON   []string `json:"same-json,omitempty"`   // Json expressions whose values must be identical in both responses
+}
+
+// ApplyReplay changes the request body of a prepared testcase before it is replayed.
+// The x-jws-signature header is computed again over the changed body so that the ASPSP judges
+// the reuse of the x-idempotency-key rather than the signature.
+func (t *TestCase) ApplyReplay(req *resty.Request, ctx *Context) error {
+	if t.Replay == nil || len(t.Replay.Body) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(t.Replay.Body))
+	for path := range t.Replay.Body {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	body := t.Input.RequestBody
+	for _, path := range paths {
+		value, err := replaceContextField(t.Replay.Body[path], ctx)
+		if err != nil {
+			return t.AppErr(fmt.Sprintf("replay: replacing context value %s: %s", t.Replay.Body[path], err.Error()))
+		}
+		body, err = sjson.Set(body, path, value)
+		if err != nil {
+			return t.AppErr(fmt.Sprintf("replay: setting %s in request body: %s", path, err.Error()))
+		}
+	}
+	t.Input.RequestBody = body
+	req.SetBody(body)
+
+	if _, signed := t.Input.Headers["x-jws-signature"]; signed {
+		if err := t.Input.createJWSDetachedSignature(ctx); err != nil {
+			return err
+		}
+		req.SetHeader("x-jws-signature", t.Input.Headers["x-jws-signature"])
+	}
+	return nil
+}
+
+// Check compares the response to a replayed request with the response to the original request
+func (r *ReplayExpect) Check(original, replayed *resty.Response) []error {
+	errs := []error{}
+
+	expected := original.StatusCode()
+	if r != nil && r.StatusCode != 0 {
+		expected = r.StatusCode
+	}
+	if replayed.StatusCode() != expected {
+		errs = append(errs, fmt.Errorf("replay: (%d) actual status code does not match expected status code (%d)", replayed.StatusCode(), expected))
+	}
+
+	if r == nil {
+		return errs
+	}
+	for _, path := range r.SameJSON {
+		first := gjson.Get(original.String(), path)
+		second := gjson.Get(replayed.String(), path)
+		if !first.Exists() || !second.Exists() {
+			errs = append(errs, fmt.Errorf("replay: no field present for pattern (%s) in both responses", path))
+			continue
+		}
+		if first.String() != second.String() {
+			errs = append(errs, fmt.Errorf("replay: %s differs, original (%s) replayed (%s)", path, first.String(), second.String()))
+		}
+	}
+	return errs
+}
+
+// Clone duplicates a Replay into a separate independent object
+func (r *Replay) Clone() *Replay {
+	if r == nil {
+		return nil
+	}
+	re := &Replay{}
+	if r.Body != nil {
+		re.Body = make(map[string]string, len(r.Body))
+		for k, v := range r.Body {
+			re.Body[k] = v
+		}
+	}
+	return re
+}
+
+// Clone duplicates a ReplayExpect into a separate independent object
+func (r *ReplayExpect) Clone() *ReplayExpect {
+	if r == nil {
+		return nil
+	}
+	return &ReplayExpect{
+		StatusCode: r.StatusCode,
+		SameJSON:   append([]string{}, r.SameJSON...),
+	}
+}
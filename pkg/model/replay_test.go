@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"gopkg.in/resty.v1"
+)
+
+func TestApplyReplayWithoutBodyChangesKeepsRequest(t *testing.T) {
+	tc := TestCase{Replay: &Replay{}, Input: Input{RequestBody: `{"Data":{}}`}}
+	req := resty.R()
+
+	require.NoError(t, tc.ApplyReplay(req, &Context{}))
+	assert.Nil(t, req.Body)
+	assert.Equal(t, `{"Data":{}}`, tc.Input.RequestBody)
+}
+
+func TestApplyReplayChangesBodyAndSignature(t *testing.T) {
+	ctx.PutStringSlice("apiversions", []string{"payments_v3.1.3"})
+	ctx.PutString("tpp_signature_kid", "x")
+	ctx.PutString("tpp_signature_issuer", "x/x")
+	ctx.PutString("tpp_signature_tan", "openbanking.org.uk")
+	cert, err := authentication.SigningCertFromContext(ctx)
+	require.NoError(t, err)
+
+	tc := TestCase{
+		ID:     "OB-301-DOP-103010",
+		Input:  Input{JwsSig: true, IdempotencyKey: true, Method: "POST", Endpoint: "https://example.com", RequestBody: "$domestic_payment_template", Headers: map[string]string{"Content-Type": "application/json"}},
+		Replay: &Replay{Body: map[string]string{"Data.Initiation.CreditorAccount.Name": "Changed"}},
+	}
+	req, err := tc.Prepare(&ctx)
+	require.NoError(t, err)
+	key := req.Header.Get("x-idempotency-key")
+	signature := req.Header.Get("x-jws-signature")
+	require.NotEmpty(t, key)
+	require.NotEmpty(t, signature)
+
+	require.NoError(t, tc.ApplyReplay(req, &ctx))
+
+	assert.Equal(t, "Changed", gjson.Get(tc.Input.RequestBody, "Data.Initiation.CreditorAccount.Name").String())
+	assert.Equal(t, tc.Input.RequestBody, req.Body)
+	assert.Equal(t, key, req.Header.Get("x-idempotency-key"))
+	assert.NotEqual(t, signature, req.Header.Get("x-jws-signature"))
+	validatedOK, err := validateSignatureTest(req.Header.Get("x-jws-signature"), tc.Input.RequestBody, authentication.SigningMethodPS256, cert.PublicKey())
+	assert.NoError(t, err)
+	assert.True(t, validatedOK)
+}
+
+func TestReplayExpectCheck(t *testing.T) {
+	original := test.CreateHTTPResponse(201, "Created", `{"Data":{"ConsentId":"c-1"}}`)
+
+	var noExpect *ReplayExpect
+	assert.Empty(t, noExpect.Check(original, test.CreateHTTPResponse(201, "Created", `{"Data":{"ConsentId":"c-2"}}`)))
+	assert.Len(t, noExpect.Check(original, test.CreateHTTPResponse(400, "Bad Request", `{}`)), 1)
+
+	expect := &ReplayExpect{StatusCode: 201, SameJSON: []string{"Data.ConsentId"}}
+	assert.Empty(t, expect.Check(original, test.CreateHTTPResponse(201, "Created", `{"Data":{"ConsentId":"c-1"}}`)))
+
+	errs := expect.Check(original, test.CreateHTTPResponse(201, "Created", `{"Data":{"ConsentId":"c-2"}}`))
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "replay: Data.ConsentId differs, original (c-1) replayed (c-2)")
+
+	errs = expect.Check(original, test.CreateHTTPResponse(400, "Bad Request", `{}`))
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "replay: (400) actual status code does not match expected status code (201)")
+	assert.EqualError(t, errs[1], "replay: no field present for pattern (Data.ConsentId) in both responses")
+
+	rejected := &ReplayExpect{StatusCode: 400}
+	assert.Empty(t, rejected.Check(original, test.CreateHTTPResponse(400, "Bad Request", `{}`)))
+}
+
+func TestReplayClone(t *testing.T) {
+	var r *Replay
+	assert.Nil(t, r.Clone())
+	var e *ReplayExpect
+	assert.Nil(t, e.Clone())
+
+	r = &Replay{Body: map[string]string{"a": "b"}}
+	c := r.Clone()
+	c.Body["a"] = "c"
+	assert.Equal(t, "b", r.Body["a"])
+
+	e = &ReplayExpect{StatusCode: 201, SameJSON: []string{"Data.ConsentId"}}
+	ce := e.Clone()
+	assert.Equal(t, e, ce)
+	ce.SameJSON[0] = "other"
+	assert.Equal(t, "Data.ConsentId", e.SameJSON[0])
+}
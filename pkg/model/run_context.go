@@ -0,0 +1,263 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
+)
+
+// Scope is the lifetime of the values of a RunContext, from the widest to the narrowest
+type Scope int
+
+// Scopes of a RunContext. A value of a narrower scope hides the value of a wider scope with the same name.
+const (
+	ScopeGlobal  Scope = iota // Configuration and discovery, set by the journey
+	ScopeJourney              // Tokens and consent ids acquired by the journey, kept across runs
+	ScopeRun                  // Values kept in context by the tests of the run, cleared when a run starts
+	ScopeSpec                 // Values of the API specification being run, cleared for each specification
+	ScopeTest                 // Local context of the test being run, cleared after each test
+	scopeCount
+)
+
+var scopeNames = [scopeCount]string{"global", "journey", "run", "spec", "test"}
+
+func (s Scope) String() string {
+	if s < 0 || s >= scopeCount {
+		return "unknown"
+	}
+	return scopeNames[s]
+}
+
+// RunContext is the context shared by the journey, the token collector and the runner. It is safe for
+// concurrent use. Tests do not run against it directly but against a snapshot taken with NewTestContext,
+// the values they put in the snapshot are kept in the run scope by Commit.
+type RunContext struct {
+	lock   sync.RWMutex
+	scopes [scopeCount]Context
+}
+
+// NewRunContext returns a RunContext with a copy of global as global scope
+func NewRunContext(global Context) *RunContext {
+	c := &RunContext{}
+	for i := range c.scopes {
+		c.scopes[i] = Context{}
+	}
+	for k, v := range global {
+		c.scopes[ScopeGlobal][k] = v
+	}
+	return c
+}
+
+// Get returns the value of key from the narrowest scope holding it
+func (c *RunContext) Get(key string) (interface{}, bool) {
+	value, _, exists := c.Lookup(key)
+	return value, exists
+}
+
+// Lookup returns the value of key and the narrowest scope holding it
+func (c *RunContext) Lookup(key string) (interface{}, Scope, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lookup(key)
+}
+
+func (c *RunContext) lookup(key string) (interface{}, Scope, bool) {
+	for scope := scopeCount - 1; scope >= ScopeGlobal; scope-- {
+		if value, exists := c.scopes[scope][key]; exists {
+			return value, scope, true
+		}
+	}
+	return nil, ScopeGlobal, false
+}
+
+// GetString returns the string value of key, ErrNotFound when there is none
+func (c *RunContext) GetString(key string) (string, error) {
+	value, exists := c.Get(key)
+	if !exists {
+		return "", ErrNotFound
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return "", errors.New("error casting key to string")
+	}
+	return valueStr, nil
+}
+
+// GetBool returns the bool value of key, ErrNotFound when there is none
+func (c *RunContext) GetBool(key string) (bool, error) {
+	value, exists := c.Get(key)
+	if !exists {
+		return false, ErrNotFound
+	}
+	valueBool, ok := value.(bool)
+	if !ok {
+		return false, errors.New("error casting key to bool")
+	}
+	return valueBool, nil
+}
+
+// GetStringSlice returns the string slice value of key, ErrNotFound when there is none
+func (c *RunContext) GetStringSlice(key string) ([]string, error) {
+	value, exists := c.Get(key)
+	if !exists {
+		return nil, ErrNotFound
+	}
+	ctx := Context{key: value}
+	return ctx.GetStringSlice(key)
+}
+
+// Put sets the value of key in scope
+func (c *RunContext) Put(scope Scope, key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.scopes[scope][key] = value
+}
+
+// PutStringSlice sets the value of key in scope to values, as Context.PutStringSlice does
+func (c *RunContext) PutStringSlice(scope Scope, key string, values []string) {
+	valuesCasted := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		valuesCasted = append(valuesCasted, value)
+	}
+	c.Put(scope, key, valuesCasted)
+}
+
+// PutContext sets the values of ctx in scope
+func (c *RunContext) PutContext(scope Scope, ctx Context) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, v := range ctx {
+		c.scopes[scope][k] = v
+	}
+}
+
+// Clear removes the values of scope
+func (c *RunContext) Clear(scope Scope) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.scopes[scope] = Context{}
+}
+
+// Snapshot returns a copy of the values of all the scopes, narrower scopes hiding wider ones. Values
+// themselves, such as slices, are not copied and must not be modified.
+func (c *RunContext) Snapshot() Context {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.snapshot()
+}
+
+func (c *RunContext) snapshot() Context {
+	snapshot := Context{}
+	for _, values := range c.scopes {
+		for k, v := range values {
+			snapshot[k] = v
+		}
+	}
+	return snapshot
+}
+
+// Update calls update with a snapshot of the context, for functions taking a *Context, then sets the values
+// update put in the snapshot in scope and removes from scope those it deleted. The context is not locked while
+// update runs, which may take long, such as calls to the ASPSP: values put concurrently by others are kept unless
+// update changed them too.
+func (c *RunContext) Update(scope Scope, update func(ctx *Context) error) error {
+	before := c.Snapshot()
+	after := c.Snapshot()
+	err := update(&after)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, v := range after {
+		if previous, exists := before[k]; !exists || !reflect.DeepEqual(previous, v) {
+			c.scopes[scope][k] = v
+		}
+	}
+	for k := range before {
+		if _, exists := after[k]; !exists {
+			delete(c.scopes[scope], k)
+		}
+	}
+	return err
+}
+
+// ContextChange is a context value read or written by a test
+type ContextChange struct {
+	Key   string `json:"key"`
+	Scope string `json:"scope"` // Scope the value was read from, or written to
+	Value string `json:"value"` // Value, JSON when not a string
+}
+
+// TestContext is the context a test runs with: a snapshot of the RunContext with the local context of the test
+type TestContext struct {
+	Values Context // Passed to the test, which puts the values it keeps in context in it
+	before Context
+	scopes map[string]Scope
+}
+
+// NewTestContext sets the test scope to the local context of a test and returns the snapshot the test runs with
+func (c *RunContext) NewTestContext(local Context) *TestContext {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.scopes[ScopeTest] = Context{}
+	for k, v := range local {
+		c.scopes[ScopeTest][k] = v
+	}
+	t := &TestContext{Values: c.snapshot(), before: c.snapshot(), scopes: map[string]Scope{}}
+	for k := range t.before {
+		_, scope, _ := c.lookup(k)
+		t.scopes[k] = scope
+	}
+	return t
+}
+
+// Commit keeps in the run scope the values the test case put in its context and clears the test scope. It
+// returns, sorted by key, the values referenced by the test case found in the context it ran with and the values
+// it wrote. Tokens and credentials are not returned, only their names.
+func (c *RunContext) Commit(t *TestContext, tc *TestCase) (read, written []ContextChange) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	tokens := ContextReferences(tc.Bearer)
+	for _, key := range tc.ContextReferences() {
+		value, exists := t.before[key]
+		if !exists {
+			continue
+		}
+		change := newContextChange(key, t.scopes[key], value)
+		if containsString(tokens, key) {
+			change.Value = redact.Value
+		}
+		read = append(read, change)
+	}
+	for k, v := range t.Values {
+		if previous, exists := t.before[k]; exists && reflect.DeepEqual(previous, v) {
+			continue
+		}
+		c.scopes[ScopeRun][k] = v
+		written = append(written, newContextChange(k, ScopeRun, v))
+	}
+	c.scopes[ScopeTest] = Context{}
+
+	sort.Slice(read, func(i, j int) bool { return read[i].Key < read[j].Key })
+	sort.Slice(written, func(i, j int) bool { return written[i].Key < written[j].Key })
+	return read, written
+}
+
+func newContextChange(key string, scope Scope, value interface{}) ContextChange {
+	if isSensitiveContextKey(key) {
+		return ContextChange{Key: key, Scope: scope.String(), Value: redact.Value}
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		raw, err := json.Marshal(value)
+		if err == nil {
+			valueStr = string(raw)
+		}
+	}
+	return ContextChange{Key: key, Scope: scope.String(), Value: redact.String(valueStr)}
+}
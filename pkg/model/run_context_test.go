@@ -0,0 +1,149 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunContextScopes(t *testing.T) {
+	ctx := NewRunContext(Context{"baseurl": "https://rs.aspsp.example.com", "phase": "run"})
+	ctx.Put(ScopeJourney, "Token001", "token-1")
+	ctx.Put(ScopeSpec, "apiVersion", "v3.1")
+	ctx.Put(ScopeRun, "baseurl", "https://other.aspsp.example.com")
+
+	value, scope, exists := ctx.Lookup("baseurl")
+	assert.True(t, exists)
+	assert.Equal(t, "https://other.aspsp.example.com", value)
+	assert.Equal(t, ScopeRun, scope)
+	_, exists = ctx.Get("missing")
+	assert.False(t, exists)
+
+	ctx.Clear(ScopeSpec)
+	_, exists = ctx.Get("apiVersion")
+	assert.False(t, exists)
+
+	assert.Equal(t, Context{"baseurl": "https://other.aspsp.example.com", "phase": "run", "Token001": "token-1"}, ctx.Snapshot())
+	assert.Equal(t, "journey", ScopeJourney.String())
+	assert.Equal(t, "run", ScopeRun.String())
+	assert.Equal(t, "unknown", Scope(7).String())
+}
+
+func TestRunContextTypedAccessors(t *testing.T) {
+	ctx := NewRunContext(Context{"phase": "run", "dynamic": true})
+	ctx.PutStringSlice(ScopeGlobal, "apiversions", []string{"accounts_v3.1.6"})
+
+	phase, err := ctx.GetString("phase")
+	require.NoError(t, err)
+	assert.Equal(t, "run", phase)
+	dynamic, err := ctx.GetBool("dynamic")
+	require.NoError(t, err)
+	assert.True(t, dynamic)
+	versions, err := ctx.GetStringSlice("apiversions")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"accounts_v3.1.6"}, versions)
+
+	_, err = ctx.GetString("missing")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = ctx.GetString("dynamic")
+	assert.EqualError(t, err, "error casting key to string")
+	_, err = ctx.GetBool("phase")
+	assert.EqualError(t, err, "error casting key to bool")
+	_, err = ctx.GetStringSlice("missing")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestRunContextUpdate(t *testing.T) {
+	ctx := NewRunContext(Context{"client_id": "client", "removed": "value"})
+	ctx.Put(ScopeJourney, "removed", "value")
+
+	err := ctx.Update(ScopeJourney, func(c *Context) error {
+		clientID, _ := c.GetString("client_id")
+		c.PutString("Token001", clientID+"-token")
+		c.Delete("removed")
+		return errors.New("partial")
+	})
+
+	assert.EqualError(t, err, "partial")
+	value, scope, _ := ctx.Lookup("Token001")
+	assert.Equal(t, "client-token", value)
+	assert.Equal(t, ScopeJourney, scope)
+	// only removed from the scope updated
+	_, scope, _ = ctx.Lookup("removed")
+	assert.Equal(t, ScopeGlobal, scope)
+}
+
+func TestRunContextUpdateIsNotLocked(t *testing.T) {
+	ctx := NewRunContext(Context{"client_id": "client"})
+
+	err := ctx.Update(ScopeJourney, func(c *Context) error {
+		// as the token collector does while a consent is acquired
+		ctx.Put(ScopeJourney, "Token002", "token-2")
+		clientID, err := ctx.GetString("client_id")
+		c.PutString("Token001", clientID+"-token")
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Context{"client_id": "client", "Token001": "client-token", "Token002": "token-2"}, ctx.Snapshot())
+}
+
+func TestRunContextTestContext(t *testing.T) {
+	ctx := NewRunContext(Context{"baseurl": "https://rs.aspsp.example.com"})
+	ctx.Put(ScopeJourney, "OB-301-DOP-100100-ConsentId", "sdp-1-b5bbdb18")
+	ctx.Put(ScopeJourney, "Token001", "secret-token")
+	tc := TestCase{
+		Input:   Input{Endpoint: "/domestic-payments", RequestBody: `{"ConsentId":"$OB-301-DOP-100100-ConsentId"}`},
+		Bearer:  "$Token001",
+		Context: Context{"postData": "$missing"},
+	}
+
+	testCtx := ctx.NewTestContext(tc.Context)
+	assert.Equal(t, "$missing", testCtx.Values["postData"])
+	testCtx.Values.PutString("OB-301-DOP-100600-DomesticPaymentId", "pv3-1")
+	testCtx.Values.PutString("client_access_token", "secret-ccg-token")
+	testCtx.Values.Put("postData", "$missing") // rewrites of the local context are not writes
+
+	read, written := ctx.Commit(testCtx, &tc)
+
+	assert.Equal(t, []ContextChange{
+		{Key: "OB-301-DOP-100100-ConsentId", Scope: "journey", Value: "sdp-1-b5bbdb18"},
+		{Key: "Token001", Scope: "journey", Value: "[redacted]"},
+	}, read)
+	assert.Equal(t, []ContextChange{
+		{Key: "OB-301-DOP-100600-DomesticPaymentId", Scope: "run", Value: "pv3-1"},
+		{Key: "client_access_token", Scope: "run", Value: "[redacted]"},
+	}, written)
+
+	value, scope, _ := ctx.Lookup("OB-301-DOP-100600-DomesticPaymentId")
+	assert.Equal(t, "pv3-1", value)
+	assert.Equal(t, ScopeRun, scope)
+	_, exists := ctx.Get("postData")
+	assert.False(t, exists, "the local context of a test is not kept after it")
+}
+
+func TestRunContextConcurrentUse(t *testing.T) {
+	ctx := NewRunContext(Context{"baseurl": "https://rs.aspsp.example.com"})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("Token%03d", i)
+			ctx.Put(ScopeRun, key, key)
+			_, _ = ctx.GetString("baseurl")
+			snapshot := ctx.Snapshot()
+			snapshot.PutString("local", key)
+			testCtx := ctx.NewTestContext(Context{})
+			ctx.Commit(testCtx, &TestCase{})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, ctx.Snapshot(), 21)
+}
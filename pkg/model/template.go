@@ -0,0 +1,787 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Templates are `${ expression }` substitutions in manifest parameters, bodies, headers, uris and component inputs.
+// An expression is evaluated against the context: `$name` is a context value, `name(...)` a template function or
+// a macro, `[index]` and `.field` select an element of an array or an object, `+` adds numbers and durations to times
+// or concatenates strings, `-` subtracts. Strings are quoted with `'` or `"`, numbers, `true`, `false` and `null` are
+// literals. Only the functions of templateFuncs and the macros can be called. e.g.
+//   ${formatTime(addDays(now(), -90), 'ISODate')}
+//   ${$accountIds[0]}/transactions?from=${urlEncode($fromDate)}
+
+// maxTemplateDepth bounds the nesting of expressions, so a template cannot exhaust the stack
+const maxTemplateDepth = 32
+
+// TemplateError is an error parsing or evaluating a template, at a position of the source
+type TemplateError struct {
+	Source string
+	Pos    int // Byte offset in Source, from 0
+	Msg    string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template %q, column %d: %s", e.Source, e.Pos+1, e.Msg)
+}
+
+// HasTemplates returns true when source contains a `${` substitution
+func HasTemplates(source string) bool {
+	return strings.Contains(source, "${")
+}
+
+// ExpandTemplates replaces each `${ expression }` of source with the value of the expression, evaluated against ctx.
+// Values which are not strings are rendered as JSON, times as RFC3339 and durations as `1h30m0s`.
+func ExpandTemplates(source string, ctx *Context) (string, error) {
+	if !HasTemplates(source) {
+		return source, nil
+	}
+	templates, err := parseTemplates(source)
+	if err != nil {
+		return source, err
+	}
+
+	b := strings.Builder{}
+	last := 0
+	for _, t := range templates {
+		value, err := t.expr.eval(&templateEval{source: source, ctx: ctx})
+		if err != nil {
+			return source, err
+		}
+		rendered, err := renderTemplateValue(value)
+		if err != nil {
+			return source, &TemplateError{Source: source, Pos: t.start, Msg: err.Error()}
+		}
+		b.WriteString(source[last:t.start])
+		b.WriteString(rendered)
+		last = t.end
+	}
+	b.WriteString(source[last:])
+	return b.String(), nil
+}
+
+// ValidateTemplates checks the templates of source parse and only call functions and macros which exist, with
+// the number of parameters they take
+func ValidateTemplates(source string) error {
+	if !HasTemplates(source) {
+		return nil
+	}
+	_, err := parseTemplates(source)
+	return err
+}
+
+// templateFunc is a function which can be called by a template, arity -1 taking any number of parameters
+type templateFunc struct {
+	arity int
+	fn    func(args []interface{}) (interface{}, error)
+}
+
+var templateFuncs = map[string]templateFunc{
+	"now": {0, func(args []interface{}) (interface{}, error) {
+		return time.Now().UTC(), nil
+	}},
+	"addDays": {2, func(args []interface{}) (interface{}, error) {
+		t, err := templateTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+		days, err := templateInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return t.AddDate(0, 0, days), nil
+	}},
+	"duration": {1, func(args []interface{}) (interface{}, error) {
+		s, err := templateString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return time.ParseDuration(s)
+	}},
+	"formatTime": {2, func(args []interface{}) (interface{}, error) {
+		t, err := templateTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+		layout, err := templateString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(templateLayout(layout)), nil
+	}},
+	"parseTime": {2, func(args []interface{}) (interface{}, error) {
+		s, err := templateString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		layout, err := templateString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return time.Parse(templateLayout(layout), s)
+	}},
+	"concat": {-1, func(args []interface{}) (interface{}, error) {
+		b := strings.Builder{}
+		for _, arg := range args {
+			s, err := renderTemplateValue(arg)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(s)
+		}
+		return b.String(), nil
+	}},
+	"upper":        stringTemplateFunc(strings.ToUpper),
+	"lower":        stringTemplateFunc(strings.ToLower),
+	"trim":         stringTemplateFunc(strings.TrimSpace),
+	"urlEncode":    stringTemplateFunc(url.QueryEscape),
+	"base64Encode": stringTemplateFunc(func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }),
+	"uuid": {0, func(args []interface{}) (interface{}, error) {
+		return uuid.New().String(), nil
+	}},
+	"len": {1, func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case string:
+			return float64(len(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("cannot take the length of %s", templateTypeName(args[0]))
+	}},
+	"string": {1, func(args []interface{}) (interface{}, error) {
+		return renderTemplateValue(args[0])
+	}},
+	"json": {1, func(args []interface{}) (interface{}, error) {
+		s, err := templateString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			return nil, fmt.Errorf("invalid json: %s", err.Error())
+		}
+		return value, nil
+	}},
+}
+
+// templateLayouts are names which can be given to formatTime and parseTime instead of a Go time layout
+var templateLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"ISODate":     "2006-01-02",
+	"ISODateTime": "2006-01-02T15:04:05-07:00",
+}
+
+func templateLayout(layout string) string {
+	if named, ok := templateLayouts[layout]; ok {
+		return named
+	}
+	return layout
+}
+
+func stringTemplateFunc(fn func(string) string) templateFunc {
+	return templateFunc{1, func(args []interface{}) (interface{}, error) {
+		s, err := templateString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(s), nil
+	}}
+}
+
+// lookupTemplateFunc returns the template function, or the macro, called name
+func lookupTemplateFunc(name string) (templateFunc, bool) {
+	if fn, ok := templateFuncs[name]; ok {
+		return fn, true
+	}
+	arity, found := MacroArity(name)
+	if !found {
+		return templateFunc{}, false
+	}
+	return templateFunc{arity, func(args []interface{}) (interface{}, error) {
+		params := make([]string, len(args))
+		for i, arg := range args {
+			param, err := renderTemplateValue(arg)
+			if err != nil {
+				return nil, err
+			}
+			params[i] = param
+		}
+		return ExecuteMacro(name, params)
+	}}, true
+}
+
+// template is a `${ expression }` of a source, from start to end excluded
+type template struct {
+	start, end int
+	expr       templateExpr
+}
+
+func parseTemplates(source string) ([]template, error) {
+	templates := []template{}
+	for offset := 0; ; {
+		index := strings.Index(source[offset:], "${")
+		if index == -1 {
+			return templates, nil
+		}
+		start := offset + index
+		p := &templateParser{source: source, pos: start + 2}
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(source) {
+			return nil, p.errorf(start, "missing closing }")
+		}
+		if source[p.pos] != '}' {
+			return nil, p.errorf(p.pos, "unexpected %q", source[p.pos])
+		}
+		templates = append(templates, template{start: start, end: p.pos + 1, expr: expr})
+		offset = p.pos + 1
+	}
+}
+
+// templateParser is a recursive descent parser of an expression:
+//
+//	expr    = unary { ("+" | "-") unary }
+//	unary   = "-" unary | postfix
+//	postfix = primary { "[" expr "]" | "." name }
+//	primary = number | string | "true" | "false" | "null" | "$" name | name "(" [ expr { "," expr } ] ")" | "(" expr ")"
+type templateParser struct {
+	source string
+	pos    int
+}
+
+func (p *templateParser) errorf(pos int, format string, args ...interface{}) error {
+	return &TemplateError{Source: p.source, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *templateParser) skipSpaces() {
+	for p.pos < len(p.source) && strings.IndexByte(" \t\r\n", p.source[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+// peek skips spaces and returns the next character, 0 at the end of the source
+func (p *templateParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.source) {
+		return 0
+	}
+	return p.source[p.pos]
+}
+
+func (p *templateParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.unexpected(fmt.Sprintf("%q", c))
+	}
+	p.pos++
+	return nil
+}
+
+func (p *templateParser) unexpected(expected string) error {
+	if p.pos >= len(p.source) {
+		return p.errorf(p.pos, "unexpected end, expected %s", expected)
+	}
+	return p.errorf(p.pos, "unexpected %q, expected %s", p.source[p.pos], expected)
+}
+
+func (p *templateParser) parseExpr(depth int) (templateExpr, error) {
+	if depth > maxTemplateDepth {
+		return nil, p.errorf(p.pos, "expression nested too deeply")
+	}
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		pos := p.pos
+		p.pos++
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{pos: pos, op: op, left: left, right: right}
+	}
+}
+
+func (p *templateParser) parseUnary(depth int) (templateExpr, error) {
+	if p.peek() == '-' {
+		pos := p.pos
+		p.pos++
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &negExpr{pos: pos, operand: operand}, nil
+	}
+	return p.parsePostfix(depth)
+}
+
+func (p *templateParser) parsePostfix(depth int) (templateExpr, error) {
+	expr, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case '[':
+			pos := p.pos
+			p.pos++
+			index, err := p.parseExpr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(']'); err != nil {
+				return nil, err
+			}
+			expr = &indexExpr{pos: pos, target: expr, index: index}
+		case '.':
+			pos := p.pos
+			p.pos++
+			name := p.name()
+			if name == "" {
+				return nil, p.unexpected("a field name")
+			}
+			expr = &indexExpr{pos: pos, target: expr, index: &literalExpr{value: name}}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *templateParser) parsePrimary(depth int) (templateExpr, error) {
+	c := p.peek()
+	start := p.pos
+	switch {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseExpr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(')')
+	case c == '\'' || c == '"':
+		s, err := p.quoted(c)
+		if err != nil {
+			return nil, err
+		}
+		return &literalExpr{value: s}, nil
+	case c >= '0' && c <= '9':
+		for p.pos < len(p.source) && (isTemplateDigit(p.source[p.pos]) || p.source[p.pos] == '.') {
+			p.pos++
+		}
+		number, err := strconv.ParseFloat(p.source[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf(start, "invalid number %s", p.source[start:p.pos])
+		}
+		return &literalExpr{value: number}, nil
+	case c == '$':
+		p.pos++
+		end := p.pos
+		for end < len(p.source) && (isTemplateNameChar(p.source[end]) || p.source[end] == '-') {
+			end++
+		}
+		if end == p.pos {
+			return nil, p.unexpected("a context value name")
+		}
+		name := p.source[p.pos:end]
+		p.pos = end
+		return &variableExpr{pos: start, name: name}, nil
+	}
+
+	name := p.name()
+	switch name {
+	case "":
+		return nil, p.unexpected("a value")
+	case "true", "false":
+		return &literalExpr{value: name == "true"}, nil
+	case "null":
+		return &literalExpr{value: nil}, nil
+	}
+	fn, found := lookupTemplateFunc(name)
+	if !found {
+		return nil, p.errorf(start, "function %s does not exist", name)
+	}
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	args := []templateExpr{}
+	if p.peek() != ')' {
+		for {
+			arg, err := p.parseExpr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	if fn.arity != -1 && fn.arity != len(args) {
+		return nil, p.errorf(start, "function %s takes %d parameters, %d given", name, fn.arity, len(args))
+	}
+	return &callExpr{pos: start, name: name, fn: fn, args: args}, nil
+}
+
+func (p *templateParser) name() string {
+	start := p.pos
+	for p.pos < len(p.source) && isTemplateNameChar(p.source[p.pos]) && (p.pos > start || !isTemplateDigit(p.source[p.pos])) {
+		p.pos++
+	}
+	return p.source[start:p.pos]
+}
+
+// quoted parses a string quoted with quote, in which `\` escapes the next character
+func (p *templateParser) quoted(quote byte) (string, error) {
+	start := p.pos
+	b := strings.Builder{}
+	for p.pos++; p.pos < len(p.source); p.pos++ {
+		c := p.source[p.pos]
+		if c == quote {
+			p.pos++
+			return b.String(), nil
+		}
+		if c == '\\' && p.pos+1 < len(p.source) {
+			p.pos++
+			c = p.source[p.pos]
+		}
+		b.WriteByte(c)
+	}
+	return "", p.errorf(start, "unterminated string")
+}
+
+func isTemplateDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isTemplateNameChar(c byte) bool {
+	return c == '_' || isTemplateDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// templateEval is the evaluation of the templates of a source
+type templateEval struct {
+	source string
+	ctx    *Context
+}
+
+func (e *templateEval) errorf(pos int, format string, args ...interface{}) error {
+	return &TemplateError{Source: e.source, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// templateExpr is a parsed expression. Values are strings, float64, bool, nil, time.Time, time.Duration,
+// []interface{} and map[string]interface{}
+type templateExpr interface {
+	eval(e *templateEval) (interface{}, error)
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (x *literalExpr) eval(e *templateEval) (interface{}, error) {
+	return x.value, nil
+}
+
+type variableExpr struct {
+	pos  int
+	name string
+}
+
+func (x *variableExpr) eval(e *templateEval) (interface{}, error) {
+	if e.ctx == nil {
+		return nil, e.errorf(x.pos, "$%s not found in context", x.name)
+	}
+	value, exists := e.ctx.Get(x.name)
+	if !exists {
+		return nil, e.errorf(x.pos, "$%s not found in context", x.name)
+	}
+	return normaliseTemplateValue(value), nil
+}
+
+type callExpr struct {
+	pos  int
+	name string
+	fn   templateFunc
+	args []templateExpr
+}
+
+func (x *callExpr) eval(e *templateEval) (interface{}, error) {
+	args := make([]interface{}, len(x.args))
+	for i, arg := range x.args {
+		value, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := x.fn.fn(args)
+	if err != nil {
+		return nil, e.errorf(x.pos, "%s: %s", x.name, err.Error())
+	}
+	return normaliseTemplateValue(value), nil
+}
+
+type indexExpr struct {
+	pos    int
+	target templateExpr
+	index  templateExpr
+}
+
+func (x *indexExpr) eval(e *templateEval) (interface{}, error) {
+	target, err := x.target.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	index, err := x.index.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	switch target := target.(type) {
+	case []interface{}:
+		i, err := templateInt(index)
+		if err != nil {
+			return nil, e.errorf(x.pos, "array index: %s", err.Error())
+		}
+		if i < 0 {
+			i += len(target)
+		}
+		if i < 0 || i >= len(target) {
+			return nil, e.errorf(x.pos, "index %d out of range, length %d", i, len(target))
+		}
+		return target[i], nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, e.errorf(x.pos, "object key must be a string, not %s", templateTypeName(index))
+		}
+		value, exists := target[key]
+		if !exists {
+			return nil, e.errorf(x.pos, "field %s not found", key)
+		}
+		return value, nil
+	}
+	return nil, e.errorf(x.pos, "cannot index %s", templateTypeName(target))
+}
+
+type binaryExpr struct {
+	pos         int
+	op          byte
+	left, right templateExpr
+}
+
+func (x *binaryExpr) eval(e *templateEval) (interface{}, error) {
+	left, err := x.left.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	right, err := x.right.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			if x.op == '+' {
+				return l + r, nil
+			}
+			return l - r, nil
+		}
+	case string:
+		if x.op == '+' {
+			r, err := renderTemplateValue(right)
+			if err != nil {
+				return nil, e.errorf(x.pos, "%s", err.Error())
+			}
+			return l + r, nil
+		}
+	case time.Time:
+		switch r := right.(type) {
+		case time.Duration:
+			if x.op == '+' {
+				return l.Add(r), nil
+			}
+			return l.Add(-r), nil
+		case time.Time:
+			if x.op == '-' {
+				return l.Sub(r), nil
+			}
+		}
+	case time.Duration:
+		if r, ok := right.(time.Duration); ok {
+			if x.op == '+' {
+				return l + r, nil
+			}
+			return l - r, nil
+		}
+	}
+	return nil, e.errorf(x.pos, "cannot apply %c to %s and %s", x.op, templateTypeName(left), templateTypeName(right))
+}
+
+type negExpr struct {
+	pos     int
+	operand templateExpr
+}
+
+func (x *negExpr) eval(e *templateEval) (interface{}, error) {
+	operand, err := x.operand.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	switch v := operand.(type) {
+	case float64:
+		return -v, nil
+	case time.Duration:
+		return -v, nil
+	}
+	return nil, e.errorf(x.pos, "cannot negate %s", templateTypeName(operand))
+}
+
+// normaliseTemplateValue converts the values of the context, and of the macros, to the types of the templates
+func normaliseTemplateValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, float64, bool, time.Time, time.Duration, []interface{}, map[string]interface{}:
+		return value
+	case []string:
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values
+	case map[string]string:
+		values := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			values[k] = s
+		}
+		return values
+	case Context:
+		return map[string]interface{}(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	}
+
+	// other types, e.g. structs, are seen through their JSON
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return fmt.Sprint(value)
+	}
+	return decoded
+}
+
+func renderTemplateValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case time.Duration:
+		return v.String(), nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func templateString(value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %s", templateTypeName(value))
+	}
+	return s, nil
+}
+
+func templateInt(value interface{}) (int, error) {
+	f, ok := value.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, fmt.Errorf("expected an integer, got %s", templateTypeName(value))
+	}
+	return int(f), nil
+}
+
+// templateTime accepts times and RFC3339 strings, so times put in context as strings can be used
+func templateTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("expected a time, got %q", v)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expected a time, got %s", templateTypeName(value))
+}
+
+func templateTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "time"
+	case time.Duration:
+		return "duration"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
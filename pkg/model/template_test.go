@@ -0,0 +1,124 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateContext() *Context {
+	ctx := Context{
+		"consentId":           "sdp-1-b5bbdb18",
+		"accountIds":          []interface{}{"500000000000000000000001", "500000000000000000000002"},
+		"account":             map[string]interface{}{"AccountId": "500000000000000000000001", "Nickname": "Bills"},
+		"count":               3,
+		"fromDate":            "2020-03-01T10:00:00+00:00",
+		"x-fapi-financial-id": "0015800001041RHAAY",
+	}
+	return &ctx
+}
+
+func TestExpandTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"no template", "/accounts/$accountId", "/accounts/$accountId"},
+		{"context value", "${$consentId}", "sdp-1-b5bbdb18"},
+		{"several per string", "/accounts/${$accountIds[0]}/statements/${$accountIds[1]}", "/accounts/500000000000000000000001/statements/500000000000000000000002"},
+		{"negative index", "${$accountIds[-1]}", "500000000000000000000002"},
+		{"field", "${$account.Nickname} ${$account['AccountId']}", "Bills 500000000000000000000001"},
+		{"name with dashes", "${$x-fapi-financial-id}", "0015800001041RHAAY"},
+		{"concatenation", "${$consentId + '-' + $count}", "sdp-1-b5bbdb18-3"},
+		{"arithmetic", "${$count + 2 - -1}", "6"},
+		{"nested calls", "${formatTime(addDays($fromDate, -90), 'ISODate')}", "2019-12-02"},
+		{"duration", "${formatTime(parseTime('2020-03-01', 'ISODate') + duration('36h'), 'RFC3339')}", "2020-03-02T12:00:00Z"},
+		{"time difference", "${parseTime('2020-03-02', 'ISODate') - parseTime('2020-03-01', 'ISODate')}", "24h0m0s"},
+		{"functions", `${upper(concat("a", 1, true))}${len($accountIds)}${urlEncode('a b&c')}`, "A1TRUE2a+b%26c"},
+		{"json", `${json('{"Data":{"Amount":[10.5]}}').Data.Amount[0]}`, "10.5"},
+		{"array rendered as json", "${$accountIds}", `["500000000000000000000001","500000000000000000000002"]`},
+		{"escaped quote", `${'it\'s'}`, "it's"},
+		{"literals", "${true}${null}${(1 + 2)}", "true3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandTemplates(tt.source, templateContext())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestExpandTemplatesCallsMacros(t *testing.T) {
+	result, err := ExpandTemplates("${instructionIdentificationID()}", &Context{})
+	require.NoError(t, err)
+	assert.Regexp(t, "^[a-f0-9]{32}$", result)
+
+	result, err = ExpandTemplates("${currentDateTime('2006')}", &Context{})
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().UTC().Format("2006"), result)
+}
+
+func TestExpandTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string
+	}{
+		{"/accounts/${$missing}", `template "/accounts/${$missing}", column 13: $missing not found in context`},
+		{"${unknown()}", `template "${unknown()}", column 3: function unknown does not exist`},
+		{"${upper('a', 'b')}", `template "${upper('a', 'b')}", column 3: function upper takes 1 parameters, 2 given`},
+		{"${upper($count)}", `template "${upper($count)}", column 3: upper: expected a string, got number`},
+		{"${$accountIds[2]}", `template "${$accountIds[2]}", column 14: index 2 out of range, length 2`},
+		{"${$account.Balance}", `template "${$account.Balance}", column 11: field Balance not found`},
+		{"${$count - 'a'}", `template "${$count - 'a'}", column 10: cannot apply - to number and string`},
+		{"${-$consentId}", `template "${-$consentId}", column 3: cannot negate string`},
+		{"${'unterminated}", `template "${'unterminated}", column 3: unterminated string`},
+		{"${$consentId", `template "${$consentId", column 1: missing closing }`},
+		{"${$consentId)}", `template "${$consentId)}", column 13: unexpected ')'`},
+		{"${upper(}", `template "${upper(}", column 9: unexpected '}', expected a value`},
+		{"${}", `template "${}", column 3: unexpected '}', expected a value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			result, err := ExpandTemplates(tt.source, templateContext())
+			assert.EqualError(t, err, tt.err)
+			assert.Equal(t, tt.source, result)
+		})
+	}
+}
+
+func TestExpandTemplatesNestingIsBounded(t *testing.T) {
+	source := "${"
+	for i := 0; i < 100; i++ {
+		source += "("
+	}
+	_, err := ExpandTemplates(source+"1", &Context{})
+	assert.Contains(t, err.Error(), "expression nested too deeply")
+}
+
+func TestValidateTemplates(t *testing.T) {
+	assert.NoError(t, ValidateTemplates("/accounts/$accountId"))
+	assert.NoError(t, ValidateTemplates("${formatTime(addDays(now(), -90), 'ISODate')}"))
+	assert.EqualError(t, ValidateTemplates("${nextDayDateTime()}"), `template "${nextDayDateTime()}", column 3: function nextDayDateTime takes 1 parameters, 0 given`)
+}
+
+func TestReplaceContextFieldExpandsTemplates(t *testing.T) {
+	ctx := templateContext()
+	ctx.PutString("accountId", "500000000000000000000001")
+
+	result, err := replaceContextField("/accounts/$accountId/statements?from=${formatTime($fromDate, 'ISODate')}", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "/accounts/500000000000000000000001/statements?from=2020-03-01", result)
+
+	// values not yet in context are left during generation, errors when the test runs
+	result, err = replaceContextField("${$missing}", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "${$missing}", result)
+
+	ctx.PutString("phase", "run")
+	_, err = replaceContextField("${$missing}", ctx)
+	assert.EqualError(t, err, `template "${$missing}", column 3: $missing not found in context`)
+}
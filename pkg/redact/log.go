@@ -0,0 +1,79 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter redacts the message and fields of the log entries it formats with the formatter it wraps, unless
+// unsafe debug is enabled
+type Formatter struct {
+	logrus.Formatter
+}
+
+// NewFormatter returns a formatter redacting the entries formatted by formatter
+func NewFormatter(formatter logrus.Formatter) *Formatter {
+	return &Formatter{Formatter: formatter}
+}
+
+// Format formats a redacted copy of the entry, the entry is not modified as it may be logged again
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if UnsafeDebug() {
+		return f.Formatter.Format(entry)
+	}
+	redacted := *entry
+	redacted.Message = String(entry.Message)
+	redacted.Data = Fields(entry.Data)
+	return f.Formatter.Format(&redacted)
+}
+
+// Fields returns a copy of the fields, with the values of the sensitive keys and of the authorisation `code`
+// redacted and the secrets found in the other values. Values other than strings, errors and numbers are rendered as JSON when secrets are found.
+func Fields(fields logrus.Fields) logrus.Fields {
+	redacted := make(logrus.Fields, len(fields))
+	for k, v := range fields {
+		if IsSensitiveParameter(k) {
+			redacted[k] = Value
+			continue
+		}
+		switch value := v.(type) {
+		case nil, bool, int, int64, float64:
+			redacted[k] = value
+		case string:
+			redacted[k] = String(value)
+		case error:
+			redacted[k] = String(value.Error())
+		default:
+			rendered := fmt.Sprintf("%+v", value)
+			if raw, err := json.Marshal(value); err == nil {
+				rendered = string(raw)
+			}
+			if cleared := String(rendered); cleared != rendered {
+				redacted[k] = cleared
+			} else {
+				redacted[k] = value
+			}
+		}
+	}
+	return redacted
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer redacting what is written to w, unless unsafe debug is enabled. Each write is
+// redacted on its own, secrets split across writes are not found.
+func NewWriter(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, Log(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
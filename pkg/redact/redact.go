@@ -0,0 +1,207 @@
+// Package redact classifies tokens, credentials and private keys, and replaces their values wherever the suite
+// writes them out: logs, traces, exported reports, websocket events and captured HTTP exchanges.
+//
+// Redaction of the logs and traces, which only the person running the suite sees, can be turned off with
+// EnableUnsafeDebug. What leaves the suite, reports, events and exchange archives, is always redacted.
+package redact
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Value replaces the values redacted
+const Value = "[redacted]"
+
+var (
+	lock        sync.RWMutex
+	unsafeDebug bool
+	signatures  bool
+)
+
+// EnableUnsafeDebug stops the redaction of logs and traces, secrets are then written in clear
+func EnableUnsafeDebug(enable bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	unsafeDebug = enable
+}
+
+// UnsafeDebug returns true when logs and traces are not redacted
+func UnsafeDebug() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return unsafeDebug
+}
+
+// RedactSignatures sets whether `x-jws-signature` headers and detached JWS are redacted, they are not by default
+// as they are not secrets
+func RedactSignatures(enable bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	signatures = enable
+}
+
+func redactsSignatures() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return signatures
+}
+
+// sensitiveKeys are the keys always sensitive, compared with case, `_` and `-` ignored
+var sensitiveKeys = []string{
+	"accesstoken", "refreshtoken", "idtoken", "clientsecret", "clientassertion", "password",
+	"basicauthentication", "signingpublic", "signingprivate", "transportprivate", "privatekey",
+}
+
+// sensitiveKeySuffixes are the endings of sensitive keys, e.g. `payment_ccg_token` or `transport_private`
+var sensitiveKeySuffixes = []string{"token", "secret", "private", "privatekey", "password"}
+
+// tokenStateRegex are the states of the consents, the context keys of the access tokens acquired, e.g. `Token001`
+var tokenStateRegex = regexp.MustCompile(`^token\d+$`)
+
+// sensitiveHeaders are the headers carrying credentials
+var sensitiveHeaders = []string{"authorization", "proxy-authorization", "cookie", "set-cookie"}
+
+// IsSensitiveKey returns true when key names a token, a credential or a private key: a context key, a JSON field,
+// a form or query parameter, a JWT claim or a log field
+func IsSensitiveKey(key string) bool {
+	normalised := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	if normalised == "" {
+		return false
+	}
+	for _, sensitive := range sensitiveKeys {
+		if normalised == sensitive {
+			return true
+		}
+	}
+	for _, suffix := range sensitiveKeySuffixes {
+		if strings.HasSuffix(normalised, suffix) {
+			return true
+		}
+	}
+	return tokenStateRegex.MatchString(normalised)
+}
+
+// IsSensitiveParameter returns true when the form or query parameter, or the log field, is sensitive: a sensitive
+// key or an authorisation `code`, which is only sensitive as a parameter as error responses have a `Code` field
+func IsSensitiveParameter(name string) bool {
+	return name == "code" || IsSensitiveKey(name)
+}
+
+// IsSensitiveHeader returns true when the header carries credentials, or a JWS signature when they are redacted
+func IsSensitiveHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, header := range sensitiveHeaders {
+		if lower == header {
+			return true
+		}
+	}
+	if lower == "x-jws-signature" {
+		return redactsSignatures()
+	}
+	return IsSensitiveKey(name)
+}
+
+// Header returns a copy of the headers, the values of those sensitive redacted
+func Header(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		if IsSensitiveHeader(name) {
+			values = []string{Value}
+		}
+		redacted[name] = append([]string{}, values...)
+	}
+	return redacted
+}
+
+var (
+	privateKeyRegex    = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+	credentialsRegex   = regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtRegex           = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	detachedJWSRegex   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.\.[A-Za-z0-9_-]+`)
+	jsonFieldRegex     = regexp.MustCompile(`(\\*)"([\w.\-]+)\\*"\s*:\s*\\*"`)
+	parameterRegex     = regexp.MustCompile(`(^|[?&\s])([\w.\-]+)=([^&\s"]+)`)
+	headerLineRegex    = regexp.MustCompile(`(?im)^([\w\-]+):[ \t]*(.+)$`)
+	sensitiveHintRegex = regexp.MustCompile(`(?i)token|secret|private|password|assertion|authori[sz]ation|cookie|bearer|basic|eyJ|signature|code=`)
+)
+
+// String redacts the secrets found in text: private keys, credentials of `Bearer` and `Basic` authorisations,
+// JWTs, the values of sensitive JSON fields, which may be escaped, of sensitive parameters and of sensitive
+// headers, one per line
+func String(text string) string {
+	if !sensitiveHintRegex.MatchString(text) {
+		return text
+	}
+	text = privateKeyRegex.ReplaceAllString(text, Value)
+	text = credentialsRegex.ReplaceAllString(text, "$1 "+Value)
+	text = jwtRegex.ReplaceAllString(text, Value)
+	if redactsSignatures() {
+		text = detachedJWSRegex.ReplaceAllString(text, Value)
+	}
+	text = redactJSONFields(text)
+	text = parameterRegex.ReplaceAllStringFunc(text, func(parameter string) string {
+		match := parameterRegex.FindStringSubmatch(parameter)
+		if !IsSensitiveParameter(match[2]) {
+			return parameter
+		}
+		return match[1] + match[2] + "=" + Value
+	})
+	return headerLineRegex.ReplaceAllStringFunc(text, func(line string) string {
+		match := headerLineRegex.FindStringSubmatch(line)
+		if !IsSensitiveHeader(match[1]) || match[2] == Value {
+			return line
+		}
+		return match[1] + ": " + Value
+	})
+}
+
+// redactJSONFields replaces the string values of sensitive JSON fields, including those of JSON escaped in a
+// JSON string, e.g. the response of an endpoint in an error message
+func redactJSONFields(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range jsonFieldRegex.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] < last || !IsSensitiveKey(text[match[4]:match[5]]) {
+			continue
+		}
+		escapes := match[3] - match[2]
+		end := jsonStringEnd(text, match[1], escapes)
+		if end < 0 {
+			continue
+		}
+		b.WriteString(text[last:match[1]])
+		b.WriteString(Value)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// jsonStringEnd returns the index of the quote closing the JSON string starting at start, preceded by escapes
+// backslashes when the JSON is escaped, or -1 when the string is not closed
+func jsonStringEnd(text string, start, escapes int) int {
+	for i := start; i < len(text); i++ {
+		if text[i] != '\\' && text[i] != '"' {
+			continue
+		}
+		backslashes := 0
+		for i+backslashes < len(text) && text[i+backslashes] == '\\' {
+			backslashes++
+		}
+		if i+backslashes < len(text) && text[i+backslashes] == '"' && backslashes == escapes {
+			return i
+		}
+		i += backslashes
+	}
+	return -1
+}
+
+// Log redacts text written to the logs or traces, unless unsafe debug is enabled
+func Log(text string) string {
+	if UnsafeDebug() {
+		return text
+	}
+	return String(text)
+}
@@ -22,6 +22,7 @@ const (
 	reportFilename         = "report.json"
 	discoveryFilename      = "discovery.json"
 	responseFieldsFilename = "responseFields.json"
+	exchangesFilename      = "exchanges.har"
 )
 
 var (
@@ -84,6 +85,14 @@ func (e *zipExporter) Export() error {
 	toExport[responseFieldsFilename] = []byte(e.report.ResponseFields)
 	toExport["report.checksum"] = createChecksum(exportSecret, reportJSON)
 
+	if len(e.report.Exchanges.Log.Entries) > 0 {
+		exchangesJSON, err := json.MarshalIndent(e.report.Exchanges, marshalIndentPrefix, marshalIndent)
+		if err != nil {
+			return fmt.Errorf("%w: json.MarshalIndent failed: %s, exchanges", ErrExportFailure, err.Error())
+		}
+		toExport[exchangesFilename] = exchangesJSON
+	}
+
 	return writeFiles(zipWriter, toExport)
 }
 
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +23,37 @@ func TestNewZipExporter(t *testing.T) {
 	require.NotNil(NewZipExporter(report, writer))
 }
 
+func TestZipExporterExportsExchanges(t *testing.T) {
+	require := test.NewRequire(t)
+
+	report := Report{Status: StatusComplete, CertifiedBy: CertifiedBy{Environment: CertifiedByEnvironmentTesting}}
+	files := func() map[string][]byte {
+		buff := bytes.NewBuffer([]byte{})
+		require.NoError(NewZipExporter(report, buff).Export())
+		zipReader, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+		require.NoError(err)
+		files := map[string][]byte{}
+		for _, file := range zipReader.File {
+			reader, err := file.Open()
+			require.NoError(err)
+			files[file.Name], err = ioutil.ReadAll(reader)
+			require.NoError(err)
+			require.NoError(reader.Close())
+		}
+		return files
+	}
+
+	require.NotContains(files(), exchangesFilename, "no archive when no exchange was recorded")
+
+	report.Exchanges = results.NewHAR([]results.HAREntry{{TestCaseID: "OB-301-ACC-120100"}})
+	exported := files()
+	require.Contains(exported, exchangesFilename)
+	archive := results.HAR{}
+	require.NoError(json.Unmarshal(exported[exchangesFilename], &archive))
+	require.Equal(report.Exchanges, archive)
+	require.NotContains(string(exported[reportFilename]), "OB-301-ACC-120100")
+}
+
 func Test_zipExporter_Export(t *testing.T) {
 	t.Skip()
 	tempDir, err := ioutil.TempDir("", "Test_zipExporter_Export")
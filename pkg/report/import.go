@@ -8,6 +8,8 @@ import (
 	"io"
 
 	"github.com/pkg/errors"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 )
 
 // Importer - allows the importing of a `Report`.
@@ -74,6 +76,10 @@ func (i *zipImporter) Import() (Report, error) {
 		if err := readerCloser.Close(); err != nil {
 			return Report{}, errors.Wrapf(err, "zipImporter.Import: file.Close failed, could not close %q", reportFilename)
 		}
+		// Reports exported before results had a status have no totals, their results' statuses are derived from `pass`.
+		if report.Totals == nil {
+			report.Totals = results.CountByStatus(report.ResultsGrouped())
+		}
 
 		return report, nil
 	}
@@ -1,11 +1,14 @@
 package report
 
 import (
+	"archive/zip"
+	"bytes"
 	"io"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 	"github.com/stretchr/testify/require"
 )
@@ -44,7 +47,7 @@ func Test_zipImporter_Import(t *testing.T) {
 			fields: fields{
 				reader: reportValid,
 			},
-			want:    Report{},
+			want:    Report{Totals: results.CountByStatus(nil)},
 			wantErr: "",
 		},
 		// invalid cases
@@ -80,3 +83,32 @@ func Test_zipImporter_Import(t *testing.T) {
 		}
 	})
 }
+
+func Test_zipImporter_Import_ResultsWithoutStatus(t *testing.T) {
+	require := test.NewRequire(t)
+
+	archive := &bytes.Buffer{}
+	writer := zip.NewWriter(archive)
+	file, err := writer.Create(reportFilename)
+	require.NoError(err)
+	_, err = file.Write([]byte(`{
+		"fails": 1,
+		"apiSpecification": [{
+			"name": "Account and Transaction API Specification",
+			"version": "v3.1",
+			"results": [{"id": "OB-301-ACC-100000", "pass": true}, {"id": "OB-301-ACC-100100", "pass": false}]
+		}]
+	}`))
+	require.NoError(err)
+	require.NoError(writer.Close())
+
+	report, err := NewZipImporter(archive).Import()
+	require.NoError(err)
+
+	specResults := report.APISpecification[0].Results
+	require.Equal(results.StatusPass, specResults[0].Status)
+	require.Equal(results.StatusFail, specResults[1].Status)
+	require.Equal(1, report.Fails)
+	require.Equal(1, report.Totals[results.StatusPass])
+	require.Equal(1, report.Totals[results.StatusFail])
+}
@@ -24,22 +24,26 @@ const (
 
 // Report - The Report.
 type Report struct {
-	ID               string             `json:"id"`                       // A unique and immutable identifier used to identify the report. The v4 UUIDs generated conform to RFC 4122.
-	Created          string             `json:"created"`                  // Date and time when the report was created, formatted accorrding to RFC3339 (https://tools.ietf.org/html/rfc3339). Note RFC3339 is derived from ISO 8601 (https://en.wikipedia.org/wiki/ISO_8601).
-	Expiration       *string            `json:"expiration,omitempty"`     // Date and time when the report should not longer be accepted, formatted accorrding to RFC3339 (https://tools.ietf.org/html/rfc3339). Note RFC3339 is derived from ISO 8601 (https://en.wikipedia.org/wiki/ISO_8601).
-	Fails            int                `json:"fails"`                    // Calculates *total* failures across the whole report, accumulated for each specification.
-	Version          string             `json:"version"`                  // The current version of the report model used.
-	Status           Status             `json:"status"`                   // A status describing overall condition of the report.
-	CertifiedBy      CertifiedBy        `json:"certifiedBy"`              // The certifier of the report.
-	APIVersions      APIVersionList     `json:"apiVersions"`              // List with the version & name of the tested APIs
-	SignatureChain   *[]SignatureChain  `json:"signatureChain,omitempty"` // When Add digital signature is set this contains the signature chain.
-	Discovery        discovery.Model    `json:"-"`                        // Original used discovery model
-	ResponseFields   string             `json:"-"`                        // ResponseFields - already in JSON format
-	APISpecification []APISpecification `json:"apiSpecification"`         // API and version tested, along with test cases
-	FCSVersion       string             `json:"fcsVersion"`               // Version of FCS running the tests
-	Products         []string           `json:"products"`                 // Products tested, e.g., "Business, Personal, Cards"
-	JWSStatus        string             `json:"jwsStatus"`                // Signature status
-	AgreedTC         bool               `json:"agreedTermsConditions"`    // Implementer acknowledged and agreed to T&C as displayed on the UI
+	ID               string                 `json:"id"`                       // A unique and immutable identifier used to identify the report. The v4 UUIDs generated conform to RFC 4122.
+	Created          string                 `json:"created"`                  // Date and time when the report was created, formatted accorrding to RFC3339 (https://tools.ietf.org/html/rfc3339). Note RFC3339 is derived from ISO 8601 (https://en.wikipedia.org/wiki/ISO_8601).
+	Expiration       *string                `json:"expiration,omitempty"`     // Date and time when the report should not longer be accepted, formatted accorrding to RFC3339 (https://tools.ietf.org/html/rfc3339). Note RFC3339 is derived from ISO 8601 (https://en.wikipedia.org/wiki/ISO_8601).
+	Fails            int                    `json:"fails"`                    // Calculates *total* failures across the whole report, accumulated for each specification.
+	Warnings         int                    `json:"warnings"`                 // Number of tests passed with advisory asserts not met, they are not counted in Fails.
+	Totals           map[results.Status]int `json:"totals"`                   // Number of tests of each status, failures being split from errors, skipped and warning tests.
+	Version          string                 `json:"version"`                  // The current version of the report model used.
+	Status           Status                 `json:"status"`                   // A status describing overall condition of the report.
+	CertifiedBy      CertifiedBy            `json:"certifiedBy"`              // The certifier of the report.
+	APIVersions      APIVersionList         `json:"apiVersions"`              // List with the version & name of the tested APIs
+	SignatureChain   *[]SignatureChain      `json:"signatureChain,omitempty"` // When Add digital signature is set this contains the signature chain.
+	Discovery        discovery.Model        `json:"-"`                        // Original used discovery model
+	ResponseFields   string                 `json:"-"`                        // ResponseFields - already in JSON format
+	APISpecification []APISpecification     `json:"apiSpecification"`         // API and version tested, along with test cases
+	Performance      Performance            `json:"performance"`              // Response times of the endpoints and performance budgets
+	FCSVersion       string                 `json:"fcsVersion"`               // Version of FCS running the tests
+	Products         []string               `json:"products"`                 // Products tested, e.g., "Business, Personal, Cards"
+	JWSStatus        string                 `json:"jwsStatus"`                // Signature status
+	AgreedTC         bool                   `json:"agreedTermsConditions"`    // Implementer acknowledged and agreed to T&C as displayed on the UI
+	Exchanges        results.HAR            `json:"-"`                        // Requests and responses recorded by the tests, exported as an HTTP Archive
 }
 
 // APIVersionList is a sortable collection of API name and version pairs
@@ -55,11 +59,12 @@ func (avl APIVersionList) Len() int           { return len(avl) }
 func (avl APIVersionList) Swap(i, j int)      { avl[i], avl[j] = avl[j], avl[i] }
 
 type APISpecification struct {
-	Name            string             `json:"name"`
-	TLSVersion      string             `json:"tls_version"`
-	TLSVersionValid bool               `json:"tls_version_valid"`
-	Version         string             `json:"version"`
-	Results         []results.TestCase `json:"results"`
+	Name            string                       `json:"name"`
+	TLSVersion      string                       `json:"tls_version"`
+	TLSVersionValid bool                         `json:"tls_version_valid"`
+	TLSPosture      []discovery.TLSPostureResult `json:"tls_posture,omitempty"` // TLS posture of the resource, token and authorization endpoints
+	Version         string                       `json:"version"`
+	Results         []results.TestCase           `json:"results"`
 }
 
 func (r *Report) requiresTCAgreement() bool {
@@ -110,9 +115,13 @@ func NewReport(exportResults models.ExportResults, environment string) (Report,
 	signatureChain := []SignatureChain{}
 
 	fails := GetFails(exportResults.Results)
+	performance, err := NewPerformance(exportResults.Results, exportResults.PerformanceBudgets)
+	if err != nil {
+		return Report{}, err
+	}
 	apiSpecs := []APISpecification{}
 	apiVersions := make(APIVersionList, 0, len(exportResults.Results))
-	for k, results := range exportResults.Results {
+	for k, results := range results.Redacted(exportResults.Results) {
 		tlsVersionResult := exportResults.TLSVersionResult[strings.ReplaceAll(k.APIName, " ", "-")]
 		if tlsVersionResult == nil {
 			tlsVersionResult = &discovery.TLSValidationResult{Valid: false, TLSVersion: "unknown"}
@@ -129,6 +138,7 @@ func NewReport(exportResults models.ExportResults, environment string) (Report,
 			Results:         results,
 			TLSVersion:      tlsVersionResult.TLSVersion,
 			TLSVersionValid: tlsVersionResult.Valid,
+			TLSPosture:      tlsVersionResult.Posture,
 		}
 		apiSpecs = append(apiSpecs, apiSpec)
 	}
@@ -139,6 +149,8 @@ func NewReport(exportResults models.ExportResults, environment string) (Report,
 		Created:          created,
 		Expiration:       &expiration,
 		Fails:            fails,
+		Warnings:         GetWarnings(exportResults.Results),
+		Totals:           results.CountByStatus(exportResults.Results),
 		Version:          Version,
 		Status:           StatusComplete,
 		CertifiedBy:      certifiedBy,
@@ -147,10 +159,12 @@ func NewReport(exportResults models.ExportResults, environment string) (Report,
 		Discovery:        exportResults.DiscoveryModel,
 		ResponseFields:   exportResults.ResponseFields,
 		APISpecification: apiSpecs,
+		Performance:      performance,
 		FCSVersion:       version.FullVersion,
 		Products:         exportResults.ExportRequest.Products,
 		JWSStatus:        exportResults.JWSStatus,
 		AgreedTC:         exportResults.ExportRequest.HasAgreed,
+		Exchanges:        results.ExchangeArchive(exportResults.Results),
 	}, nil
 }
 
@@ -164,6 +178,7 @@ func (r *Report) manifestFilePaths() []string {
 }
 
 // GetFails - fails is the number of specification tests that failed, it is not the number of failed tests.
+// Any test whose status did not pass counts, errors and skipped tests included, the split per status is in `Totals`.
 func GetFails(specs map[results.ResultKey][]results.TestCase) int {
 	var fails int
 	for _, results := range specs {
@@ -175,3 +190,22 @@ func GetFails(specs map[results.ResultKey][]results.TestCase) int {
 	}
 	return fails
 }
+
+// GetWarnings - number of tests which passed with advisory asserts not met.
+func GetWarnings(specs map[results.ResultKey][]results.TestCase) int {
+	return results.CountByStatus(specs)[results.StatusWarning]
+}
+
+// ResultsGrouped - results of the report grouped by API specification, as accumulated during a run.
+func (r Report) ResultsGrouped() map[results.ResultKey][]results.TestCase {
+	grouped := make(map[results.ResultKey][]results.TestCase, len(r.APISpecification))
+	for _, spec := range r.APISpecification {
+		key := results.ResultKey{APIName: spec.Name, APIVersion: spec.Version}
+		for _, result := range spec.Results {
+			result.API = spec.Name
+			result.APIVersion = spec.Version
+			grouped[key] = append(grouped[key], result)
+		}
+	}
+	return grouped
+}
@@ -0,0 +1,27 @@
+package report
+
+import (
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+)
+
+// Performance - response times of the run, reported alongside conformance. Budgets not met do not fail the report.
+type Performance struct {
+	Latencies []results.Latency      `json:"latencies"`         // Min/avg/p50/p95/max response time of each endpoint, in milliseconds
+	Budgets   []results.BudgetResult `json:"budgets,omitempty"` // Performance budgets configured, evaluated across the run
+	Throttled float64                `json:"throttled"`         // Time the requests waited for the rate limits of their host, in milliseconds
+}
+
+// NewPerformance - computes the latency of each endpoint and evaluates the budgets against the results of the run.
+func NewPerformance(specs map[results.ResultKey][]results.TestCase, budgets []results.PerformanceBudget) (Performance, error) {
+	evaluated, err := results.EvaluateBudgets(budgets, specs)
+	if err != nil {
+		return Performance{}, err
+	}
+	return Performance{
+		Latencies: results.Latencies(specs),
+		Budgets:   evaluated,
+		Throttled: float64(results.TotalThrottled(specs)) / float64(time.Millisecond),
+	}, nil
+}
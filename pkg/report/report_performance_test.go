@@ -0,0 +1,44 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+)
+
+func TestNewReportPerformance(t *testing.T) {
+	require := test.NewRequire(t)
+
+	exportResults := stubExportResults()
+	exportResults.Results = map[results.ResultKey][]results.TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: {
+			{Id: "1", Endpoint: "https://rs.aspsp.example.com/accounts", Resource: "Account", Metrics: results.Metrics{ResponseTime: 200 * time.Millisecond}},
+			{Id: "2", Endpoint: "https://rs.aspsp.example.com/accounts?page=2", Resource: "Account", Metrics: results.Metrics{ResponseTime: 1200 * time.Millisecond, Throttled: 300 * time.Millisecond}},
+			{Id: "3", Endpoint: "https://rs.aspsp.example.com/balances", Resource: "Balance"},
+		},
+	}
+	exportResults.PerformanceBudgets = []results.PerformanceBudget{{Resource: "Account", Max: "1s"}}
+
+	report, err := NewReport(exportResults, "Testing")
+	require.NoError(err)
+
+	require.Equal([]results.Latency{
+		{Endpoint: "/accounts", Count: 2, Min: 200, Avg: 700, P50: 200, P95: 1200, Max: 1200},
+	}, report.Performance.Latencies)
+	require.Equal([]results.BudgetResult{
+		{Budget: exportResults.PerformanceBudgets[0], Count: 2, Actual: 1200, Met: false},
+	}, report.Performance.Budgets)
+	require.Equal(300.0, report.Performance.Throttled)
+}
+
+func TestNewReportPerformanceInvalidBudget(t *testing.T) {
+	require := test.NewRequire(t)
+
+	exportResults := stubExportResults()
+	exportResults.PerformanceBudgets = []results.PerformanceBudget{{Max: "fast"}}
+
+	_, err := NewReport(exportResults, "Testing")
+	require.EqualError(err, `performance budget max "fast": time: invalid duration "fast"`)
+}
@@ -317,6 +317,40 @@ func TestReport_GetFails_Fails_Four(t *testing.T) {
 	require.Equal(expected, actual)
 }
 
+func TestReport_GetFails_CountsErrorsAndSkipped(t *testing.T) {
+	require := test.NewRequire(t)
+
+	specs := stubResults(true, true, true)
+	spec1 := results.ResultKey{
+		APIVersion: "APIVersion1",
+		APIName:    "APIName1",
+	}
+	spec2 := results.ResultKey{
+		APIVersion: "APIVersion2",
+		APIName:    "APIName2",
+	}
+	specs[spec1][0] = results.NewTestCaseSkipped("1.1", "1.0", "endpoint", "api-name", "api-version", "skipped", "https://openbanking.org.uk/ref/uri")
+	specs[spec2][1] = results.NewTestCaseError("2.2", results.NoMetrics(), nil, "endpoint", "api-name", "api-version", "detailed description", "https://openbanking.org.uk/ref/uri", "")
+
+	require.Equal(2, GetFails(specs))
+	require.Equal(1, results.CountByStatus(specs)[results.StatusSkipped])
+	require.Equal(1, results.CountByStatus(specs)[results.StatusError])
+}
+
+func TestReport_GetWarnings(t *testing.T) {
+	require := test.NewRequire(t)
+
+	specs := stubResults(false, true, true)
+	spec2 := results.ResultKey{
+		APIVersion: "APIVersion2",
+		APIName:    "APIName2",
+	}
+	specs[spec2][0].Status = results.StatusWarning
+
+	require.Equal(1, GetWarnings(specs))
+	require.Equal(1, GetFails(specs))
+}
+
 func TestNewReport(t *testing.T) {
 	t.Parallel()
 	// TODO: add test cases once functionality is read. Intentionally skipping test for now.
@@ -353,6 +387,82 @@ func TestNewReport(t *testing.T) {
 	}
 }
 
+func TestNewReportCarriesTLSPosture(t *testing.T) {
+	require := test.NewRequire(t)
+
+	exportResults := stubExportResults()
+	exportResults.Results = stubResults(true, true, true)
+	posture := []discovery.TLSPostureResult{
+		{Endpoint: discovery.TLSEndpointResource, URI: "https://rs.aspsp.example.com", Valid: true, Versions: []string{"TLS12"}},
+		{Endpoint: discovery.TLSEndpointToken, URI: "https://as.aspsp.example.com/token", Errors: []string{"accepts connections without a client certificate"}},
+	}
+	exportResults.TLSVersionResult = map[string]*discovery.TLSValidationResult{
+		"APIName1": {Valid: true, TLSVersion: "TLS12", Posture: posture},
+	}
+
+	report, err := NewReport(exportResults, "Testing")
+	require.NoError(err)
+
+	for _, apiSpec := range report.APISpecification {
+		if apiSpec.Name == "APIName1" {
+			require.Equal("TLS12", apiSpec.TLSVersion)
+			require.Equal(posture, apiSpec.TLSPosture)
+		} else {
+			require.Empty(apiSpec.TLSPosture)
+		}
+	}
+}
+
+func TestNewReportArchivesExchanges(t *testing.T) {
+	require := test.NewRequire(t)
+
+	exportResults := stubExportResults()
+	exportResults.Results = map[results.ResultKey][]results.TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: {{Id: "OB-301-ACC-120100", Exchanges: []results.HAREntry{{TestCaseID: "OB-301-ACC-120100"}}}},
+	}
+
+	report, err := NewReport(exportResults, "Testing")
+	require.NoError(err)
+
+	require.Equal(results.ExchangeArchive(exportResults.Results), report.Exchanges)
+	require.Len(report.Exchanges.Log.Entries, 1)
+}
+
+func TestNewReportRedactsResults(t *testing.T) {
+	require := test.NewRequire(t)
+
+	exportResults := stubExportResults()
+	key := results.ResultKey{APIName: "Accounts", APIVersion: "v3.1"}
+	exportResults.Results = map[results.ResultKey][]results.TestCase{
+		key: {{Id: "OB-301-ACC-120100", Status: results.StatusFail, Fail: []string{"called with Bearer 2f6c5b1e"}}},
+	}
+
+	report, err := NewReport(exportResults, "Testing")
+	require.NoError(err)
+
+	require.Len(report.APISpecification, 1)
+	require.Equal([]string{"called with Bearer [redacted]"}, report.APISpecification[0].Results[0].Fail)
+	require.Equal([]string{"called with Bearer 2f6c5b1e"}, exportResults.Results[key][0].Fail)
+}
+
+func TestReportResultsGrouped(t *testing.T) {
+	require := test.NewRequire(t)
+
+	exportResults := stubExportResults()
+	exportResults.Results = stubResults(false, true, false)
+
+	report, err := NewReport(exportResults, "Testing")
+	require.NoError(err)
+
+	grouped := report.ResultsGrouped()
+	require.Len(grouped, 4)
+	spec1 := results.ResultKey{APIName: "APIName1", APIVersion: "APIVersion1"}
+	require.Len(grouped[spec1], 3)
+	require.Equal("APIName1", grouped[spec1][0].API)
+	require.Equal("APIVersion1", grouped[spec1][0].APIVersion)
+	require.Equal([]string{"1.1", "3.3"}, results.FailedIDs(grouped))
+}
+
 func stubResults(pass1, pass2, pass3 bool) map[results.ResultKey][]results.TestCase {
 	specs := map[results.ResultKey][]results.TestCase{}
 
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Operation is a method and path defined by a specification, e.g. GET /accounts/{AccountId}
+type Operation struct {
+	Method string
+	Path   string
+}
+
+// Operations - operations of a specification, sorted by path and method
+type Operations []Operation
+
+// SpecOperations lists the operations defined by the specification specName at version
+func SpecOperations(specName, version string) (Operations, error) {
+	shouldUseOpenApi3, err := ShouldUseOpenApi3(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "schema: parsing version number failed, version=%q", version)
+	}
+
+	ops := Operations{}
+	if shouldUseOpenApi3 {
+		filenamePattern := getSpecFilePathPattern(specName)
+		if filenamePattern == "" {
+			return nil, errors.New("cannot get operations for spec: " + specName)
+		}
+		filename := fmt.Sprintf(filenamePattern, version)
+		doc, err := loadSpecFromFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot Load OpenApi Spec from file %s, %s", filename, err)
+		}
+		for path, props := range doc.Paths {
+			for method := range getOas3Operations(props) {
+				ops = append(ops, Operation{Method: method, Path: path})
+			}
+		}
+	} else {
+		validator, err := NewSwaggerOBSpecValidator(specName, version)
+		if err != nil {
+			return nil, err
+		}
+		swagger, ok := validator.(validators)
+		if !ok {
+			return nil, fmt.Errorf("schema: unexpected validator for spec %s version %s", specName, version)
+		}
+		for path, props := range swagger.document.Spec().Paths.Paths {
+			props := props
+			for method := range getOperations(&props) {
+				ops = append(ops, Operation{Method: method, Path: path})
+			}
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	return ops, nil
+}
+
+// Find returns the operation matching method and path, in which any segment starting with `$`,
+// e.g. `/accounts/$accountId`, matches a path parameter
+func (o Operations) Find(method, path string) (Operation, bool) {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for _, op := range o {
+		if op.Method != strings.ToUpper(method) {
+			continue
+		}
+		opSegments := strings.Split(op.Path, "/")
+		if len(opSegments) != len(segments) {
+			continue
+		}
+		matches := true
+		for i, segment := range opSegments {
+			isParam := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+			if segment != segments[i] && !(isParam && strings.HasPrefix(segments[i], "$")) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecOperationsOpenAPI3(t *testing.T) {
+	ops, err := SpecOperations("Account and Transaction API Specification", "v3.1.10")
+	require.NoError(t, err)
+
+	op, found := ops.Find("get", "/accounts/$accountId/balances")
+	assert.True(t, found)
+	assert.Equal(t, Operation{Method: "GET", Path: "/accounts/{AccountId}/balances"}, op)
+
+	_, found = ops.Find("get", "/accounts/$accountId/foobar")
+	assert.False(t, found)
+	_, found = ops.Find("post", "/accounts")
+	assert.False(t, found)
+	_, found = ops.Find("get", "/accounts/1234/balances")
+	assert.False(t, found, "only placeholders match path parameters")
+}
+
+func TestSpecOperationsSwagger(t *testing.T) {
+	ops, err := SpecOperations("Payment Initiation API", "v3.1.6")
+	require.NoError(t, err)
+
+	_, found := ops.Find("POST", "/domestic-payment-consents")
+	assert.True(t, found)
+	_, found = ops.Find("GET", "/domestic-payment-consents/$consentId/funds-confirmation")
+	assert.True(t, found)
+}
+
+func TestSpecOperationsUnknownSpec(t *testing.T) {
+	_, err := SpecOperations("Unknown API", "v3.1.10")
+	assert.EqualError(t, err, "cannot get operations for spec: Unknown API")
+}
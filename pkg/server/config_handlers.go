@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
-	"gopkg.in/resty.v1"
+	"github.com/OpenBankingUK/conformance-suite/pkg/version"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/labstack/echo/v4"
@@ -89,6 +91,12 @@ type GlobalConfiguration struct {
 	AcrValuesSupported            []string                             `json:"acr_values_supported,omitempty"`
 	ConditionalProperties         []discovery.ConditionalAPIProperties `json:"conditional_properties,omitempty"`
 	CBPIIDebtorAccount            discovery.CBPIIDebtorAccount         `json:"cbpii_debtor_account"`
+	PerformanceBudgets            []results.PerformanceBudget          `json:"performance_budgets,omitempty"`
+	ExchangeCapture               results.ExchangeCapture              `json:"exchange_capture"`
+	Cassette                      executors.CassetteConfig             `json:"cassette"`
+	RetryPolicy                   executors.RetryPolicy                `json:"retry_policy"`
+	RateLimits                    []executors.RateLimit                `json:"rate_limits,omitempty"`
+	HTTPClient                    executors.ClientConfig               `json:"http_client"`
 	// Should be taken from the well-known endpoint:
 	Issuer string `json:"issuer" validate:"valid_url"`
 }
@@ -107,9 +115,29 @@ func (c GlobalConfiguration) Validate() error {
 		validation.Field(&c.RequestedExecutionDateTime, validation.By(futureDateTimeValidator)),
 		validation.Field(&c.PaymentFrequency, validation.Required),
 		validation.Field(&c.CBPIIDebtorAccount, validation.Required),
+		validation.Field(&c.PerformanceBudgets, validation.By(performanceBudgetsValidator)),
+		validation.Field(&c.ExchangeCapture),
+		validation.Field(&c.Cassette),
+		validation.Field(&c.RetryPolicy),
+		validation.Field(&c.RateLimits),
+		validation.Field(&c.HTTPClient),
 	)
 }
 
+func performanceBudgetsValidator(value interface{}) error {
+	budgets, ok := value.([]results.PerformanceBudget)
+	if !ok {
+		return nil
+	}
+	for _, budget := range budgets {
+		if err := budget.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func futureDateTimeValidator(value interface{}) error {
 	dateTimeStr, ok := value.(string)
 	if !ok {
@@ -189,9 +217,6 @@ func (h configHandlers) configGlobalPostHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 
-	// Use the transport keys for MATLS as some endpoints require this
-	resty.SetCertificates(journeyConfig.certificateTransport.TLSCert())
-
 	err = h.journey.SetConfig(journeyConfig)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
@@ -217,6 +242,23 @@ func MakeJourneyConfig(config *GlobalConfiguration) (JourneyConfig, error) {
 		return JourneyConfig{}, errors.Wrap(err, "error with transport certificate")
 	}
 
+	cassette, err := executors.NewCassette(config.Cassette)
+	if err != nil {
+		return JourneyConfig{}, errors.Wrap(err, "error with cassette")
+	}
+
+	rateLimiter, err := executors.NewRateLimiter(config.RateLimits)
+	if err != nil {
+		return JourneyConfig{}, errors.Wrap(err, "error with rate limits")
+	}
+
+	// Use the transport keys for MATLS as some endpoints require this
+	client, err := executors.NewClient(config.HTTPClient, certificateTransport)
+	if err != nil {
+		return JourneyConfig{}, errors.Wrap(err, "error with http client")
+	}
+	client.SetHeader("User-Agent", userAgent(certificateTransport))
+
 	return JourneyConfig{
 		certificateSigning:            certificateSigning,
 		certificateTransport:          certificateTransport,
@@ -249,10 +291,26 @@ func MakeJourneyConfig(config *GlobalConfiguration) (JourneyConfig, error) {
 		AcrValuesSupported:            config.AcrValuesSupported,
 		conditionalProperties:         config.ConditionalProperties,
 		cbpiiDebtorAccount:            config.CBPIIDebtorAccount,
+		performanceBudgets:            config.PerformanceBudgets,
+		exchangeCapture:               config.ExchangeCapture,
+		cassette:                      cassette,
+		retryPolicy:                   config.RetryPolicy,
+		rateLimiter:                   rateLimiter,
+		client:                        client,
 		issuer:                        config.Issuer, // TBD: available from well-known ?
 	}, nil
 }
 
+// userAgent returns the User-Agent of the requests, with the organisational unit and common name of the transport
+// certificate when known
+func userAgent(certificateTransport authentication.Certificate) string {
+	_, ou, cn, err := certificateTransport.DN()
+	if err == nil && cn != "" && ou != "" {
+		return "OpenBankingFCS/" + version.NewGitHub("").GetHumanVersion() + "/" + ou + "/" + cn
+	}
+	return "OpenBankingFCS/" + version.NewGitHub("").GetHumanVersion()
+}
+
 func validateConfig(config *GlobalConfiguration) (bool, string) {
 	rules := parseRules(config)
 	for _, rule := range rules {
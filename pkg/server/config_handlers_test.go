@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/sirupsen/logrus"
@@ -896,3 +897,8 @@ func testJourney() Journey {
 	testGenerator := generation.NewGenerator()
 	return NewJourney(logger, testGenerator, validatorEngine, discovery.NewNullTLSValidator(), false)
 }
+
+func TestPerformanceBudgetsValidator(t *testing.T) {
+	assert.NoError(t, performanceBudgetsValidator([]results.PerformanceBudget{{Endpoint: "/accounts$", Percentile: 95, Max: "1s"}}))
+	assert.EqualError(t, performanceBudgetsValidator([]results.PerformanceBudget{{Resource: "Account", Max: "1s"}, {Max: "0s"}}), `performance budget max "0s": must be positive`)
+}
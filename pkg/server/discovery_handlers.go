@@ -1,7 +1,10 @@
 package server
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/sets"
@@ -11,6 +14,7 @@ import (
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
 )
 
 const (
@@ -51,6 +55,18 @@ func (d discoveryHandlers) setDiscoveryModelHandler(c echo.Context) error {
 		"function": "setDiscoveryModelHandler",
 	})
 
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	c.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	// invalid JSON is reported by Bind
+	if json.Valid(body) {
+		if failures := schemaValidationFailures(body); !failures.Empty() {
+			return c.JSON(http.StatusBadRequest, validationFailuresResponse{failures})
+		}
+	}
 	discoveryModel := &discovery.Model{}
 	if err := c.Bind(discoveryModel); err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
@@ -126,3 +142,21 @@ func newOpenidConfigurationURIFailure(discoveryItemIndex int, err error) discove
 		Error: err.Error(),
 	}
 }
+
+// schemaValidationFailures validates the discovery model against its JSON Schema, each value not matching is
+// a failure keyed by its path
+func schemaValidationFailures(discoveryModel []byte) discovery.ValidationFailures {
+	failures := discovery.NoValidationFailures()
+	err := jsonschema.Validate(jsonschema.Discovery, discoveryModel)
+	if err == nil {
+		return failures
+	}
+	validationErrors, ok := err.(jsonschema.ValidationErrors)
+	if !ok {
+		return append(failures, discovery.ValidationFailure{Key: "DiscoveryModel", Error: err.Error()})
+	}
+	for _, e := range validationErrors {
+		failures = append(failures, discovery.ValidationFailure{Key: e.Path, Error: e.Error()})
+	}
+	return failures
+}
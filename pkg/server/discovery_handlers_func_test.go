@@ -58,3 +58,34 @@ func TestServerDiscoveryModelPOSTValidateReturnsErrorsWhenIncomplete(t *testing.
 	assert.Equal(http.StatusBadRequest, code)
 	assert.Equal(expectedJSONHeaders(), headers)
 }
+
+// /api/discovery-model - POST - When the model does not match the JSON Schema returns the path and line of the values
+func TestServerDiscoveryModelPOSTValidateReturnsSchemaFailures(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	server := NewServer(testJourney(), nullLogger(), &versionmock.Version{})
+	defer func() {
+		assert.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	discoveryModel := `{
+  "discoveryModel": {
+    "name": "ob-v3.1-ozone",
+    "discoveryItems": [
+      { "endpoints": [{ "method": "GET", "path": 42 }] }
+    ]
+  }
+}`
+	expected := `{ "error":
+					[
+						{"key": "discoveryModel.discoveryItems.0.endpoints.0.path", "error": "line 5: discoveryModel.discoveryItems.0.endpoints.0.path: Field must be set to string or not be present"}
+                    ]
+				}`
+
+	code, body, headers := request(http.MethodPost, "/api/discovery-model", strings.NewReader(discoveryModel), server)
+
+	assert.NotNil(body)
+	assert.JSONEq(expected, body.String())
+	assert.Equal(http.StatusBadRequest, code)
+	assert.Equal(expectedJSONHeaders(), headers)
+}
@@ -45,7 +45,7 @@ func (h exportHandlers) postExport(c echo.Context) error {
 
 	logger.WithField("request", request).Info("Exporting ...")
 
-	results := h.journey.Results().AllResultsGrouped()
+	results := h.journey.CombinedResults()
 	responseFields := h.journey.Results().ResponseFieldsJSON()
 	tokens := h.journey.Events().AllAcquiredAccessToken()
 	discovery, err := h.journey.DiscoveryModel()
@@ -63,6 +63,8 @@ func (h exportHandlers) postExport(c echo.Context) error {
 		TLSVersionResult: h.journey.TLSVersionResult(),
 		ResponseFields:   responseFields,
 		JWSStatus:        model.JWSStatus(),
+
+		PerformanceBudgets: h.journey.PerformanceBudgets(),
 	}
 
 	r, err := report.NewReport(exportResults, request.Environment)
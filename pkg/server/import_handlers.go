@@ -82,20 +82,10 @@ func (h importHandlers) doImport(request models.ImportRequest, logger *logrus.En
 	var discoveryModel discovery.Model
 	logger.WithField("len(request.Report)", len(request.Report)).Info("Importing ...")
 
-	// Decode the base64 string
-	// Split the string to get only the base64 part
-	parts := strings.SplitN(request.Report, ",", 2)
-	if len(parts) != 2 {
-		logger.Error("Invalid report format")
-		return discoveryModel, fmt.Errorf("invalid report format")
-	}
-	base64Data := parts[1]
-
-	// Decode the base64 string
-	reportBytes, err := base64.StdEncoding.DecodeString(base64Data)
+	reportBytes, err := decodeReportArchive(request.Report)
 	if err != nil {
-		logger.WithField("error", err).Error("Failed to decode base64 string")
-		return discoveryModel, fmt.Errorf("failed to decode report: %w", err)
+		logger.WithField("error", err).Error("Failed to decode report")
+		return discoveryModel, err
 	}
 
 	// Create a reader for the zip file
@@ -142,3 +132,18 @@ func (h importHandlers) doImport(request models.ImportRequest, logger *logrus.En
 
 	return discoveryModel, nil
 }
+
+// decodeReportArchive - decodes the exported report ZIP archive sent as a base64 data URL.
+func decodeReportArchive(report string) ([]byte, error) {
+	// Split the string to get only the base64 part
+	parts := strings.SplitN(report, ",", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid report format")
+	}
+
+	reportBytes, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	return reportBytes, nil
+}
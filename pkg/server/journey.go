@@ -9,14 +9,17 @@ import (
 	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors"
 	"github.com/OpenBankingUK/conformance-suite/pkg/executors/events"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
+	"github.com/OpenBankingUK/conformance-suite/pkg/redact"
 	"github.com/OpenBankingUK/conformance-suite/pkg/schemaprops"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 )
@@ -29,6 +32,7 @@ var (
 	errConsentIDAcquisitionFailed      = errors.New("ConsentId acquistion failed")
 	errDynamicResourceAllocationFailed = errors.New("Dynamic Resource allocation failed")
 	errNoTestCases                     = errors.New("No testcases were generated - please select a wider set of endpoints to test")
+	errNoFailedTestCases               = errors.New("error no failed test cases to re-run")
 )
 
 // Journey represents all possible steps for a user test conformance journey
@@ -40,22 +44,31 @@ var (
 // 3.1 CollectToken - collects all tokens required to RunTest
 // 4. RunTest - Runs triggers a background run on all generated test from previous steps, needs all token to be already collected
 // 5. Results - returns a background process control, so we can monitor on finished tests
+//
+// Once a run has finished, RerunFailures generates the test cases which failed again so they can be
+// collected tokens for and run, CombinedResults then merges their results with those of the previous run.
 type Journey interface {
 	SetDiscoveryModel(discoveryModel *discovery.Model) (discovery.ValidationFailures, error)
 	DiscoveryModel() (discovery.Model, error)
 	SetFilteredManifests(manifest.Scripts)
 	FilteredManifests() (manifest.Scripts, error)
+	SetTestSelection(selection manifest.Selection)
 	TestCases() (generation.SpecRun, error)
+	ExecutionPlan() (generation.ExecutionPlan, error)
+	PlanTestCases(selection manifest.Selection) (generation.ExecutionPlan, error)
+	RerunFailures(previous map[results.ResultKey][]results.TestCase) (generation.SpecRun, error)
 	CollectToken(code, state, scope string) error
 	AllTokenCollected() bool
 	RunTests() error
 	StopTestRun()
 	NewDaemonController()
 	Results() executors.DaemonController
+	CombinedResults() map[results.ResultKey][]results.TestCase
 	SetConfig(config JourneyConfig) error
 	ConditionalProperties() []discovery.ConditionalAPIProperties
 	Events() events.Events
 	TLSVersionResult() map[string]*discovery.TLSValidationResult
+	PerformanceBudgets() []results.PerformanceBudget
 }
 
 // AppJourney - application controlled by this class
@@ -69,13 +82,15 @@ type AppJourney struct {
 	collector             executors.TokenCollector
 	allCollected          bool
 	validDiscoveryModel   *discovery.Model
-	context               model.Context
+	context               *model.RunContext
 	log                   *logrus.Entry
 	config                JourneyConfig
 	events                events.Events
 	permissions           map[string][]manifest.RequiredTokens
 	manifests             []manifest.Scripts
 	filteredManifests     manifest.Scripts
+	selection             manifest.Selection
+	previousResults       map[results.ResultKey][]results.TestCase
 	tlsValidator          discovery.TLSValidator
 	conditionalProperties []discovery.ConditionalAPIProperties
 	dynamicResourceIDs    bool
@@ -92,7 +107,7 @@ func NewJourney(logger *logrus.Entry, generator generation.Generator,
 		journeyLock:           &sync.Mutex{},
 		allCollected:          false,
 		testCasesRunGenerated: false,
-		context:               model.Context{},
+		context:               model.NewRunContext(nil),
 		log:                   logger.WithField("module", "journey"),
 		events:                events.NewEvents(),
 		permissions:           make(map[string][]manifest.RequiredTokens),
@@ -189,7 +204,9 @@ func (wj *AppJourney) TLSVersionResult() map[string]*discovery.TLSValidationResu
 			}).Errorf("Error getting %s from context ...", tlsValidKey)
 			continue
 		}
-		tlsValidationResult[strings.ReplaceAll(discoveryItem.APISpecification.Name, " ", "-")] = &discovery.TLSValidationResult{TLSVersion: tlsVersion, Valid: tlsValid.(bool)}
+		posture, _ := wj.context.Get(wj.tlsPostureCtxKey(discoveryItem.APISpecification.Name))
+		tlsPosture, _ := posture.([]discovery.TLSPostureResult)
+		tlsValidationResult[strings.ReplaceAll(discoveryItem.APISpecification.Name, " ", "-")] = &discovery.TLSValidationResult{TLSVersion: tlsVersion, Valid: tlsValid.(bool), Posture: tlsPosture}
 	}
 
 	return tlsValidationResult
@@ -205,6 +222,91 @@ func (wj *AppJourney) FilteredManifests() (manifest.Scripts, error) {
 	return wj.filteredManifests, nil
 }
 
+// SetTestSelection narrows the test cases generated by TestCases, along with the consents they require
+func (wj *AppJourney) SetTestSelection(selection manifest.Selection) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	wj.selection = selection
+	wj.previousResults = nil
+}
+
+// RerunFailures generates the test cases which failed in previous, along with the test cases putting in context
+// the values they use, and acquires the consents they require only. previous is kept to be merged with the
+// results of the re-run, see CombinedResults.
+func (wj *AppJourney) RerunFailures(previous map[results.ResultKey][]results.TestCase) (generation.SpecRun, error) {
+	failedIDs := results.FailedIDs(previous)
+	if len(failedIDs) == 0 {
+		return generation.SpecRun{}, errNoFailedTestCases
+	}
+
+	wj.journeyLock.Lock()
+	wj.log.WithField("function", "RerunFailures").WithField("ids", failedIDs).Info("Re-running failed test cases")
+	wj.selection = manifest.SelectIDs(failedIDs)
+	wj.previousResults = previous
+	wj.testCasesRunGenerated = false
+	wj.allCollected = false
+	wj.journeyLock.Unlock()
+
+	return wj.TestCases()
+}
+
+// ExecutionPlan returns the graph of the test cases generated by TestCases, see generation.ExecutionPlan
+func (wj *AppJourney) ExecutionPlan() (generation.ExecutionPlan, error) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	if !wj.testCasesRunGenerated {
+		return generation.ExecutionPlan{}, errTestCasesNotGenerated
+	}
+	return generation.NewExecutionPlan(wj.specRun, wj.permissions), nil
+}
+
+// PlanTestCases returns the execution plan of the test cases the discovery model and selection generate, see
+// generation.ExecutionPlan. Unlike TestCases, the TLS posture is not checked, no consent is acquired and the
+// test cases generated for the run, if any, are kept.
+func (wj *AppJourney) PlanTestCases(selection manifest.Selection) (generation.ExecutionPlan, error) {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	if wj.validDiscoveryModel == nil {
+		return generation.ExecutionPlan{}, errDiscoveryModelNotSet
+	}
+
+	ctx := wj.context.Snapshot()
+	specRun, _, permissions := wj.generateTestCases(selection, &ctx, wj.log.WithField("function", "PlanTestCases"))
+	plan := generation.NewExecutionPlan(specRun, permissions)
+	if len(plan.Nodes) == 0 {
+		return generation.ExecutionPlan{}, errNoTestCases
+	}
+	return plan, nil
+}
+
+// generateTestCases generates the test cases of the discovery model and selection, putting the API versions of
+// the discovery items in ctx
+func (wj *AppJourney) generateTestCases(selection manifest.Selection, ctx *model.Context, logger *logrus.Entry) (generation.SpecRun, manifest.Scripts, map[string][]manifest.RequiredTokens) {
+	ctx.PutString(CtxPhase, "generation")
+	config := wj.makeGeneratorConfig()
+	config.Selection = selection
+	discovery := wj.validDiscoveryModel.DiscoveryModel
+	if len(discovery.DiscoveryItems) > 0 { // default currently "v3.1" ... allow "v3.0"
+		apiversions := DetermineAPIVersions(discovery.DiscoveryItems)
+		if len(apiversions) > 0 {
+			ctx.PutStringSlice("apiversions", apiversions)
+		}
+		// version string gets replaced in URLS like  "endpoint": "/open-banking/$api-version/aisp/account-access-consents",
+		version, err := semver.ParseTolerant(discovery.DiscoveryItems[0].APISpecification.Version)
+		if err != nil {
+			logger.WithError(err).Error("parsing spec version")
+		} else {
+			wj.config.apiVersion = fmt.Sprintf("v%d.%d", version.Major, version.Minor)
+			ctx.PutString(CtxAPIVersion, wj.config.apiVersion)
+		}
+		logger.WithField("version", wj.config.apiVersion).Info("API url version")
+	}
+
+	logger.Debug("generator.GenerateManifestTests ...")
+	logrus.Tracef("conditionalProperties from journey config: %#v", wj.config.conditionalProperties)
+	return wj.generator.GenerateManifestTests(wj.log, config, discovery, ctx, wj.config.conditionalProperties)
+}
+
 // TestCases -
 func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 	wj.journeyLock.Lock()
@@ -229,12 +331,37 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 
 	jwksURI := authentication.GetJWKSUri()
 	if jwksURI != "" { // STORE jwks_uri from well known endpoint in journey context
-		wj.context.PutString("jwks_uri", jwksURI)
+		wj.context.Put(model.ScopeGlobal, "jwks_uri", jwksURI)
 	} else {
 		logrus.Warn("JWKS URI is empty")
 	}
 
 	if tlsCheck {
+		// the token and authorization endpoints are shared by all the discovery items
+		endpointsPosture := []discovery.TLSPostureResult{}
+		for _, endpoint := range []struct {
+			kind         string
+			uri          string
+			mtlsRequired bool
+		}{
+			{discovery.TLSEndpointToken, wj.config.tokenEndpoint, true},
+			{discovery.TLSEndpointAuthorization, wj.config.authorizationEndpoint, false},
+		} {
+			if endpoint.uri == "" {
+				continue
+			}
+			posture, err := wj.tlsValidator.ValidateTLSPosture(endpoint.kind, endpoint.uri, endpoint.mtlsRequired)
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"err":      err,
+					"endpoint": endpoint.kind,
+					"uri":      endpoint.uri,
+				}).Error("Error validating TLS posture of endpoint")
+				posture.Errors = append(posture.Errors, err.Error())
+			}
+			endpointsPosture = append(endpointsPosture, posture)
+		}
+
 		for k, discoveryItem := range wj.validDiscoveryModel.DiscoveryModel.DiscoveryItems {
 			tlsValidationResult, err := wj.tlsValidator.ValidateTLSVersion(discoveryItem.ResourceBaseURI)
 			if err != nil {
@@ -244,35 +371,30 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 					"discoveryItem.ResourceBaseURI": discoveryItem.ResourceBaseURI,
 				}).Error("Error validating TLS version for discovery item ResourceBaseURI")
 			}
-			wj.context.PutString(wj.tlsVersionCtxKey(discoveryItem.APISpecification.Name), tlsValidationResult.TLSVersion)
-			wj.context.Put(wj.tlsValidCtxKey(discoveryItem.APISpecification.Name), tlsValidationResult.Valid)
+			wj.context.Put(model.ScopeGlobal, wj.tlsVersionCtxKey(discoveryItem.APISpecification.Name), tlsValidationResult.TLSVersion)
+			wj.context.Put(model.ScopeGlobal, wj.tlsValidCtxKey(discoveryItem.APISpecification.Name), tlsValidationResult.Valid)
+
+			resourcePosture, err := wj.tlsValidator.ValidateTLSPosture(discovery.TLSEndpointResource, discoveryItem.ResourceBaseURI, true)
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"err":                           err,
+					"discoveryItem[key]":            k,
+					"discoveryItem.ResourceBaseURI": discoveryItem.ResourceBaseURI,
+				}).Error("Error validating TLS posture for discovery item ResourceBaseURI")
+				resourcePosture.Errors = append(resourcePosture.Errors, err.Error())
+			}
+			posture := append([]discovery.TLSPostureResult{resourcePosture}, endpointsPosture...)
+			wj.context.Put(model.ScopeGlobal, wj.tlsPostureCtxKey(discoveryItem.APISpecification.Name), posture)
 		}
 	} else {
 		logrus.Warn("TLS Check disabled")
 	}
 
-	wj.context.PutString(CtxPhase, "generation")
-	config := wj.makeGeneratorConfig()
 	discovery := wj.validDiscoveryModel.DiscoveryModel
-	if len(discovery.DiscoveryItems) > 0 { // default currently "v3.1" ... allow "v3.0"
-		apiversions := DetermineAPIVersions(discovery.DiscoveryItems)
-		if len(apiversions) > 0 {
-			wj.context.PutStringSlice("apiversions", apiversions)
-		}
-		// version string gets replaced in URLS like  "endpoint": "/open-banking/$api-version/aisp/account-access-consents",
-		version, err := semver.ParseTolerant(discovery.DiscoveryItems[0].APISpecification.Version)
-		if err != nil {
-			logger.WithError(err).Error("parsing spec version")
-		} else {
-			wj.config.apiVersion = fmt.Sprintf("v%d.%d", version.Major, version.Minor)
-			wj.context.PutString(CtxAPIVersion, wj.config.apiVersion)
-		}
-		logger.WithField("version", wj.config.apiVersion).Info("API url version")
-	}
-
-	logger.Debug("generator.GenerateManifestTests ...")
-	logrus.Tracef("conditionalProperties from journey config: %#v", wj.config.conditionalProperties)
-	wj.specRun, wj.filteredManifests, wj.permissions = wj.generator.GenerateManifestTests(wj.log, config, discovery, &wj.context, wj.config.conditionalProperties)
+	_ = wj.context.Update(model.ScopeGlobal, func(ctx *model.Context) error {
+		wj.specRun, wj.filteredManifests, wj.permissions = wj.generateTestCases(wj.selection, ctx, logger)
+		return nil
+	})
 
 	tests := 0
 	for _, sp := range wj.specRun.SpecTestCases {
@@ -301,7 +423,7 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 		}).Debug("AcquirePSUTokens ...")
 		definition := wj.makeRunDefinition()
 
-		consentIds, tokenMap, err := executors.GetPsuConsent(definition, &wj.context, &wj.specRun, wj.permissions)
+		consentIds, tokenMap, err := executors.GetPsuConsent(definition, wj.context, &wj.specRun, wj.permissions)
 		if err != nil {
 			logger.WithFields(logrus.Fields{
 				"err": err,
@@ -309,12 +431,13 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 			return generation.SpecRun{}, errors.WithMessage(errConsentIDAcquisitionFailed, err.Error())
 		}
 
+		tokens := wj.context.Snapshot() // read by the token mapping
 		for k := range wj.permissions {
 			if k == "payments" {
 				paymentpermissions := wj.permissions["payments"]
 				if len(paymentpermissions) > 0 {
 					for _, spec := range wj.specRun.SpecTestCases {
-						manifest.MapTokensToPaymentTestCases(paymentpermissions, spec.TestCases, &wj.context)
+						manifest.MapTokensToPaymentTestCases(paymentpermissions, spec.TestCases, &tokens)
 					}
 				}
 			}
@@ -322,7 +445,7 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 				vrpspermissions := wj.permissions["vrps"]
 				if len(vrpspermissions) > 0 {
 					for _, spec := range wj.specRun.SpecTestCases {
-						manifest.MapTokensToPaymentTestCases(vrpspermissions, spec.TestCases, &wj.context)
+						manifest.MapTokensToPaymentTestCases(vrpspermissions, spec.TestCases, &tokens)
 					}
 				}
 			}
@@ -330,14 +453,14 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 				cbpiiPerms := wj.permissions["cbpii"]
 				if len(cbpiiPerms) > 0 {
 					for _, spec := range wj.specRun.SpecTestCases {
-						manifest.MapTokensToCBPIITestCases(cbpiiPerms, spec.TestCases, &wj.context)
+						manifest.MapTokensToCBPIITestCases(cbpiiPerms, spec.TestCases, &tokens)
 					}
 				}
 			}
 		}
 
 		for k, v := range tokenMap {
-			wj.context.PutString(k, v)
+			wj.context.Put(model.ScopeJourney, k, v)
 			logger.Tracef("processtokenMap %s:%s into context", k, v)
 		}
 
@@ -350,7 +473,11 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 		}).Debug("AcquireHeadlessTokens ...")
 		definition := wj.makeRunDefinition()
 
-		tokenPermissionsMap, err := executors.GetHeadlessConsent(definition, &wj.context, &wj.specRun, wj.permissions)
+		var tokenPermissionsMap []manifest.RequiredTokens
+		err := wj.context.Update(model.ScopeJourney, func(ctx *model.Context) (err error) {
+			tokenPermissionsMap, err = executors.GetHeadlessConsent(definition, ctx, &wj.specRun, wj.permissions)
+			return err
+		})
 		if err != nil {
 			logger.WithFields(logrus.Fields{
 				"err": err,
@@ -367,10 +494,11 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 		}
 
 		for k, v := range tokenMap {
-			wj.context.PutString(k, v)
+			wj.context.Put(model.ScopeJourney, k, v)
 			logger.Tracef("processtokenMap %s:%s into context", k, v)
 		}
 
+		tokens := wj.context.Snapshot() // read by the token mapping
 		for k := range wj.permissions {
 			if k == "payments" {
 				paymentpermissions := wj.permissions["payments"]
@@ -378,7 +506,7 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 				if len(paymentpermissions) > 0 {
 					for _, spec := range wj.specRun.SpecTestCases {
 						logger.Tracef("Analysing %d test cases for token mapping", len(spec.TestCases))
-						manifest.MapTokensToPaymentTestCases(paymentpermissions, spec.TestCases, &wj.context)
+						manifest.MapTokensToPaymentTestCases(paymentpermissions, spec.TestCases, &tokens)
 					}
 				}
 			}
@@ -386,7 +514,7 @@ func (wj *AppJourney) TestCases() (generation.SpecRun, error) {
 				cbpiiPerms := wj.permissions["cbpii"]
 				if len(cbpiiPerms) > 0 {
 					for _, spec := range wj.specRun.SpecTestCases {
-						manifest.MapTokensToCBPIITestCases(cbpiiPerms, spec.TestCases, &wj.context)
+						manifest.MapTokensToCBPIITestCases(cbpiiPerms, spec.TestCases, &tokens)
 					}
 				}
 			}
@@ -407,6 +535,10 @@ func (wj *AppJourney) tlsVersionCtxKey(discoveryItemName string) string {
 	return fmt.Sprintf("tlsVersionForDiscoveryItem-%s", strings.ReplaceAll(discoveryItemName, " ", "-"))
 }
 
+func (wj *AppJourney) tlsPostureCtxKey(discoveryItemName string) string {
+	return fmt.Sprintf("tlsPostureForDiscoveryItem-%s", strings.ReplaceAll(discoveryItemName, " ", "-"))
+}
+
 func (wj *AppJourney) tlsValidCtxKey(discoveryItemName string) string {
 	return fmt.Sprintf("tlsIsValidForDiscoveryItem-%s", strings.ReplaceAll(discoveryItemName, " ", "-"))
 }
@@ -424,39 +556,38 @@ func (wj *AppJourney) CollectToken(code, state, scope string) error {
 	if !wj.testCasesRunGenerated {
 		logger.WithFields(logrus.Fields{
 			"err":   errTestCasesNotGenerated,
-			"code":  code,
 			"state": state,
 			"scope": scope,
 		}).Error("Error collecting token")
 		return errTestCasesNotGenerated
 	}
 
-	accessToken, err := executors.ExchangeCodeForAccessToken(state, code, &wj.context)
+	definition := wj.makeRunDefinition()
+	var accessToken string
+	err := wj.context.Update(model.ScopeJourney, func(ctx *model.Context) (err error) {
+		accessToken, err = executors.ExchangeCodeForAccessToken(definition, state, code, ctx)
+		return err
+	})
+	if wj.config.cassette.Recording() {
+		if err := wj.config.cassette.Save(); err != nil {
+			logger.WithError(err).Error("saving cassette")
+		}
+	}
 	if err != nil {
 		logger.WithFields(logrus.Fields{
-			"err":         err,
-			"code":        code,
-			"state":       state,
-			"scope":       scope,
-			"accessToken": accessToken,
+			"err":   err,
+			"state": state,
+			"scope": scope,
 		}).Error("Error collecting token due to error in executors.ExchangeCodeForAccessToken")
 		return err
 	}
 
-	wj.context.PutString(state, accessToken)
-	if state == "Token001" {
-		logger.WithFields(logrus.Fields{
-			"err":         err,
-			"code":        code,
-			"state":       state,
-			"scope":       scope,
-			"accessToken": accessToken,
-		}).Warn(`Setting 'access_token' because state == "Token001"`)
-		wj.context.PutString("access_token", accessToken) // tmp measure to get testcases running
-	}
+	wj.context.Put(model.ScopeJourney, state, accessToken)
 
 	if wj.config.useDynamicResourceID {
-		err := executors.GetDynamicResourceIds(state, accessToken, &wj.context, wj.permissions["accounts"])
+		err := wj.context.Update(model.ScopeJourney, func(ctx *model.Context) error {
+			return executors.GetDynamicResourceIds(definition, state, accessToken, ctx, wj.permissions["accounts"])
+		})
 		if err != nil {
 			logger.WithFields(logrus.Fields{
 				"err": err,
@@ -525,8 +656,8 @@ func (wj *AppJourney) RunTests() error {
 			}
 		}
 		// put a default accountid and statement id in the journey context for those tests that haven't got a token that can call /accounts
-		wj.context.PutString(CtxConsentedAccountID, wj.config.resourceIDs.AccountIDs[0].AccountID)
-		wj.context.PutString(CtxStatementID, wj.config.resourceIDs.StatementIDs[0].StatementID)
+		wj.context.Put(model.ScopeGlobal, CtxConsentedAccountID, wj.config.resourceIDs.AccountIDs[0].AccountID)
+		wj.context.Put(model.ScopeGlobal, CtxStatementID, wj.config.resourceIDs.StatementIDs[0].StatementID)
 	}
 
 	requiredTokens := wj.permissions
@@ -539,8 +670,8 @@ func (wj *AppJourney) RunTests() error {
 
 	runDefinition := wj.makeRunDefinition()
 	runner := executors.NewTestCaseRunner(wj.log, runDefinition, wj.daemonController)
-	wj.context.PutString(CtxPhase, "run")
-	err := runner.RunTestCases(&wj.context)
+	wj.context.Put(model.ScopeGlobal, CtxPhase, "run")
+	err := runner.RunTestCases(wj.context)
 	return err
 }
 
@@ -549,6 +680,18 @@ func (wj *AppJourney) Results() executors.DaemonController {
 	return wj.daemonController
 }
 
+// CombinedResults returns the results of the run merged into those of the run it re-ran the failures of, if any
+func (wj *AppJourney) CombinedResults() map[results.ResultKey][]results.TestCase {
+	wj.journeyLock.Lock()
+	previous := wj.previousResults
+	wj.journeyLock.Unlock()
+
+	if previous == nil {
+		return wj.daemonController.AllResultsGrouped()
+	}
+	return results.Merge(previous, wj.daemonController.AllResultsGrouped())
+}
+
 // StopTestRun -
 func (wj *AppJourney) StopTestRun() {
 	wj.daemonController.Stop()
@@ -582,6 +725,7 @@ func (wj *AppJourney) makeGeneratorConfig() generation.GeneratorConfig {
 		AuthorizationEndpoint: wj.config.authorizationEndpoint,
 		RedirectURL:           wj.config.redirectURL,
 		ResourceIDs:           wj.config.resourceIDs,
+		Selection:             wj.selection,
 	}
 }
 
@@ -591,6 +735,12 @@ func (wj *AppJourney) makeRunDefinition() executors.RunDefinition {
 		SpecRun:       wj.specRun,
 		SigningCert:   wj.config.certificateSigning,
 		TransportCert: wj.config.certificateTransport,
+
+		ExchangeCapture: wj.config.exchangeCapture,
+		Cassette:        wj.config.cassette,
+		RetryPolicy:     wj.config.retryPolicy,
+		RateLimiter:     wj.config.rateLimiter,
+		Client:          wj.config.client,
 	}
 }
 
@@ -631,6 +781,12 @@ type JourneyConfig struct {
 	AcrValuesSupported             []string
 	conditionalProperties          []discovery.ConditionalAPIProperties
 	cbpiiDebtorAccount             discovery.CBPIIDebtorAccount
+	performanceBudgets             []results.PerformanceBudget
+	exchangeCapture                results.ExchangeCapture
+	cassette                       *executors.Cassette
+	retryPolicy                    executors.RetryPolicy
+	rateLimiter                    *executors.RateLimiter
+	client                         *resty.Client
 	issuer                         string
 }
 
@@ -641,7 +797,9 @@ func (wj *AppJourney) SetConfig(config JourneyConfig) error {
 
 	wj.config = config
 	wj.config.useDynamicResourceID = wj.dynamicResourceIDs // fed from environment variable 'dynres'=true/false
-	err := PutParametersToJourneyContext(wj.config, wj.context)
+	err := wj.context.Update(model.ScopeGlobal, func(ctx *model.Context) error {
+		return PutParametersToJourneyContext(wj.config, *ctx)
+	})
 	if err != nil {
 		return err
 	}
@@ -656,6 +814,13 @@ func (wj *AppJourney) ConditionalProperties() []discovery.ConditionalAPIProperti
 	return wj.conditionalProperties
 }
 
+// PerformanceBudgets returns the performance budgets of the configuration, evaluated when the report is exported
+func (wj *AppJourney) PerformanceBudgets() []results.PerformanceBudget {
+	wj.journeyLock.Lock()
+	defer wj.journeyLock.Unlock()
+	return wj.config.performanceBudgets
+}
+
 // Events -
 func (wj *AppJourney) Events() events.Events {
 	return wj.events
@@ -669,7 +834,7 @@ func (wj *AppJourney) customTestParametersToJourneyContext() {
 	// assume ordering is prerun i.e. customtest run before other tests
 	for _, customTest := range wj.validDiscoveryModel.DiscoveryModel.CustomTests {
 		for k, v := range customTest.Replacements {
-			wj.context.PutString(k, v)
+			wj.context.Put(model.ScopeGlobal, k, v)
 		}
 	}
 }
@@ -702,7 +867,7 @@ func consentIdsToTestCaseRun(log *logrus.Entry, consentIds []executors.TokenCons
 func (wj *AppJourney) dumpJSON(i interface{}) {
 	var model []byte
 	model, _ = json.MarshalIndent(i, "", "    ")
-	wj.log.Traceln(string(model))
+	wj.log.Traceln(redact.Log(string(model)))
 }
 
 // EnableDynamicResourceIDs is triggered by and environment variable dynids=true
@@ -2,18 +2,22 @@ package server
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery"
 	"github.com/OpenBankingUK/conformance-suite/pkg/discovery/mocks"
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
 	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 	"github.com/OpenBankingUK/conformance-suite/pkg/test"
 
 	gmocks "github.com/OpenBankingUK/conformance-suite/pkg/generation"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -108,6 +112,51 @@ func TestJourneyTestCasesCantGenerateIfDiscoveryNotSet(t *testing.T) {
 	assert.Equal(generation.SpecRun{}, testCases)
 }
 
+func TestJourneyExecutionPlanRequiresTestCases(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	validator := &mocks.Validator{}
+	generator := &gmocks.MockGenerator{}
+	journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+
+	_, err := journey.ExecutionPlan()
+
+	assert.Equal(errTestCasesNotGenerated, err)
+}
+
+func TestJourneyPlanTestCasesDoesNotAcquireConsents(t *testing.T) {
+	validator := &mocks.Validator{}
+	generator := &gmocks.MockGenerator{}
+	journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+	journey.validDiscoveryModel = &discovery.Model{DiscoveryModel: discovery.ModelDiscovery{TokenAcquisition: "psu"}}
+	selection := manifest.SelectIDs([]string{"OB-301-DOP-100100"})
+	specRun := generation.SpecRun{SpecTestCases: []generation.SpecificationTestCases{
+		{TestCases: []model.TestCase{{ID: "OB-301-DOP-100100", Name: "Domestic payment consent"}}},
+	}}
+	permissions := map[string][]manifest.RequiredTokens{"payments": {{Name: "paymentToken0001", IDs: []string{"OB-301-DOP-100100"}}}}
+	generator.On("GenerateManifestTests", mock.Anything, mock.MatchedBy(func(config generation.GeneratorConfig) bool {
+		return reflect.DeepEqual(selection, config.Selection)
+	}), mock.Anything, mock.Anything).Return(specRun, manifest.Scripts{}, permissions)
+
+	plan, err := journey.PlanTestCases(selection)
+
+	require.NoError(t, err)
+	require.Equal(t, generation.NewExecutionPlan(specRun, permissions), plan)
+	require.False(t, journey.testCasesRunGenerated, "the test cases are not generated for a run")
+	require.Equal(t, manifest.Selection{}, journey.selection)
+	_, err = journey.ExecutionPlan()
+	require.Equal(t, errTestCasesNotGenerated, err)
+	generator.AssertExpectations(t)
+}
+
+func TestJourneyPlanTestCasesRequiresDiscoveryModel(t *testing.T) {
+	journey := NewJourney(nullLogger(), &gmocks.MockGenerator{}, &mocks.Validator{}, discovery.NewNullTLSValidator(), false)
+
+	_, err := journey.PlanTestCases(manifest.Selection{})
+
+	require.Equal(t, errDiscoveryModelNotSet, err)
+}
+
 func TestJourneyRunTestCasesCantRunIfNoTestCases(t *testing.T) {
 	assert := test.NewAssert(t)
 
@@ -161,3 +210,50 @@ func TestJourneySetConfig(t *testing.T) {
 	require.NoError(journey.SetConfig(config))
 	require.Equal(config, journey.config)
 }
+
+func TestJourneyRerunFailuresRequiresFailedTestCases(t *testing.T) {
+	validator := &mocks.Validator{}
+	generator := &gmocks.MockGenerator{}
+	journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+
+	_, err := journey.RerunFailures(map[results.ResultKey][]results.TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: {{Id: "OB-301-ACC-100100", Pass: true}},
+	})
+
+	require.Equal(t, errNoFailedTestCases, err)
+}
+
+func TestJourneyRerunFailuresSelectsFailedTestCases(t *testing.T) {
+	validator := &mocks.Validator{}
+	generator := &gmocks.MockGenerator{}
+	journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+
+	_, err := journey.RerunFailures(map[results.ResultKey][]results.TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: {{Id: "OB-301-ACC-100100", Pass: true}, {Id: "OB-301-ACC-120100", Pass: false}},
+	})
+
+	require.Equal(t, errDiscoveryModelNotSet, err)
+	require.Equal(t, manifest.SelectIDs([]string{"OB-301-ACC-120100"}), journey.makeGeneratorConfig().Selection)
+}
+
+func TestJourneyCombinedResultsMergesRerun(t *testing.T) {
+	validator := &mocks.Validator{}
+	generator := &gmocks.MockGenerator{}
+	journey := NewJourney(nullLogger(), generator, validator, discovery.NewNullTLSValidator(), false)
+	key := results.ResultKey{APIName: "Accounts", APIVersion: "v3.1"}
+	previous := map[results.ResultKey][]results.TestCase{
+		key: {{Id: "OB-301-ACC-100100", Pass: true}, {Id: "OB-301-ACC-120100", Pass: false}},
+	}
+	_, err := journey.RerunFailures(previous)
+	require.Error(t, err)
+
+	rerun := results.TestCase{Id: "OB-301-ACC-120100", Pass: true, API: "Accounts", APIVersion: "v3.1"}
+	journey.Results().AddResult(rerun)
+
+	require.Equal(t, map[results.ResultKey][]results.TestCase{
+		key: {{Id: "OB-301-ACC-100100", Pass: true}, rerun},
+	}, journey.CombinedResults())
+
+	journey.SetTestSelection(manifest.Selection{})
+	require.Equal(t, map[results.ResultKey][]results.TestCase{key: {rerun}}, journey.CombinedResults())
+}
@@ -8,6 +8,7 @@ import executors "github.com/OpenBankingUK/conformance-suite/pkg/executors"
 import generation "github.com/OpenBankingUK/conformance-suite/pkg/generation"
 import manifest "github.com/OpenBankingUK/conformance-suite/pkg/manifest"
 import mock "github.com/stretchr/testify/mock"
+import results "github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
 
 // MockJourney is an autogenerated mock type for the Journey type
 type MockJourney struct {
@@ -42,6 +43,22 @@ func (_m *MockJourney) CollectToken(code string, state string, scope string) err
 	return r0
 }
 
+// CombinedResults provides a mock function with given fields:
+func (_m *MockJourney) CombinedResults() map[results.ResultKey][]results.TestCase {
+	ret := _m.Called()
+
+	var r0 map[results.ResultKey][]results.TestCase
+	if rf, ok := ret.Get(0).(func() map[results.ResultKey][]results.TestCase); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[results.ResultKey][]results.TestCase)
+		}
+	}
+
+	return r0
+}
+
 // ConditionalProperties provides a mock function with given fields:
 func (_m *MockJourney) ConditionalProperties() []discovery.ConditionalAPIProperties {
 	ret := _m.Called()
@@ -95,6 +112,27 @@ func (_m *MockJourney) Events() events.Events {
 	return r0
 }
 
+// ExecutionPlan provides a mock function with given fields:
+func (_m *MockJourney) ExecutionPlan() (generation.ExecutionPlan, error) {
+	ret := _m.Called()
+
+	var r0 generation.ExecutionPlan
+	if rf, ok := ret.Get(0).(func() generation.ExecutionPlan); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(generation.ExecutionPlan)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FilteredManifests provides a mock function with given fields:
 func (_m *MockJourney) FilteredManifests() (manifest.Scripts, error) {
 	ret := _m.Called()
@@ -121,6 +159,64 @@ func (_m *MockJourney) NewDaemonController() {
 	_m.Called()
 }
 
+// PerformanceBudgets provides a mock function with given fields:
+func (_m *MockJourney) PerformanceBudgets() []results.PerformanceBudget {
+	ret := _m.Called()
+
+	var r0 []results.PerformanceBudget
+	if rf, ok := ret.Get(0).(func() []results.PerformanceBudget); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]results.PerformanceBudget)
+		}
+	}
+
+	return r0
+}
+
+// PlanTestCases provides a mock function with given fields: selection
+func (_m *MockJourney) PlanTestCases(selection manifest.Selection) (generation.ExecutionPlan, error) {
+	ret := _m.Called(selection)
+
+	var r0 generation.ExecutionPlan
+	if rf, ok := ret.Get(0).(func(manifest.Selection) generation.ExecutionPlan); ok {
+		r0 = rf(selection)
+	} else {
+		r0 = ret.Get(0).(generation.ExecutionPlan)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(manifest.Selection) error); ok {
+		r1 = rf(selection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RerunFailures provides a mock function with given fields: previous
+func (_m *MockJourney) RerunFailures(previous map[results.ResultKey][]results.TestCase) (generation.SpecRun, error) {
+	ret := _m.Called(previous)
+
+	var r0 generation.SpecRun
+	if rf, ok := ret.Get(0).(func(map[results.ResultKey][]results.TestCase) generation.SpecRun); ok {
+		r0 = rf(previous)
+	} else {
+		r0 = ret.Get(0).(generation.SpecRun)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(map[results.ResultKey][]results.TestCase) error); ok {
+		r1 = rf(previous)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Results provides a mock function with given fields:
 func (_m *MockJourney) Results() executors.DaemonController {
 	ret := _m.Called()
@@ -193,6 +289,11 @@ func (_m *MockJourney) SetFilteredManifests(_a0 manifest.Scripts) {
 	_m.Called(_a0)
 }
 
+// SetTestSelection provides a mock function with given fields: selection
+func (_m *MockJourney) SetTestSelection(selection manifest.Selection) {
+	_m.Called(selection)
+}
+
 // StopTestRun provides a mock function with given fields:
 func (_m *MockJourney) StopTestRun() {
 	_m.Called()
@@ -3,9 +3,7 @@ package server
 import (
 	"github.com/OpenBankingUK/conformance-suite/pkg/authentication"
 	"github.com/OpenBankingUK/conformance-suite/pkg/model"
-	"github.com/OpenBankingUK/conformance-suite/pkg/version"
 	"github.com/sirupsen/logrus"
-	"gopkg.in/resty.v1"
 )
 
 // Context Variables
@@ -113,13 +111,6 @@ func PutParametersToJourneyContext(config JourneyConfig, context model.Context)
 		context.Delete(CtxStatementID)
 	}
 
-	_, ou, cn, err := config.certificateTransport.DN()
-	if err == nil && cn != "" && ou != "" {
-		resty.SetHeader("User-Agent", "OpenBankingFCS/"+version.NewGitHub("").GetHumanVersion()+"/"+ou+"/"+cn)
-	} else {
-		resty.SetHeader("User-Agent", "OpenBankingFCS/"+version.NewGitHub("").GetHumanVersion())
-	}
-
 	logrus.Tracef("TokenEndpoint auth method %s", config.tokenEndpointAuthMethod)
 	return nil
 }
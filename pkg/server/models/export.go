@@ -90,4 +90,6 @@ type ExportResults struct {
 	ResponseFields   string                                    `json:"-"`
 	TLSVersionResult map[string]*discovery.TLSValidationResult `json:"-"`
 	JWSStatus        string                                    `json:"jws_status"`
+	// Performance budgets configured, evaluated against the results
+	PerformanceBudgets []results.PerformanceBudget `json:"performance_budgets,omitempty"`
 }
@@ -16,3 +16,8 @@ func (r ImportRequest) Validate() error {
 		validation.Field(&r.Report, validation.Required),
 	)
 }
+
+// RerunFailuresRequest - Request to `/api/test-cases/rerun-failures` POST.
+type RerunFailuresRequest struct {
+	Report string `json:"report,omitempty"` // The exported report ZIP archive to re-run the failures of, the current run when empty.
+}
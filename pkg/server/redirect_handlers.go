@@ -185,7 +185,6 @@ func (h redirectHandlers) postQueryOKHandler(c echo.Context) error {
 func (h redirectHandlers) handleCodeExchange(code string, state string, scope string) error {
 	h.logger.WithFields(logrus.Fields{
 		"function": "handleCodeExchange",
-		"code":     code,
 		"state":    state,
 		"scope":    scope,
 	}).Info("h.journey.CollectToken ...")
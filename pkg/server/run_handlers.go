@@ -46,6 +46,25 @@ func (h runHandlers) runStartPostHandler(c echo.Context) error {
 	return c.NoContent(http.StatusCreated)
 }
 
+// runExchangesHandler - GET /api/run/exchanges
+// returns the requests and responses recorded by the test cases run as an HTTP Archive, those of the test case
+// `id` only when given. Exchanges are recorded when `exchange_capture` is enabled in the configuration.
+func (h runHandlers) runExchangesHandler(c echo.Context) error {
+	grouped := h.journey.CombinedResults()
+	id := c.QueryParam("id")
+	if id == "" {
+		return c.JSON(http.StatusOK, results.ExchangeArchive(grouped))
+	}
+	for _, testCases := range grouped {
+		for _, testCase := range testCases {
+			if testCase.Id == id {
+				return c.JSON(http.StatusOK, results.NewHAR(testCase.Exchanges))
+			}
+		}
+	}
+	return c.JSON(http.StatusNotFound, NewErrorResponse(fmt.Errorf("no result for test case %s", id)))
+}
+
 // listenResultWebSocket - /api/run/ws
 // creates a socket connection to listen for test run results.
 //
@@ -83,7 +102,7 @@ func (h runHandlers) listenResultWebSocket(c echo.Context) error {
 				break
 			}
 		case isCompleted, ok := <-daemon.IsCompleted():
-			if err := h.processTestCasesCompleted(ws, logger, isCompleted, ok); err != nil {
+			if err := h.processTestCasesCompleted(ws, logger, isCompleted, daemon.CountByStatus(), ok); err != nil {
 				break
 			}
 		case testCaseResult, ok := <-daemon.Results():
@@ -144,18 +163,19 @@ func (h runHandlers) stopRunHandler(c echo.Context) error {
 	return nil
 }
 
-func (h runHandlers) processTestCasesCompleted(ws *websocket.Conn, logger *logrus.Entry, isCompleted bool, ok bool) error {
+func (h runHandlers) processTestCasesCompleted(ws *websocket.Conn, logger *logrus.Entry, isCompleted bool, totals map[results.Status]int, ok bool) error {
 	if !ok {
 		err := errors.New("error reading from daemon.IsCompleted channel")
 		logger.Error(err)
 		return err
 	}
 
-	wsEvent := newTestCasesCompletedWebSocketEvent(isCompleted)
+	wsEvent := newTestCasesCompletedWebSocketEvent(isCompleted, totals)
 
 	logger.WithFields(logrus.Fields{
 		"wsEvent.Type": wsEvent.Type,
 		"isCompleted":  isCompleted,
+		"totals":       totals,
 	}).Info("sending event")
 	if err := ws.WriteJSON(wsEvent); err != nil {
 		logger.WithError(err).Error("[processTestCasesCompleted] writing json to websocket")
@@ -245,20 +265,22 @@ type TestCaseResultWebSocketEvent struct {
 func newTestCaseResultWebSocketEvent(testCaseResult results.TestCase) TestCaseResultWebSocketEvent {
 	return TestCaseResultWebSocketEvent{
 		Type: "ResultType_TestCaseResult",
-		Test: testCaseResult,
+		Test: testCaseResult.Redacted(),
 	}
 }
 
 // TestCasesCompletedWebSocketEvent -
 type TestCasesCompletedWebSocketEvent struct {
-	Type  string `json:"type"`
-	Value bool   `json:"value"`
+	Type   string                 `json:"type"`
+	Value  bool                   `json:"value"`
+	Totals map[results.Status]int `json:"totals,omitempty"` // Number of results of each status
 }
 
-func newTestCasesCompletedWebSocketEvent(isCompleted bool) TestCasesCompletedWebSocketEvent {
+func newTestCasesCompletedWebSocketEvent(isCompleted bool, totals map[results.Status]int) TestCasesCompletedWebSocketEvent {
 	return TestCasesCompletedWebSocketEvent{
-		Type:  "ResultType_TestCasesCompleted",
-		Value: isCompleted,
+		Type:   "ResultType_TestCasesCompleted",
+		Value:  isCompleted,
+		Totals: totals,
 	}
 }
 
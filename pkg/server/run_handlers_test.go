@@ -45,6 +45,7 @@ func TestServerRunHandlersnewTestCaseResultWebSocketEvent(t *testing.T) {
 	testCaseResult := results.TestCase{
 		Id:         "#t1025",
 		Pass:       true,
+		Status:     results.StatusPass,
 		Detail:     "Example Test Case",
 		RefURI:     "https://openbanking.org.uk/ref/uri",
 		Endpoint:   "/foobar",
@@ -62,6 +63,7 @@ func TestServerRunHandlersnewTestCaseResultWebSocketEvent(t *testing.T) {
     "test": {
         "id": "#t1025",
         "pass": true,
+        "status": "pass",
         "metrics": {
             "response_time": 0,
             "response_size": 0
@@ -78,10 +80,55 @@ func TestServerRunHandlersnewTestCaseResultWebSocketEvent(t *testing.T) {
 	require.JSONEq(expected, actual)
 }
 
+func TestServerRunHandlersnewTestCaseResultWebSocketEventSkipped(t *testing.T) {
+	require := test.NewRequire(t)
+
+	testCaseResult := results.NewTestCaseSkipped("OB-301-DOP-100600", "OB-301-DOP-100100", "/domestic-payments", "Payments", "v3.1", "Domestic Payment succeeds", "https://openbanking.org.uk/ref/uri")
+	wsEvent := newTestCaseResultWebSocketEvent(testCaseResult)
+
+	wsEventJSON, err := json.MarshalIndent(wsEvent, prefix, indent)
+	require.NoError(err)
+
+	expected := `
+{
+	"type": "ResultType_TestCaseResult",
+	"test": {
+		"id": "OB-301-DOP-100600",
+		"pass": false,
+		"status": "skipped",
+		"severity": "medium",
+		"blockedBy": "OB-301-DOP-100100",
+		"metrics": {
+			"response_time": 0,
+			"response_size": 0
+		},
+		"detail": "Domestic Payment succeeds",
+		"refURI": "https://openbanking.org.uk/ref/uri",
+		"endpoint": "/domestic-payments",
+		"httpStatusCode": ""
+	}
+}
+	`
+	require.JSONEq(expected, string(wsEventJSON))
+}
+
+func TestServerRunHandlersnewTestCaseResultWebSocketEventRedacted(t *testing.T) {
+	require := test.NewRequire(t)
+
+	testCaseResult := results.TestCase{
+		Id:     "#t1026",
+		Status: results.StatusFail,
+		Fail:   []string{`token endpoint replied {"access_token":"2f6c5b1e"}`},
+	}
+	wsEvent := newTestCaseResultWebSocketEvent(testCaseResult)
+
+	require.Equal([]string{`token endpoint replied {"access_token":"[redacted]"}`}, wsEvent.Test.Fail)
+}
+
 func TestServerRunHandlersnewTestCasesCompletedWebSocketEvent(t *testing.T) {
 	require := test.NewRequire(t)
 
-	wsEvent := newTestCasesCompletedWebSocketEvent(true)
+	wsEvent := newTestCasesCompletedWebSocketEvent(true, map[results.Status]int{results.StatusPass: 2, results.StatusError: 1})
 
 	wsEventJSON, err := json.MarshalIndent(wsEvent, prefix, indent)
 	require.NoError(err)
@@ -90,10 +137,48 @@ func TestServerRunHandlersnewTestCasesCompletedWebSocketEvent(t *testing.T) {
 	expected := `
 {
     "type": "ResultType_TestCasesCompleted",
-    "value": true
+    "value": true,
+    "totals": {
+        "pass": 2,
+        "error": 1
+    }
 }
 	`
 	actual := string(wsEventJSON)
 
 	require.JSONEq(expected, actual)
 }
+
+func TestServerRunExchanges(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	journey.On("CombinedResults").Return(map[results.ResultKey][]results.TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: {
+			{Id: "OB-301-ACC-120100", Exchanges: []results.HAREntry{{TestCaseID: "OB-301-ACC-120100", Request: results.HARRequest{Method: http.MethodGet}}}},
+			{Id: "OB-301-ACC-120200", Exchanges: []results.HAREntry{{TestCaseID: "OB-301-ACC-120200"}}},
+		},
+	})
+	server := NewServer(journey, nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, body, _ := request(http.MethodGet, "/api/run/exchanges", nil, server)
+	require.Equal(http.StatusOK, code)
+	archive := results.HAR{}
+	require.NoError(json.Unmarshal(body.Bytes(), &archive))
+	require.Equal(results.HARVersion, archive.Log.Version)
+	require.Len(archive.Log.Entries, 2)
+
+	code, body, _ = request(http.MethodGet, "/api/run/exchanges?id=OB-301-ACC-120100", nil, server)
+	require.Equal(http.StatusOK, code)
+	archive = results.HAR{}
+	require.NoError(json.Unmarshal(body.Bytes(), &archive))
+	require.Len(archive.Log.Entries, 1)
+	require.Equal(http.MethodGet, archive.Log.Entries[0].Request.Method)
+
+	code, body, _ = request(http.MethodGet, "/api/run/exchanges?id=OB-301-ACC-999999", nil, server)
+	require.Equal(http.StatusNotFound, code)
+	require.JSONEq(`{"error": "no result for test case OB-301-ACC-999999"}`, body.String())
+}
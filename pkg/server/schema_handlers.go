@@ -0,0 +1,32 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
+)
+
+// schemaHandlers publishes the JSON Schemas of manifests, assertions and discovery models so that
+// editors can validate and autocomplete the files, e.g. with `"$schema": "https://0.0.0.0:8443/api/schemas/v1/manifest.json"`
+type schemaHandlers struct{}
+
+func newSchemaHandlers() schemaHandlers {
+	return schemaHandlers{}
+}
+
+// schemaHandler returns the schema called `name` of the version requested
+func (h schemaHandlers) schemaHandler(c echo.Context) error {
+	if version := c.Param("version"); version != jsonschema.Version {
+		return c.JSON(http.StatusNotFound, NewErrorResponse(fmt.Errorf("unknown json schema version %q", version)))
+	}
+	name := strings.TrimSuffix(c.Param("name"), ".json")
+	schema, err := jsonschema.Schema(name)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, NewErrorResponse(err))
+	}
+	return c.Blob(http.StatusOK, "application/schema+json", schema)
+}
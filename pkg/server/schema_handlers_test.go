@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/jsonschema"
+	versionmock "github.com/OpenBankingUK/conformance-suite/pkg/version/mocks"
+)
+
+func TestSchemaHandler(t *testing.T) {
+	server := NewServer(testJourney(), nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(t, server.Shutdown(context.TODO()))
+	}()
+
+	for _, name := range jsonschema.Names {
+		code, body, headers := request(http.MethodGet, "/api/schemas/v1/"+name+".json", nil, server)
+
+		expected, err := jsonschema.Schema(name)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "application/schema+json", headers.Get("Content-Type"))
+		assert.Equal(t, string(expected), body.String())
+	}
+}
+
+func TestSchemaHandlerUnknownSchema(t *testing.T) {
+	server := NewServer(testJourney(), nullLogger(), &versionmock.Version{})
+	defer func() {
+		require.NoError(t, server.Shutdown(context.TODO()))
+	}()
+
+	code, body, _ := request(http.MethodGet, "/api/schemas/v1/unknown.json", nil, server)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.JSONEq(t, `{"error": "unknown json schema \"unknown\""}`, body.String())
+
+	code, body, _ = request(http.MethodGet, "/api/schemas/v0/manifest.json", nil, server)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.JSONEq(t, `{"error": "unknown json schema version \"v0\""}`, body.String())
+}
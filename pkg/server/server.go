@@ -105,11 +105,16 @@ func registerRoutes(journey Journey, server *Server, logger *logrus.Entry, versi
 	// endpoints for test cases
 	testCaseHandlers := newTestCaseHandlers(journey, NewWebSocketUpgrader(), logger)
 	api.GET("/test-cases", testCaseHandlers.testCasesHandler)
+	api.POST("/test-cases", testCaseHandlers.testCasesSelectionHandler)
+	api.POST("/test-cases/rerun-failures", testCaseHandlers.testCasesRerunFailuresHandler)
+	api.GET("/test-cases/plan", testCaseHandlers.testCasesPlanHandler)
+	api.POST("/test-cases/plan", testCaseHandlers.testCasesSelectionPlanHandler)
 
 	// endpoints for test runner
 	runHandlers := newRunHandlers(journey, NewWebSocketUpgrader(), logger)
 	api.POST("/run", runHandlers.runStartPostHandler)
 	api.GET("/run/ws", runHandlers.listenResultWebSocket)
+	api.GET("/run/exchanges", runHandlers.runExchangesHandler)
 	api.DELETE("/run", runHandlers.stopRunHandler)
 
 	// endpoints for validating and storing the token retrieved in `/conformancesuite/callback`
@@ -122,6 +127,10 @@ func registerRoutes(journey Journey, server *Server, logger *logrus.Entry, versi
 	exportHandlers := newExportHandlers(journey, logger)
 	api.POST("/export", exportHandlers.postExport)
 
+	// JSON Schemas of the manifest, assertions and discovery files
+	schemaHandlers := newSchemaHandlers()
+	api.GET("/schemas/:version/:name", schemaHandlers.schemaHandler)
+
 	// endpoints for utility function such as version/update checking.
 	utilityEndpoints := newUtilityEndpoints(version)
 	api.GET("/version", utilityEndpoints.versionCheck)
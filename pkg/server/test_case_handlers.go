@@ -1,11 +1,20 @@
 package server
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
+	"github.com/OpenBankingUK/conformance-suite/pkg/server/models"
 )
 
 type testCaseHandlers struct {
@@ -23,10 +32,106 @@ func newTestCaseHandlers(journey Journey, upgrader *websocket.Upgrader, logger *
 }
 
 func (d testCaseHandlers) testCasesHandler(c echo.Context) error {
+	return d.generateTestCases(c, manifest.Selection{})
+}
+
+// testCasesSelectionHandler generates the test cases included, and not excluded, by the selection in the request body
+func (d testCaseHandlers) testCasesSelectionHandler(c echo.Context) error {
+	selection := manifest.Selection{}
+	if err := c.Bind(&selection); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(errors.Wrap(err, "error with Bind")))
+	}
+	if err := selection.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	return d.generateTestCases(c, selection)
+}
+
+func (d testCaseHandlers) generateTestCases(c echo.Context, selection manifest.Selection) error {
 	d.journey.NewDaemonController() // fix for not sending events to correct websocket after a websocket reconnect
+	d.journey.SetTestSelection(selection)
 	testCases, err := d.journey.TestCases()
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
 	}
 	return c.JSON(http.StatusOK, testCases)
 }
+
+// testCasesRerunFailuresHandler generates the test cases which failed in the current run, or in the
+// report in the request body, so they can be run again and their results merged into a combined report
+func (d testCaseHandlers) testCasesRerunFailuresHandler(c echo.Context) error {
+	request := models.RerunFailuresRequest{}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(errors.Wrap(err, "error with Bind")))
+	}
+
+	var previous map[results.ResultKey][]results.TestCase
+	if request.Report == "" {
+		previous = d.journey.CombinedResults()
+	} else {
+		archive, err := decodeReportArchive(request.Report)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+		}
+		imported, err := report.NewZipImporter(bytes.NewReader(archive)).Import()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+		}
+		previous = imported.ResultsGrouped()
+	}
+
+	d.journey.NewDaemonController() // fix for not sending events to correct websocket after a websocket reconnect
+	testCases, err := d.journey.RerunFailures(previous)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	return c.JSON(http.StatusOK, testCases)
+}
+
+// testCasesPlanHandler returns the execution plan of the test cases generated, as JSON or, with `format=dot`,
+// in the Graphviz DOT language. With `failed=<test case id>`, the test cases skipped when it fails are marked.
+func (d testCaseHandlers) testCasesPlanHandler(c echo.Context) error {
+	plan, err := d.journey.ExecutionPlan()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	return writeExecutionPlan(c, plan)
+}
+
+// testCasesSelectionPlanHandler returns the execution plan of the test cases the selection in the request body
+// would generate, without generating them for the run nor acquiring consents. It takes the same query
+// parameters as testCasesPlanHandler.
+func (d testCaseHandlers) testCasesSelectionPlanHandler(c echo.Context) error {
+	selection := manifest.Selection{}
+	if err := c.Bind(&selection); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(errors.Wrap(err, "error with Bind")))
+	}
+	if err := selection.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	plan, err := d.journey.PlanTestCases(selection)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+	}
+	return writeExecutionPlan(c, plan)
+}
+
+// writeExecutionPlan writes the plan in the format of the `format` query parameter, the test cases skipped when
+// the test case of the `failed` query parameter fails marked
+func writeExecutionPlan(c echo.Context, plan generation.ExecutionPlan) error {
+	var err error
+	if failed := c.QueryParam("failed"); failed != "" {
+		if plan, err = plan.WithFailure(failed); err != nil {
+			return c.JSON(http.StatusBadRequest, NewErrorResponse(err))
+		}
+	}
+
+	switch format := c.QueryParam("format"); format {
+	case "", "json":
+		return c.JSON(http.StatusOK, plan)
+	case "dot":
+		return c.Blob(http.StatusOK, "text/vnd.graphviz", []byte(plan.DOT()))
+	default:
+		return c.JSON(http.StatusBadRequest, NewErrorResponse(fmt.Errorf("unknown execution plan format %q, expected json or dot", format)))
+	}
+}
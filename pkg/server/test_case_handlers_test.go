@@ -0,0 +1,312 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/OpenBankingUK/conformance-suite/pkg/executors/results"
+	"github.com/OpenBankingUK/conformance-suite/pkg/generation"
+	"github.com/OpenBankingUK/conformance-suite/pkg/manifest"
+	"github.com/OpenBankingUK/conformance-suite/pkg/report"
+	"github.com/OpenBankingUK/conformance-suite/pkg/test"
+	"github.com/OpenBankingUK/conformance-suite/pkg/version/mocks"
+)
+
+func TestTestCasesHandlerSelectsEveryTest(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	journey.On("NewDaemonController").Return()
+	journey.On("SetTestSelection", manifest.Selection{}).Return()
+	journey.On("TestCases").Return(generation.SpecRun{}, nil)
+
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, _, _ := request(http.MethodGet, "/api/test-cases", nil, server)
+
+	require.Equal(http.StatusOK, code)
+	journey.AssertExpectations(t)
+}
+
+func TestTestCasesSelectionHandler(t *testing.T) {
+	require := test.NewRequire(t)
+
+	expected := manifest.Selection{
+		Include: manifest.SelectionRules{IDs: []string{"OB-301-ACC-*"}, Tags: []string{"smoke"}},
+		Exclude: manifest.SelectionRules{Regexes: []string{"Transactions"}},
+	}
+	journey := &MockJourney{}
+	journey.On("NewDaemonController").Return()
+	journey.On("SetTestSelection", expected).Return()
+	journey.On("TestCases").Return(generation.SpecRun{}, nil)
+
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	body := `{"include": {"ids": ["OB-301-ACC-*"], "tags": ["smoke"]}, "exclude": {"regexes": ["Transactions"]}}`
+	code, _, _ := request(http.MethodPost, "/api/test-cases", strings.NewReader(body), server)
+
+	require.Equal(http.StatusOK, code)
+	journey.AssertExpectations(t)
+}
+
+func TestTestCasesSelectionHandlerRejectsInvalidSelection(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	body := `{"include": {"uriImplementations": ["sometimes"]}}`
+	code, resp, _ := request(http.MethodPost, "/api/test-cases", strings.NewReader(body), server)
+
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error":"selection include: invalid uriImplementation \"sometimes\", expected one of mandatory, optional, conditional"}`, resp.String())
+	journey.AssertNotCalled(t, "TestCases")
+}
+
+func TestTestCasesSelectionHandlerRejectsInvalidBody(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, _, _ := request(http.MethodPost, "/api/test-cases", strings.NewReader(`{"include": []}`), server)
+
+	require.Equal(http.StatusBadRequest, code)
+	journey.AssertNotCalled(t, "TestCases")
+}
+
+func TestTestCasesRerunFailuresHandlerUsesCurrentRun(t *testing.T) {
+	require := test.NewRequire(t)
+
+	previous := map[results.ResultKey][]results.TestCase{
+		{APIName: "Accounts", APIVersion: "v3.1"}: {{Id: "OB-301-ACC-120100", Pass: false}},
+	}
+	journey := &MockJourney{}
+	journey.On("CombinedResults").Return(previous)
+	journey.On("NewDaemonController").Return()
+	journey.On("RerunFailures", previous).Return(generation.SpecRun{}, nil)
+
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, _, _ := request(http.MethodPost, "/api/test-cases/rerun-failures", strings.NewReader(`{}`), server)
+
+	require.Equal(http.StatusOK, code)
+	journey.AssertExpectations(t)
+}
+
+func TestTestCasesRerunFailuresHandlerUsesImportedReport(t *testing.T) {
+	require := test.NewRequire(t)
+
+	imported := report.Report{Status: report.StatusComplete, CertifiedBy: report.CertifiedBy{Environment: report.CertifiedByEnvironmentTesting}, APISpecification: []report.APISpecification{
+		{Name: "Accounts", Version: "v3.1", Results: []results.TestCase{{Id: "OB-301-ACC-120100", Pass: false}}},
+	}}
+	reportJSON, err := json.Marshal(imported)
+	require.NoError(err)
+	archive := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(archive)
+	file, err := zipWriter.Create("report.json")
+	require.NoError(err)
+	_, err = file.Write(reportJSON)
+	require.NoError(err)
+	require.NoError(zipWriter.Close())
+
+	journey := &MockJourney{}
+	journey.On("NewDaemonController").Return()
+	journey.On("RerunFailures", imported.ResultsGrouped()).Return(generation.SpecRun{}, nil)
+
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	body, err := json.Marshal(map[string]string{"report": "data:application/zip;base64," + base64.StdEncoding.EncodeToString(archive.Bytes())})
+	require.NoError(err)
+	code, _, _ := request(http.MethodPost, "/api/test-cases/rerun-failures", bytes.NewReader(body), server)
+
+	require.Equal(http.StatusOK, code)
+	journey.AssertExpectations(t)
+	journey.AssertNotCalled(t, "CombinedResults")
+}
+
+func TestTestCasesRerunFailuresHandlerRejectsInvalidReport(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, resp, _ := request(http.MethodPost, "/api/test-cases/rerun-failures", strings.NewReader(`{"report": "not a data url"}`), server)
+
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error":"invalid report format"}`, resp.String())
+	journey.AssertNotCalled(t, "RerunFailures", mock.Anything)
+}
+
+func TestTestCasesRerunFailuresHandlerReportsNoFailures(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	journey.On("CombinedResults").Return(map[results.ResultKey][]results.TestCase{})
+	journey.On("NewDaemonController").Return()
+	journey.On("RerunFailures", mock.Anything).Return(generation.SpecRun{}, errNoFailedTestCases)
+
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, resp, _ := request(http.MethodPost, "/api/test-cases/rerun-failures", strings.NewReader(`{}`), server)
+
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error":"error no failed test cases to re-run"}`, resp.String())
+}
+
+func testExecutionPlan() generation.ExecutionPlan {
+	return generation.ExecutionPlan{
+		Nodes: []generation.PlanNode{
+			{ID: "OB-301-DOP-100100", Produces: []string{"OB-301-DOP-100100-ConsentId"}},
+			{ID: "OB-301-DOP-100600", Consumes: []string{"OB-301-DOP-100100-ConsentId"}},
+		},
+		Edges:  []generation.PlanEdge{{From: "OB-301-DOP-100100", To: "OB-301-DOP-100600", Context: "OB-301-DOP-100100-ConsentId"}},
+		Tokens: []generation.PlanToken{},
+	}
+}
+
+func TestTestCasesPlanHandler(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	journey.On("ExecutionPlan").Return(testExecutionPlan(), nil)
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, resp, _ := request(http.MethodGet, "/api/test-cases/plan?failed=OB-301-DOP-100100", nil, server)
+
+	require.Equal(http.StatusOK, code)
+	plan := generation.ExecutionPlan{}
+	require.NoError(json.Unmarshal(resp.Bytes(), &plan))
+	require.Equal("OB-301-DOP-100100", plan.Failed)
+	require.Equal([]string{"OB-301-DOP-100600"}, plan.Skipped)
+	require.Len(plan.Edges, 1)
+}
+
+func TestTestCasesPlanHandlerDOT(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	journey.On("ExecutionPlan").Return(testExecutionPlan(), nil)
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, resp, headers := request(http.MethodGet, "/api/test-cases/plan?format=dot", nil, server)
+
+	require.Equal(http.StatusOK, code)
+	require.Equal("text/vnd.graphviz", headers.Get("Content-Type"))
+	require.Equal(testExecutionPlan().DOT(), resp.String())
+}
+
+func TestTestCasesPlanHandlerRejectsInvalidRequests(t *testing.T) {
+	for query, expected := range map[string]string{
+		"?format=svg":           `{"error":"unknown execution plan format \"svg\", expected json or dot"}`,
+		"?failed=OB-301-XXX-01": `{"error":"test case OB-301-XXX-01 is not in the execution plan"}`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			require := test.NewRequire(t)
+
+			journey := &MockJourney{}
+			journey.On("ExecutionPlan").Return(testExecutionPlan(), nil)
+			server := NewServer(journey, nullLogger(), &mocks.Version{})
+			defer func() {
+				require.NoError(server.Shutdown(context.TODO()))
+			}()
+
+			code, resp, _ := request(http.MethodGet, "/api/test-cases/plan"+query, nil, server)
+
+			require.Equal(http.StatusBadRequest, code)
+			require.JSONEq(expected, resp.String())
+		})
+	}
+}
+
+func TestTestCasesPlanHandlerRequiresTestCases(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	journey.On("ExecutionPlan").Return(generation.ExecutionPlan{}, errTestCasesNotGenerated)
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, resp, _ := request(http.MethodGet, "/api/test-cases/plan", nil, server)
+
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error":"error test cases not generated"}`, resp.String())
+}
+
+func TestTestCasesSelectionPlanHandler(t *testing.T) {
+	require := test.NewRequire(t)
+
+	selection := manifest.Selection{Include: manifest.SelectionRules{APINames: []string{"Payment Initiation"}}}
+	journey := &MockJourney{}
+	journey.On("PlanTestCases", selection).Return(testExecutionPlan(), nil)
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	body := `{"include": {"apiNames": ["Payment Initiation"]}}`
+	code, resp, _ := request(http.MethodPost, "/api/test-cases/plan?failed=OB-301-DOP-100100", strings.NewReader(body), server)
+
+	require.Equal(http.StatusOK, code)
+	plan := generation.ExecutionPlan{}
+	require.NoError(json.Unmarshal(resp.Bytes(), &plan))
+	require.Equal([]string{"OB-301-DOP-100600"}, plan.Skipped)
+	journey.AssertExpectations(t)
+	journey.AssertNotCalled(t, "TestCases")
+}
+
+func TestTestCasesSelectionPlanHandlerRejectsInvalidSelection(t *testing.T) {
+	require := test.NewRequire(t)
+
+	journey := &MockJourney{}
+	server := NewServer(journey, nullLogger(), &mocks.Version{})
+	defer func() {
+		require.NoError(server.Shutdown(context.TODO()))
+	}()
+
+	code, resp, _ := request(http.MethodPost, "/api/test-cases/plan", strings.NewReader(`{"include": {"regexes": ["("]}}`), server)
+
+	require.Equal(http.StatusBadRequest, code)
+	require.JSONEq(`{"error":"selection include: invalid regex \"(\": error parsing regexp: missing closing ): `+"`(`"+`"}`, resp.String())
+	journey.AssertNotCalled(t, "PlanTestCases", mock.Anything)
+}
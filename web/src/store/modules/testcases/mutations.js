@@ -2,6 +2,16 @@ import * as _ from 'lodash';
 import * as moment from 'moment';
 import * as types from './mutation-types';
 
+// Label shown for each status of a test case result.
+const STATUS_LABELS = {
+  pass: 'PASSED',
+  fail: 'FAILED',
+  skipped: 'SKIPPED',
+  error: 'ERROR',
+  warning: 'WARNING',
+  'not-applicable': 'N/A',
+};
+
 export default {
   [types.SET_TEST_CASES](state, testCases) {
     state.testCases = testCases;
@@ -34,15 +44,18 @@ export default {
     }
 
     const {
-      id, pass, metrics, fail, detail, refURI,
+      id, pass, status, severity, metrics, fail, warnings, detail, refURI, blockedBy,
     } = update.test;
 
     testCase.id = id;
-    testCase.meta.status = pass ? 'PASSED' : 'FAILED';
+    testCase.meta.status = STATUS_LABELS[status] || (pass ? 'PASSED' : 'FAILED');
+    testCase.severity = severity;
+    testCase.blockedBy = blockedBy;
     const responseSeconds = moment.duration(metrics ? metrics.response_time : 0).asMilliseconds().toFixed(3);
     testCase.meta.metrics.responseTime = `${responseSeconds.toLocaleString()}ms`;
     testCase.meta.metrics.responseSize = `${metrics ? metrics.response_size.toLocaleString() : 0}`;
     testCase.error = fail;
+    testCase.warnings = warnings;
     testCase.detail = detail;
     testCase.refURI = refURI;
 
@@ -52,6 +65,10 @@ export default {
       _.merge(testCase, {
         _rowVariant: 'danger',
       });
+    } else if (warnings) {
+      _.merge(testCase, {
+        _rowVariant: 'warning',
+      });
     }
   },
   [types.SET_TEST_CASES_STATUS](state, status) {